@@ -139,3 +139,11 @@ func (fs *FileStorage) Write(path string, content []byte) error {
 	_, err = f.Write(content)
 	return err
 }
+
+func (fs *FileStorage) Move(source string, dest string) error {
+	err := os.MkdirAll(filepath.Dir(dest), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	return os.Rename(source, dest)
+}
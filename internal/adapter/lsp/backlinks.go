@@ -0,0 +1,154 @@
+package lsp
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mickael-menu/zk/internal/core"
+	strutil "github.com/mickael-menu/zk/internal/util/strings"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// findBacklinks returns the location of the links pointing to the note at
+// the given path, relative to the notebook root.
+func (s *Server) findBacklinks(notebook *core.Notebook, path string) ([]protocol.Location, error) {
+	locations := []protocol.Location{}
+
+	target, err := notebook.FindMinimalNotes(core.NoteFindOpts{
+		IncludePaths: []string{path},
+		Limit:        1,
+	})
+	if err != nil || len(target) == 0 {
+		return locations, err
+	}
+
+	notes, err := notebook.FindNotes(core.NoteFindOpts{
+		LinkTo: &core.LinkFilter{Paths: []string{path}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []core.NoteID{target[0].ID}
+	for _, note := range notes {
+		ids = append(ids, note.ID)
+	}
+	links, err := notebook.FindLinksBetweenNotes(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, note := range notes {
+		for _, link := range links {
+			if link.SourceID != note.ID || link.TargetID != target[0].ID {
+				continue
+			}
+			locations = append(locations, protocol.Location{
+				URI:   pathToURI(filepath.Join(notebook.Path, note.Path)),
+				Range: backlinkRange(note.RawContent, link),
+			})
+		}
+	}
+
+	return locations, nil
+}
+
+// backlinkRange returns the range of the href of the given link in the
+// content of its source note, or of its snippet if the href can't be found.
+func backlinkRange(content string, link core.ResolvedLink) protocol.Range {
+	start := link.SnippetStart
+	end := link.SnippetEnd
+	if start < 0 || end > len(content) || start > end {
+		return protocol.Range{}
+	}
+	if i := strings.Index(content[start:end], link.Href); link.Href != "" && i >= 0 {
+		start += i
+		end = start + len(link.Href)
+	}
+	return protocol.Range{
+		Start: positionAt(content, start),
+		End:   positionAt(content, end),
+	}
+}
+
+// codeLenses returns the code lenses displayed in the given document, such
+// as the number of backlinks at the top of the note.
+func (s *Server) codeLenses(doc *document) ([]protocol.CodeLens, error) {
+	notebook, err := s.notebookOf(doc)
+	if err != nil {
+		return nil, err
+	}
+	if !notebook.Config.LSP.CodeLens.Backlinks {
+		return nil, nil
+	}
+
+	path, err := notebook.RelPath(doc.Path)
+	if err != nil {
+		return nil, err
+	}
+	// Only the number of linking notes is needed, so their content is not
+	// loaded to locate the links.
+	backlinks, err := notebook.FindMinimalNotes(core.NoteFindOpts{
+		LinkTo: &core.LinkFilter{Paths: []string{path}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The references of the note are requested by the client at the lens
+	// position, which is not on a link.
+	count := len(backlinks)
+	return []protocol.CodeLens{{
+		Range: protocol.Range{},
+		Command: &protocol.Command{
+			Title:     fmt.Sprintf("%d %s", count, strutil.Pluralize("backlink", count)),
+			Command:   cmdShowReferences,
+			Arguments: []interface{}{doc.URI, protocol.Position{}},
+		},
+	}}, nil
+}
+
+// inlayHints returns the titles of the notes targeted by the links without
+// title found in the given range of the document, e.g. [[a3f9]].
+func (s *Server) inlayHints(doc *document, rng protocol.Range) ([]inlayHint, error) {
+	notebook, err := s.notebookOf(doc)
+	if err != nil {
+		return nil, err
+	}
+	if !notebook.Config.LSP.InlayHints.LinkTitle {
+		return nil, nil
+	}
+
+	links, err := doc.DocumentLinks()
+	if err != nil {
+		return nil, err
+	}
+
+	hints := []inlayHint{}
+	for _, link := range links {
+		if link.HasTitle || strutil.IsURL(link.Href) {
+			continue
+		}
+		if link.Range.End.Line < rng.Start.Line || link.Range.Start.Line > rng.End.Line {
+			continue
+		}
+
+		target, err := s.noteForHref(link.Href, doc, notebook)
+		if err != nil {
+			s.logger.Err(err)
+			continue
+		}
+		if target == nil || target.Title == "" || target.Title == link.Href {
+			continue
+		}
+
+		hints = append(hints, inlayHint{
+			Position:    link.Range.End,
+			Label:       target.Title,
+			PaddingLeft: true,
+		})
+	}
+
+	return hints, nil
+}
@@ -0,0 +1,40 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/mickael-menu/zk/internal/core"
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestBacklinkRange(t *testing.T) {
+	test := func(content string, href string, snippetStart, snippetEnd int, expected protocol.Range) {
+		link := core.ResolvedLink{Link: core.Link{
+			Href:         href,
+			SnippetStart: snippetStart,
+			SnippetEnd:   snippetEnd,
+		}}
+		assert.Equal(t, backlinkRange(content, link), expected)
+	}
+
+	rng := func(startLine, startChar, endLine, endChar uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: startLine, Character: startChar},
+			End:   protocol.Position{Line: endLine, Character: endChar},
+		}
+	}
+
+	content := "# Title\n\nSee [[a3f9]] and\n[the note](dir/note.md).\n"
+	// ID-only wiki link.
+	test(content, "a3f9", 9, 49, rng(2, 6, 2, 10))
+	// The href is looked up in the snippet, not before.
+	test(content, "dir/note.md", 9, 49, rng(3, 11, 3, 22))
+	test(content, "dir/note", 9, 49, rng(3, 11, 3, 19))
+	// Falls back on the snippet when the href is not written as is.
+	test(content, "dir/other.md", 9, 49, rng(2, 0, 3, 23))
+	test(content, "", 9, 49, rng(2, 0, 3, 23))
+	// Invalid snippet offsets.
+	test(content, "a3f9", 9, 100, protocol.Range{})
+	test(content, "a3f9", 20, 9, protocol.Range{})
+}
@@ -0,0 +1,81 @@
+package lsp
+
+import (
+	"fmt"
+
+	"github.com/mickael-menu/zk/internal/core"
+	dateutil "github.com/mickael-menu/zk/internal/util/date"
+	"github.com/mickael-menu/zk/internal/util/errors"
+	"github.com/tliron/glsp"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+const cmdJournal = "zk.journal"
+
+// cmdJournalOpts holds the options of the zk.journal command, which mirror
+// the options of `zk journal`.
+type cmdJournalOpts struct {
+	Period string `json:"period"`
+	Date   string `json:"date"`
+	// Number of periods to shift the date with, e.g. -1 for the previous
+	// journal note.
+	Offset int         `json:"offset"`
+	Edit   jsonBoolean `json:"edit"`
+}
+
+func (s *Server) executeCommandJournal(context *glsp.Context, args []interface{}) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("zk.journal expects a notebook path as first argument")
+	}
+	wd, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("zk.journal expects a notebook path as first argument, got: %v", args[0])
+	}
+
+	opts := cmdJournalOpts{Period: string(core.JournalPeriodDaily)}
+	if len(args) > 1 {
+		arg, ok := args[1].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("zk.journal expects a dictionary of options as second argument, got: %v", args[1])
+		}
+		err := unmarshalJSON(arg, &opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse zk.journal args, got: %v", arg)
+		}
+	}
+
+	notebook, err := s.notebooks.Open(wd)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := core.JournalPeriodFromString(opts.Period)
+	if err != nil {
+		return nil, err
+	}
+	date, err := dateutil.TimeFromNatural(opts.Date)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s, failed to parse the `date` option", opts.Date)
+	}
+
+	path, err := notebook.JournalNote(core.JournalOpts{
+		Period: period,
+		Date:   date,
+		Offset: opts.Offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Index the notebook to be able to navigate to the journal note.
+	notebook.Index(false)
+
+	if opts.Edit {
+		go context.Call(protocol.ServerWindowShowDocument, protocol.ShowDocumentParams{
+			URI:       pathToURI(path),
+			TakeFocus: boolPtr(true),
+		}, nil)
+	}
+
+	return map[string]interface{}{"path": path}, nil
+}
@@ -0,0 +1,216 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/mickael-menu/zk/internal/core"
+	dateutil "github.com/mickael-menu/zk/internal/util/date"
+	"github.com/mickael-menu/zk/internal/util/errors"
+	"github.com/mickael-menu/zk/internal/util/opt"
+)
+
+const cmdList = "zk.list"
+
+// cmdListOpts holds the options of the zk.list command, which mirror the
+// filtering options of `zk list`.
+type cmdListOpts struct {
+	// Fields of the notes to return, as named in the JSON output of
+	// `zk list --format json`.
+	Select []string `json:"select"`
+
+	Hrefs          []string `json:"hrefs"`
+	ExcludeHrefs   []string `json:"excludeHrefs"`
+	Limit          int      `json:"limit"`
+	Match          string   `json:"match"`
+	ExactMatch     bool     `json:"exactMatch"`
+	Tags           []string `json:"tags"`
+	Meta           []string `json:"meta"`
+	Mention        []string `json:"mention"`
+	MentionedBy    []string `json:"mentionedBy"`
+	LinkTo         []string `json:"linkTo"`
+	LinkedBy       []string `json:"linkedBy"`
+	Orphan         bool     `json:"orphan"`
+	Related        []string `json:"related"`
+	MaxDistance    int      `json:"maxDistance"`
+	Recursive      bool     `json:"recursive"`
+	CreatedBefore  string   `json:"createdBefore"`
+	CreatedAfter   string   `json:"createdAfter"`
+	ModifiedBefore string   `json:"modifiedBefore"`
+	ModifiedAfter  string   `json:"modifiedAfter"`
+	Sort           []string `json:"sort"`
+}
+
+func (s *Server) executeCommandList(args []interface{}) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("zk.list expects a notebook path as first argument")
+	}
+	path, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("zk.list expects a notebook path as first argument, got: %v", args[0])
+	}
+
+	var opts cmdListOpts
+	if len(args) > 1 {
+		arg, ok := args[1].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("zk.list expects a dictionary of options as second argument, got: %v", args[1])
+		}
+		err := unmarshalJSON(arg, &opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse zk.list args, got: %v", arg)
+		}
+	}
+	if len(opts.Select) == 0 {
+		return nil, fmt.Errorf("zk.list expects a `select` option with the list of fields to return")
+	}
+
+	notebook, err := s.notebooks.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	findOpts, err := opts.NewNoteFindOpts(notebook)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid zk.list options")
+	}
+
+	notes, err := notebook.FindNotes(findOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	// The fields are the same as the JSON output of `zk list`.
+	formatter, err := notebook.NewNoteFormatter("{{json .}}")
+	if err != nil {
+		return nil, err
+	}
+
+	listNotes := []map[string]interface{}{}
+	for _, note := range notes {
+		js, err := formatter(note)
+		if err != nil {
+			return nil, err
+		}
+		var fields map[string]interface{}
+		err = json.Unmarshal([]byte(js), &fields)
+		if err != nil {
+			return nil, err
+		}
+
+		listNote := map[string]interface{}{}
+		for _, field := range opts.Select {
+			if value, ok := fields[field]; ok {
+				listNote[field] = value
+			}
+		}
+		listNotes = append(listNotes, listNote)
+	}
+
+	return listNotes, nil
+}
+
+// NewNoteFindOpts converts the zk.list options into core.NoteFindOpts.
+// Paths are relative to the notebook root, unless absolute, and must be
+// located inside the notebook.
+func (o cmdListOpts) NewNoteFindOpts(notebook *core.Notebook) (core.NoteFindOpts, error) {
+	opts := core.NoteFindOpts{
+		Match:       opt.NewNotEmptyString(o.Match),
+		ExactMatch:  o.ExactMatch,
+		Tags:        o.Tags,
+		Mention:     o.Mention,
+		MentionedBy: o.MentionedBy,
+		Orphan:      o.Orphan,
+		Limit:       o.Limit,
+	}
+
+	relPaths := func(paths []string) ([]string, bool, error) {
+		relPaths := []string{}
+		for _, path := range paths {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(notebook.Path, path)
+			}
+			path, err := notebook.RelPath(path)
+			if err != nil {
+				return nil, false, err
+			}
+			relPaths = append(relPaths, path)
+		}
+		return relPaths, len(relPaths) > 0, nil
+	}
+
+	paths, ok, err := relPaths(o.Hrefs)
+	if err != nil {
+		return opts, err
+	}
+	if ok {
+		opts.IncludePaths = paths
+	}
+	paths, ok, err = relPaths(o.ExcludeHrefs)
+	if err != nil {
+		return opts, err
+	}
+	if ok {
+		opts.ExcludePaths = paths
+	}
+	paths, ok, err = relPaths(o.LinkTo)
+	if err != nil {
+		return opts, err
+	}
+	if ok {
+		opts.LinkTo = &core.LinkFilter{
+			Paths:       paths,
+			Recursive:   o.Recursive,
+			MaxDistance: o.MaxDistance,
+		}
+	}
+	paths, ok, err = relPaths(o.LinkedBy)
+	if err != nil {
+		return opts, err
+	}
+	if ok {
+		opts.LinkedBy = &core.LinkFilter{
+			Paths:       paths,
+			Recursive:   o.Recursive,
+			MaxDistance: o.MaxDistance,
+		}
+	}
+	paths, ok, err = relPaths(o.Related)
+	if err != nil {
+		return opts, err
+	}
+	if ok {
+		opts.Related = paths
+	}
+
+	if len(o.Meta) > 0 {
+		opts.Metadata, err = core.MetadataFiltersFromStrings(o.Meta)
+		if err != nil {
+			return opts, err
+		}
+	}
+
+	for _, date := range []struct {
+		value string
+		opt   **time.Time
+	}{
+		{o.CreatedBefore, &opts.CreatedEnd},
+		{o.CreatedAfter, &opts.CreatedStart},
+		{o.ModifiedBefore, &opts.ModifiedEnd},
+		{o.ModifiedAfter, &opts.ModifiedStart},
+	} {
+		if date.value == "" {
+			continue
+		}
+		value, err := dateutil.TimeFromNatural(date.value)
+		if err != nil {
+			return opts, err
+		}
+		*date.opt = &value
+	}
+
+	opts.Sorters, err = core.NoteSortersFromStrings(o.Sort)
+	return opts, err
+}
@@ -0,0 +1,233 @@
+package lsp
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mickael-menu/zk/internal/core"
+	strutil "github.com/mickael-menu/zk/internal/util/strings"
+	"github.com/tliron/glsp"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func (s *Server) refreshDiagnosticsOfDocument(doc *document, notify glsp.NotifyFunc, delay bool) {
+	if doc.NeedsRefreshDiagnostics { // Already refreshing
+		return
+	}
+
+	notebook, err := s.notebookOf(doc)
+	if err != nil {
+		s.logger.Err(err)
+		return
+	}
+
+	diagConfig := notebook.Config.LSP.Diagnostics
+	if diagConfig == (core.LSPDiagnosticConfig{}) {
+		// No diagnostic enabled.
+		return
+	}
+
+	doc.NeedsRefreshDiagnostics = true
+	go func() {
+		if delay {
+			time.Sleep(1 * time.Second)
+		}
+		doc.NeedsRefreshDiagnostics = false
+
+		diagnostics, err := s.documentDiagnostics(doc, notebook, diagConfig)
+		if err != nil {
+			s.logger.Err(err)
+			return
+		}
+
+		go notify(protocol.ServerTextDocumentPublishDiagnostics, protocol.PublishDiagnosticsParams{
+			URI:         doc.URI,
+			Diagnostics: diagnostics,
+		})
+	}()
+}
+
+// deadLinkDiagnosticMessage is the message of the diagnostics reporting a
+// dead link, used to offer quick fixes for them.
+const deadLinkDiagnosticMessage = "not found"
+
+// documentDiagnostics computes the diagnostics of the given document enabled
+// in the configuration.
+func (s *Server) documentDiagnostics(doc *document, notebook *core.Notebook, diagConfig core.LSPDiagnosticConfig) ([]protocol.Diagnostic, error) {
+	diagnostics := []protocol.Diagnostic{}
+	report := func(rng protocol.Range, severity core.LSPDiagnosticSeverity, message string) {
+		if severity == core.LSPDiagnosticNone {
+			return
+		}
+		lspSeverity := protocol.DiagnosticSeverity(severity)
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Range:    rng,
+			Severity: &lspSeverity,
+			Source:   stringPtr("zk"),
+			Message:  message,
+		})
+	}
+
+	// The editor buffer might differ from the indexed note.
+	parsed, err := notebook.ParseNote(doc.Content)
+	if err != nil {
+		return nil, err
+	}
+	path, err := notebook.RelPath(doc.Path)
+	if err != nil {
+		return nil, err
+	}
+	note, err := s.indexedNote(notebook, path)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := doc.DocumentLinks()
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		if strutil.IsURL(link.Href) {
+			continue
+		}
+
+		// Anchors without a path, e.g. [](#section), point to a section of
+		// the current note.
+		if strings.HasPrefix(link.Href, "#") {
+			_, anchor := core.SplitHref(link.Href)
+			if core.FindSectionByAnchor(parsed.Sections, anchor) == nil {
+				report(link.Range, diagConfig.BrokenAnchor, fmt.Sprintf("section #%s not found", anchor))
+			}
+			continue
+		}
+
+		target, err := s.noteForHref(link.Href, doc, notebook)
+		if err != nil {
+			s.logger.Err(err)
+			continue
+		}
+
+		_, anchor := core.SplitHref(link.Href)
+		switch {
+		case target == nil:
+			report(link.Range, diagConfig.DeadLink, deadLinkDiagnosticMessage)
+		case anchor != "" && diagConfig.BrokenAnchor != core.LSPDiagnosticNone && !s.hasSection(notebook, target, anchor):
+			report(link.Range, diagConfig.BrokenAnchor, fmt.Sprintf("section #%s not found", anchor))
+		case anchor == "" && target.Path == path && diagConfig.SelfLink != core.LSPDiagnosticNone:
+			report(link.Range, diagConfig.SelfLink, "link to the note itself")
+		case !link.HasTitle:
+			report(link.Range, diagConfig.WikiTitle, target.Title)
+		}
+	}
+
+	// Note-wide diagnostics are reported at the top of the document.
+	top := protocol.Range{}
+
+	if parsed.Title.NonEmpty().IsNull() {
+		report(top, diagConfig.MissingTitle, "missing title")
+	}
+
+	if diagConfig.Orphan != core.LSPDiagnosticNone {
+		opts := core.NoteFindOpts{
+			LinkTo: &core.LinkFilter{Paths: []string{path}},
+			Limit:  1,
+		}
+		if note != nil {
+			opts = opts.ExcludingID(note.ID)
+		}
+		backlinks, err := notebook.FindMinimalNotes(opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(backlinks) == 0 {
+			report(top, diagConfig.Orphan, "no other note links to it")
+		}
+	}
+
+	if diagConfig.UnknownTag != core.LSPDiagnosticNone && len(parsed.Tags) > 0 {
+		unknownTags, err := s.unknownTags(notebook, note, parsed.Tags)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range unknownTags {
+			report(tagRange(doc.Content, tag), diagConfig.UnknownTag, fmt.Sprintf("tag %s is not used by any other note", tag))
+		}
+	}
+
+	return diagnostics, nil
+}
+
+// indexedNote returns the indexed note at the given path, relative to the
+// notebook root, or nil if it was not indexed yet.
+func (s *Server) indexedNote(notebook *core.Notebook, path string) (*core.ContextualNote, error) {
+	notes, err := notebook.FindNotes(core.NoteFindOpts{
+		IncludePaths: []string{path},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, note := range notes {
+		if note.Path == path {
+			return &note, nil
+		}
+	}
+	return nil, nil
+}
+
+// unknownTags returns the given tags which are not used by any other note
+// than the current one.
+func (s *Server) unknownTags(notebook *core.Notebook, note *core.ContextualNote, tags []string) ([]string, error) {
+	collections, err := notebook.FindCollections(core.CollectionKindTag, nil)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for _, collection := range collections {
+		counts[collection.Name] = collection.NoteCount
+	}
+	// The indexed version of the current note doesn't count.
+	if note != nil {
+		for _, tag := range note.Tags {
+			counts[tag]--
+		}
+	}
+
+	unknownTags := []string{}
+	for _, tag := range tags {
+		if counts[tag] <= 0 {
+			unknownTags = append(unknownTags, tag)
+		}
+	}
+	return unknownTags, nil
+}
+
+// tagRange returns the range of the first occurrence of the given tag in the
+// content, or an empty range at the top of the document if not found.
+func tagRange(content string, tag string) protocol.Range {
+	isWordRune := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_' || r == '-' || r == '/'
+	}
+
+	offset := 0
+	for {
+		i := strings.Index(content[offset:], tag)
+		if i < 0 || tag == "" {
+			return protocol.Range{}
+		}
+		start := offset + i
+		end := start + len(tag)
+		offset = end
+
+		before, _ := utf8.DecodeLastRuneInString(content[:start])
+		after, _ := utf8.DecodeRuneInString(content[end:])
+		if (start == 0 || !isWordRune(before)) && (end == len(content) || !isWordRune(after)) {
+			return protocol.Range{
+				Start: positionAt(content, start),
+				End:   positionAt(content, end),
+			}
+		}
+	}
+}
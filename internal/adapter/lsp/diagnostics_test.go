@@ -0,0 +1,36 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestTagRange(t *testing.T) {
+	test := func(content string, tag string, expected protocol.Range) {
+		assert.Equal(t, tagRange(content, tag), expected)
+	}
+
+	rng := func(line, start, end uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: line, Character: start},
+			End:   protocol.Position{Line: line, Character: end},
+		}
+	}
+
+	test("", "tag", protocol.Range{})
+	test("No tags", "tag", protocol.Range{})
+	test("", "", protocol.Range{})
+	test("#tag", "tag", rng(0, 1, 4))
+	test("Title\n\nA #tag here", "tag", rng(2, 3, 6))
+	test(":a:tag:b:", "tag", rng(0, 3, 6))
+	// Skips the occurrences which are part of a longer word or tag.
+	test("#tagged #big-tag #tag", "tag", rng(0, 18, 21))
+	test("#tag/child #tag", "tag", rng(0, 12, 15))
+	test("#parent/tag", "parent/tag", rng(0, 1, 11))
+	test("#stag_tag", "tag", protocol.Range{})
+	// Positions are counted in UTF-16 code units.
+	test("😀 #tag", "tag", rng(0, 4, 7))
+	test("é #tag", "tag", rng(0, 3, 6))
+}
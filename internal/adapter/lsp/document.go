@@ -61,6 +61,15 @@ func (s *documentStore) Get(pathOrURI string) (*document, bool) {
 	return d, ok
 }
 
+// All returns the opened documents.
+func (s *documentStore) All() []*document {
+	docs := make([]*document, 0, len(s.documents))
+	for _, doc := range s.documents {
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
 func (s *documentStore) normalizePath(pathOrUri string) (string, error) {
 	path, err := uriToPath(pathOrUri)
 	if err != nil {
@@ -170,6 +179,48 @@ func (d *document) LookForward(pos protocol.Position, length int) string {
 var wikiLinkRegex = regexp.MustCompile(`\[?\[\[(.+?)(?:\|(.+?))?\]\]`)
 var markdownLinkRegex = regexp.MustCompile(`\[([^\]]+?[^\\])\]\((.+?[^\\])\)`)
 
+var wikiLinkAnchorRegex = regexp.MustCompile(`\[\[([^\]|#]+)#([^\]|#]*)$`)
+var markdownLinkAnchorRegex = regexp.MustCompile(`\]\(([^)#\s]+)#([^)#\s]*)$`)
+
+// AnchorAt returns the link whose #anchor is being written at the given
+// position, e.g. `[[note#hea`. The partial anchor is returned with the href
+// of the link.
+func (d *document) AnchorAt(pos protocol.Position) *documentAnchor {
+	line := d.LookBehind(pos, int(pos.Character))
+
+	if match := wikiLinkAnchorRegex.FindStringSubmatch(line); match != nil {
+		return &documentAnchor{
+			Href:       strings.TrimSpace(match[1]),
+			Partial:    match[2],
+			IsWikiLink: true,
+		}
+	}
+
+	if match := markdownLinkAnchorRegex.FindStringSubmatch(line); match != nil {
+		href := match[1]
+		// Valid Markdown links are percent-encoded.
+		if decodedHref, err := url.PathUnescape(href); err == nil {
+			href = decodedHref
+		}
+		return &documentAnchor{
+			Href:    href,
+			Partial: match[2],
+		}
+	}
+
+	return nil
+}
+
+// documentAnchor is a #anchor being written in a link.
+type documentAnchor struct {
+	// Href of the link, without the anchor.
+	Href string
+	// Part of the anchor already written.
+	Partial string
+	// Indicates whether this is a [[wiki link]].
+	IsWikiLink bool
+}
+
 // DocumentLinkAt returns the internal or external link found in the document
 // at the given position.
 func (d *document) DocumentLinkAt(pos protocol.Position) (*documentLink, error) {
@@ -195,25 +246,24 @@ func (d *document) DocumentLinks() ([]documentLink, error) {
 	lines := d.GetLines()
 	for lineIndex, line := range lines {
 
-		appendLink := func(href string, start, end int, hasTitle bool) {
-			if href == "" {
-				return
+		lineRange := func(start, end int) protocol.Range {
+			return protocol.Range{
+				Start: protocol.Position{
+					Line:      protocol.UInteger(lineIndex),
+					Character: protocol.UInteger(start),
+				},
+				End: protocol.Position{
+					Line:      protocol.UInteger(lineIndex),
+					Character: protocol.UInteger(end),
+				},
 			}
+		}
 
-			links = append(links, documentLink{
-				Href: href,
-				Range: protocol.Range{
-					Start: protocol.Position{
-						Line:      protocol.UInteger(lineIndex),
-						Character: protocol.UInteger(start),
-					},
-					End: protocol.Position{
-						Line:      protocol.UInteger(lineIndex),
-						Character: protocol.UInteger(end),
-					},
-				},
-				HasTitle: hasTitle,
-			})
+		appendLink := func(link documentLink) {
+			if link.Href == "" {
+				return
+			}
+			links = append(links, link)
 		}
 
 		for _, match := range markdownLinkRegex.FindAllStringSubmatchIndex(line, -1) {
@@ -222,13 +272,27 @@ func (d *document) DocumentLinks() ([]documentLink, error) {
 			if decodedHref, err := url.PathUnescape(href); err == nil {
 				href = decodedHref
 			}
-			appendLink(href, match[0], match[1], true)
+			appendLink(documentLink{
+				Href:      href,
+				Range:     lineRange(match[0], match[1]),
+				HrefRange: lineRange(match[4], match[5]),
+				Title:     line[match[2]:match[3]],
+				HasTitle:  true,
+			})
 		}
 
 		for _, match := range wikiLinkRegex.FindAllStringSubmatchIndex(line, -1) {
-			href := line[match[2]:match[3]]
-			hasTitle := match[4] != -1
-			appendLink(href, match[0], match[1], hasTitle)
+			link := documentLink{
+				Href:       line[match[2]:match[3]],
+				Range:      lineRange(match[0], match[1]),
+				HrefRange:  lineRange(match[2], match[3]),
+				IsWikiLink: true,
+			}
+			if match[4] != -1 {
+				link.Title = line[match[4]:match[5]]
+				link.HasTitle = true
+			}
+			appendLink(link)
 		}
 	}
 
@@ -238,7 +302,13 @@ func (d *document) DocumentLinks() ([]documentLink, error) {
 type documentLink struct {
 	Href  string
 	Range protocol.Range
+	// HrefRange is the range of the raw href in the link.
+	HrefRange protocol.Range
+	// Title of the link, if any.
+	Title string
 	// HasTitle indicates whether this link has a title information. For
 	// example [[filename]] doesn't but [[filename|title]] does.
 	HasTitle bool
+	// IsWikiLink indicates whether this link uses the [[wiki-link]] syntax.
+	IsWikiLink bool
 }
@@ -0,0 +1,74 @@
+package lsp
+
+import (
+	"encoding/json"
+
+	"github.com/tliron/glsp"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// protocolHandler extends the LSP 3.16 protocol handler with the requests of
+// LSP 3.17 used by zk.
+type protocolHandler struct {
+	protocol.Handler
+
+	TextDocumentInlayHint func(context *glsp.Context, params *inlayHintParams) ([]inlayHint, error)
+}
+
+const methodTextDocumentInlayHint = "textDocument/inlayHint"
+
+// Handle implements glsp.Handler.
+func (h *protocolHandler) Handle(context *glsp.Context) (r interface{}, validMethod bool, validParams bool, err error) {
+	switch context.Method {
+	case methodTextDocumentInlayHint:
+		validMethod = true
+		if h.TextDocumentInlayHint == nil {
+			return
+		}
+		var params inlayHintParams
+		if err = json.Unmarshal(context.Params, &params); err == nil {
+			validParams = true
+			r, err = h.TextDocumentInlayHint(context, &params)
+		}
+		return
+
+	default:
+		return h.Handler.Handle(context)
+	}
+}
+
+// initializeResult is the result of the initialize request, which allows
+// declaring the capabilities of LSP 3.17.
+type initializeResult struct {
+	Capabilities map[string]interface{}               `json:"capabilities"`
+	ServerInfo   *protocol.InitializeResultServerInfo `json:"serverInfo,omitempty"`
+}
+
+// newInitializeResult declares the given capabilities, with the additional
+// ones supported by the handler.
+func (h *protocolHandler) newInitializeResult(capabilities protocol.ServerCapabilities, serverInfo *protocol.InitializeResultServerInfo) (initializeResult, error) {
+	result := initializeResult{ServerInfo: serverInfo}
+	err := unmarshalJSON(capabilities, &result.Capabilities)
+	if err != nil {
+		return result, err
+	}
+
+	if h.TextDocumentInlayHint != nil {
+		result.Capabilities["inlayHintProvider"] = true
+	}
+	return result, nil
+}
+
+// inlayHintParams are the parameters of a textDocument/inlayHint request.
+type inlayHintParams struct {
+	TextDocument protocol.TextDocumentIdentifier `json:"textDocument"`
+	Range        protocol.Range                  `json:"range"`
+}
+
+// inlayHint is an inline annotation displayed by the editor.
+type inlayHint struct {
+	Position     protocol.Position `json:"position"`
+	Label        string            `json:"label"`
+	PaddingLeft  bool              `json:"paddingLeft,omitempty"`
+	PaddingRight bool              `json:"paddingRight,omitempty"`
+}
@@ -0,0 +1,215 @@
+package lsp
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mickael-menu/zk/internal/core"
+	"github.com/mickael-menu/zk/internal/util/paths"
+	strutil "github.com/mickael-menu/zk/internal/util/strings"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// maxLinkCandidates is the maximum number of existing notes suggested to
+// replace a dead link.
+const maxLinkCandidates = 3
+
+// deadLinkQuickFixes returns the code actions resolving the dead links
+// reported by the given diagnostics, by creating the missing note or
+// replacing the href with one of the closest existing notes.
+func (s *Server) deadLinkQuickFixes(doc *document, notebook *core.Notebook, diagnostics []protocol.Diagnostic) ([]protocol.CodeAction, error) {
+	actions := []protocol.CodeAction{}
+
+	deadLinkDiagnostics := []protocol.Diagnostic{}
+	for _, diagnostic := range diagnostics {
+		if isDeadLinkDiagnostic(diagnostic) {
+			deadLinkDiagnostics = append(deadLinkDiagnostics, diagnostic)
+		}
+	}
+	// Most code action requests are sent when moving the cursor, without
+	// any dead link to fix.
+	if len(deadLinkDiagnostics) == 0 {
+		return actions, nil
+	}
+
+	links, err := doc.DocumentLinks()
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []core.MinimalNote
+	for _, link := range links {
+		if strutil.IsURL(link.Href) || strings.HasPrefix(link.Href, "#") {
+			continue
+		}
+
+		linkDiagnostics := []protocol.Diagnostic{}
+		for _, diagnostic := range deadLinkDiagnostics {
+			if diagnostic.Range == link.Range {
+				linkDiagnostics = append(linkDiagnostics, diagnostic)
+			}
+		}
+		if len(linkDiagnostics) == 0 {
+			continue
+		}
+		// The diagnostics might be outdated if the note was created since.
+		target, err := s.noteForHref(link.Href, doc, notebook)
+		if target != nil || err != nil {
+			continue
+		}
+
+		action, err := s.createNoteQuickFix(doc, notebook, link, linkDiagnostics)
+		if err != nil {
+			s.logger.Err(err)
+		} else if action != nil {
+			actions = append(actions, *action)
+		}
+
+		if notes == nil {
+			notes, err = notebook.FindMinimalNotes(core.NoteFindOpts{})
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, candidate := range closestHrefs(doc, notebook, link.Href, notes) {
+			actions = append(actions, s.replaceHrefQuickFix(doc, notebook, link, candidate, linkDiagnostics))
+		}
+	}
+
+	return actions, nil
+}
+
+// isDeadLinkDiagnostic returns whether the given diagnostic reports a dead
+// link found by zk.
+func isDeadLinkDiagnostic(diagnostic protocol.Diagnostic) bool {
+	return diagnostic.Source != nil && *diagnostic.Source == "zk" &&
+		diagnostic.Message == deadLinkDiagnosticMessage
+}
+
+// createNoteQuickFix returns a code action creating the note missing at the
+// path of the given link, using the template of its group.
+func (s *Server) createNoteQuickFix(doc *document, notebook *core.Notebook, link documentLink, diagnostics []protocol.Diagnostic) (*protocol.CodeAction, error) {
+	href, _ := core.SplitHref(link.Href)
+	path, err := notebook.RelPath(filepath.Join(filepath.Dir(doc.Path), href))
+	if err != nil {
+		// Outside the notebook.
+		return nil, nil
+	}
+
+	title := link.Title
+	if title == "" {
+		title = paths.FilenameStem(path)
+	}
+
+	opts := cmdNewOpts{
+		Title:    title,
+		Dir:      filepath.Dir(filepath.Join(notebook.Path, path)),
+		Filename: filepath.Base(path),
+	}
+	var jsonOpts map[string]interface{}
+	err = unmarshalJSON(opts, &jsonOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &protocol.CodeAction{
+		Title:       fmt.Sprintf("Create the note %s", path),
+		Kind:        stringPtr(protocol.CodeActionKindQuickFix),
+		Diagnostics: diagnostics,
+		Command: &protocol.Command{
+			Title:     "Create the note",
+			Command:   cmdNew,
+			Arguments: []interface{}{notebook.Path, jsonOpts},
+		},
+	}, nil
+}
+
+// replaceHrefQuickFix returns a code action replacing the href of the given
+// link with another one, keeping its anchor.
+func (s *Server) replaceHrefQuickFix(doc *document, notebook *core.Notebook, link documentLink, newHref string, diagnostics []protocol.Diagnostic) protocol.CodeAction {
+	newText := newHref
+	if !link.IsWikiLink && notebook.Config.Format.Markdown.LinkEncodePath {
+		segments := strings.Split(filepath.ToSlash(newText), "/")
+		for i, segment := range segments {
+			segments[i] = url.PathEscape(segment)
+		}
+		newText = strings.Join(segments, "/")
+	}
+	if _, anchor := core.SplitHref(link.Href); anchor != "" {
+		newText += "#" + anchor
+	}
+
+	return protocol.CodeAction{
+		Title:       fmt.Sprintf("Replace with %s", newHref),
+		Kind:        stringPtr(protocol.CodeActionKindQuickFix),
+		Diagnostics: diagnostics,
+		Edit: &protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+				doc.URI: {{Range: link.HrefRange, NewText: newText}},
+			},
+		},
+	}
+}
+
+// closestHrefs returns the hrefs of the notes matching the most closely the
+// given dead href, by comparing it with their paths and titles.
+func closestHrefs(doc *document, notebook *core.Notebook, href string, notes []core.MinimalNote) []string {
+	href, _ = core.SplitHref(href)
+	keepExtension := filepath.Ext(href) != ""
+	stem := strings.ToLower(paths.FilenameStem(href))
+	// Ignore candidates which are too far off to be typos.
+	maxDistance := len([]rune(stem))/3 + 1
+
+	type candidate struct {
+		href     string
+		distance int
+	}
+	candidates := []candidate{}
+
+	for _, note := range notes {
+		path := filepath.Join(notebook.Path, note.Path)
+		if path == doc.Path {
+			continue
+		}
+		noteHref, err := filepath.Rel(filepath.Dir(doc.Path), path)
+		if err != nil {
+			continue
+		}
+		if !keepExtension {
+			noteHref = strings.TrimSuffix(noteHref, filepath.Ext(noteHref))
+		}
+
+		distance := strutil.EditDistance(strings.ToLower(href), strings.ToLower(noteHref))
+		if d := strutil.EditDistance(stem, strings.ToLower(paths.FilenameStem(note.Path))); d < distance {
+			distance = d
+		}
+		if note.Title != "" {
+			if d := strutil.EditDistance(stem, strings.ToLower(note.Title)); d < distance {
+				distance = d
+			}
+		}
+
+		if distance <= maxDistance {
+			candidates = append(candidates, candidate{href: noteHref, distance: distance})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].href < candidates[j].href
+	})
+
+	hrefs := []string{}
+	for i, candidate := range candidates {
+		if i >= maxLinkCandidates {
+			break
+		}
+		hrefs = append(hrefs, candidate.href)
+	}
+	return hrefs
+}
@@ -0,0 +1,43 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/mickael-menu/zk/internal/core"
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+)
+
+func TestClosestHrefs(t *testing.T) {
+	doc := &document{Path: "/notebook/dir/a.md"}
+	notebook := &core.Notebook{Path: "/notebook"}
+
+	test := func(href string, notes []core.MinimalNote, expected []string) {
+		assert.Equal(t, closestHrefs(doc, notebook, href, notes), expected)
+	}
+
+	notes := []core.MinimalNote{
+		{Path: "dir/a.md", Title: "Notee"},
+		{Path: "dir/note.md"},
+		{Path: "dir/zebra.md"},
+		{Path: "other/note.md"},
+		{Path: "dir/nothing.md"},
+	}
+
+	// Ranked by distance then href, ignoring the current note and the
+	// candidates too far off.
+	test("notee", notes, []string{"../other/note", "note"})
+	test("zebar", notes, []string{"zebra"})
+	test("unrelated", notes, []string{})
+	// The anchor is ignored.
+	test("notee#section", notes, []string{"../other/note", "note"})
+	// The extension is kept only if the dead href has one.
+	test("notee.md", notes, []string{"../other/note.md", "note.md"})
+
+	// The titles are compared as well, and the number of candidates is
+	// limited.
+	notes = append(notes,
+		core.MinimalNote{Path: "dir/x.md", Title: "Notee"},
+		core.MinimalNote{Path: "dir/notes.md"},
+	)
+	test("notee", notes, []string{"x", "../other/note", "note"})
+}
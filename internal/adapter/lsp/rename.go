@@ -0,0 +1,256 @@
+package lsp
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/mickael-menu/zk/internal/core"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// renameLinkTarget renames the note targeted by the link under the caret,
+// returning a workspace edit which moves the note file and rewrites its
+// inbound links.
+//
+// The new name is a path relative to the directory of the current document,
+// as it would be written in a link. The extension of the note is kept when
+// omitted.
+func (s *Server) renameLinkTarget(doc *document, pos protocol.Position, newName string, clientCapabilities protocol.ClientCapabilities) (*protocol.WorkspaceEdit, error) {
+	link, err := doc.DocumentLinkAt(pos)
+	if link == nil || err != nil {
+		return nil, err
+	}
+
+	notebook, err := s.notebookOf(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.noteForHref(link.Href, doc, notebook)
+	if target == nil || err != nil {
+		return nil, err
+	}
+
+	if !supportsRenameFile(clientCapabilities) {
+		return nil, fmt.Errorf("renaming a note requires an editor supporting file operations in workspace edits")
+	}
+
+	from := filepath.Join(notebook.Path, target.Path)
+	to := filepath.Join(filepath.Dir(doc.Path), newName)
+	if filepath.Ext(to) == "" {
+		to += filepath.Ext(from)
+	}
+
+	move, changes, err := s.planNoteMove(notebook, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	documentChanges := []interface{}{}
+	for _, uri := range sortedURIs(changes) {
+		edits := []interface{}{}
+		for _, edit := range changes[uri] {
+			edits = append(edits, edit)
+		}
+		documentChanges = append(documentChanges, protocol.TextDocumentEdit{
+			TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+				TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: uri},
+			},
+			Edits: edits,
+		})
+	}
+	documentChanges = append(documentChanges, protocol.RenameFile{
+		Kind:   "rename",
+		OldURI: pathToURI(filepath.Join(notebook.Path, move.From)),
+		NewURI: pathToURI(filepath.Join(notebook.Path, move.To)),
+	})
+
+	return &protocol.WorkspaceEdit{DocumentChanges: documentChanges}, nil
+}
+
+// willRenameFiles returns the edits rewriting the links pointing to the
+// notes about to be renamed by the editor.
+func (s *Server) willRenameFiles(files []protocol.FileRename) (*protocol.WorkspaceEdit, error) {
+	changes := map[protocol.DocumentUri][]protocol.TextEdit{}
+
+	for _, file := range files {
+		from, to, notebook, err := s.fileRename(file)
+		if err != nil {
+			s.logger.Err(err)
+			continue
+		}
+
+		_, fileChanges, err := s.planNoteMove(notebook, from, to)
+		if err != nil {
+			// Most likely not a note.
+			s.logger.Err(err)
+			continue
+		}
+		for uri, edits := range fileChanges {
+			changes[uri] = append(changes[uri], edits...)
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return &protocol.WorkspaceEdit{Changes: changes}, nil
+}
+
+// didRenameFiles updates the index after the editor renamed some notes, so
+// that they keep their identity.
+func (s *Server) didRenameFiles(files []protocol.FileRename) {
+	for _, file := range files {
+		from, to, notebook, err := s.fileRename(file)
+		if err != nil {
+			s.logger.Err(err)
+			continue
+		}
+
+		err = notebook.NoteMoved(from, to)
+		if err != nil {
+			s.logger.Err(err)
+			continue
+		}
+
+		// The watcher already reindexes the renamed notes.
+		if !s.isWatched(notebook) {
+			_, err = notebook.Index(false)
+			s.logger.Err(err)
+		}
+	}
+}
+
+// fileRename resolves the paths of a renamed file and its notebook.
+func (s *Server) fileRename(file protocol.FileRename) (from string, to string, notebook *core.Notebook, err error) {
+	from, err = uriToPath(file.OldURI)
+	if err != nil {
+		return
+	}
+	to, err = uriToPath(file.NewURI)
+	if err != nil {
+		return
+	}
+	notebook, err = s.notebooks.Open(from)
+	return
+}
+
+// planNoteMove computes the text edits needed to move the note at path
+// `from` to `to`, grouped by document URI.
+//
+// The edits are computed against the content of the open documents, which
+// might have unsaved changes, and against the files on the disk otherwise.
+func (s *Server) planNoteMove(notebook *core.Notebook, from string, to string) (core.NoteMove, map[protocol.DocumentUri][]protocol.TextEdit, error) {
+	changes := map[protocol.DocumentUri][]protocol.TextEdit{}
+
+	move, err := notebook.PlanNoteMove(from, to)
+	if err != nil {
+		return move, changes, err
+	}
+
+	contents, err := s.noteContents(notebook, move)
+	if err != nil {
+		return move, changes, err
+	}
+
+	// The edits are planned from the notes on the disk. If an open document
+	// has unsaved changes, the plan is rebuilt with its content.
+	if !editsMatch(notebook, move.Edits, contents) {
+		openContents := map[string]string{}
+		for path, content := range contents {
+			if _, ok := s.documents.Get(pathToURI(path)); ok {
+				openContents[path] = content
+			}
+		}
+		move, err = notebook.PlanNoteMoveWithContents(from, to, openContents)
+		if err != nil {
+			return move, changes, err
+		}
+		contents, err = s.noteContents(notebook, move)
+		if err != nil {
+			return move, changes, err
+		}
+		if !editsMatch(notebook, move.Edits, contents) {
+			return move, changes, fmt.Errorf("%s: the linking notes changed while planning the move", move.From)
+		}
+	}
+
+	for _, edit := range move.Edits {
+		path := filepath.Join(notebook.Path, edit.Path)
+		content := contents[path]
+
+		uri := pathToURI(path)
+		changes[uri] = append(changes[uri], protocol.TextEdit{
+			Range: protocol.Range{
+				Start: positionAt(content, edit.Start),
+				End:   positionAt(content, edit.End),
+			},
+			NewText: edit.NewText,
+		})
+	}
+
+	return move, changes, nil
+}
+
+// noteContents returns the current content of the notes edited by the given
+// move, by absolute path. The content of an open document is used over the
+// file on the disk.
+func (s *Server) noteContents(notebook *core.Notebook, move core.NoteMove) (map[string]string, error) {
+	contents := map[string]string{}
+	for _, edit := range move.Edits {
+		path := filepath.Join(notebook.Path, edit.Path)
+		if _, ok := contents[path]; ok {
+			continue
+		}
+		if doc, ok := s.documents.Get(pathToURI(path)); ok {
+			contents[path] = doc.Content
+			continue
+		}
+		bytes, err := s.fs.Read(path)
+		if err != nil {
+			return contents, err
+		}
+		contents[path] = string(bytes)
+	}
+	return contents, nil
+}
+
+// editsMatch returns whether the text replaced by each edit is found at its
+// offsets in the given note contents.
+func editsMatch(notebook *core.Notebook, edits []core.TextEdit, contents map[string]string) bool {
+	for _, edit := range edits {
+		content := contents[filepath.Join(notebook.Path, edit.Path)]
+		if edit.End > len(content) || content[edit.Start:edit.End] != edit.OldText {
+			return false
+		}
+	}
+	return true
+}
+
+// supportsRenameFile returns whether the client is able to rename files
+// with a workspace edit.
+func supportsRenameFile(capabilities protocol.ClientCapabilities) bool {
+	if capabilities.Workspace == nil || capabilities.Workspace.WorkspaceEdit == nil {
+		return false
+	}
+	workspaceEdit := capabilities.Workspace.WorkspaceEdit
+	if !isTrue(workspaceEdit.DocumentChanges) {
+		return false
+	}
+	for _, kind := range workspaceEdit.ResourceOperations {
+		if kind == protocol.ResourceOperationKindRename {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedURIs(changes map[protocol.DocumentUri][]protocol.TextEdit) []protocol.DocumentUri {
+	uris := []protocol.DocumentUri{}
+	for uri := range changes {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	return uris
+}
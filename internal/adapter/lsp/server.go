@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"path/filepath"
+	"regexp"
 	"strings"
-	"time"
+	"sync"
 
 	"github.com/mickael-menu/zk/internal/core"
 	"github.com/mickael-menu/zk/internal/util"
@@ -28,6 +30,19 @@ type Server struct {
 	documents *documentStore
 	fs        core.FileStorage
 	logger    util.Logger
+
+	// Paths to the root folders of the workspace opened in the editor.
+	workspacePaths map[string]bool
+	workspaceMutex sync.Mutex
+
+	// Whether the notebooks are watched to keep their index up to date.
+	watch bool
+	// Watchers of the notebooks already watched, by notebook path.
+	watchedNotebooks map[string]*notebookWatcher
+	watchMutex       sync.Mutex
+	// Closed when the server shuts down, to stop watching the notebooks.
+	stopWatching chan struct{}
+	stopOnce     sync.Once
 }
 
 // ServerOpts holds the options to create a new Server.
@@ -38,6 +53,9 @@ type ServerOpts struct {
 	Logger    *util.ProxyLogger
 	Notebooks *core.NotebookStore
 	FS        core.FileStorage
+	// Watch keeps the index of the opened notebooks up to date from file
+	// system events.
+	Watch bool
 }
 
 // NewServer creates a new Server instance.
@@ -48,8 +66,8 @@ func NewServer(opts ServerOpts) *Server {
 		logging.Configure(10, opts.LogFile.Value)
 	}
 
-	handler := protocol.Handler{}
-	glspServer := glspserv.NewServer(&handler, opts.Name, debug)
+	handler := &protocolHandler{}
+	glspServer := glspserv.NewServer(handler, opts.Name, debug)
 
 	// Redirect zk's logger to GLSP's to avoid breaking the JSON-RPC protocol
 	// with unwanted output.
@@ -63,6 +81,12 @@ func NewServer(opts ServerOpts) *Server {
 		documents: newDocumentStore(fs, opts.Logger),
 		fs:        fs,
 		logger:    opts.Logger,
+
+		workspacePaths: map[string]bool{},
+
+		watch:            opts.Watch,
+		watchedNotebooks: map[string]*notebookWatcher{},
+		stopWatching:     make(chan struct{}),
 	}
 
 	var clientCapabilities protocol.ClientCapabilities
@@ -70,6 +94,17 @@ func NewServer(opts ServerOpts) *Server {
 	handler.Initialize = func(context *glsp.Context, params *protocol.InitializeParams) (interface{}, error) {
 		clientCapabilities = params.Capabilities
 
+		if len(params.WorkspaceFolders) > 0 {
+			server.addWorkspaceFolders(params.WorkspaceFolders)
+		} else if params.RootURI != nil {
+			rootPath, err := uriToPath(*params.RootURI)
+			if err == nil {
+				server.addWorkspacePaths([]string{rootPath})
+			}
+		} else if params.RootPath != nil {
+			server.addWorkspacePaths([]string{*params.RootPath})
+		}
+
 		// To see the logs with coc.nvim, run :CocCommand workspace.showOutput
 		// https://github.com/neoclide/coc.nvim/wiki/Debug-language-server#using-output-channel
 		if params.Trace != nil {
@@ -97,6 +132,9 @@ func NewServer(opts ServerOpts) *Server {
 			Commands: []string{
 				cmdIndex,
 				cmdNew,
+				cmdJournal,
+				cmdList,
+				cmdBacklinks,
 			},
 		}
 		capabilities.CompletionProvider = &protocol.CompletionOptions{
@@ -105,22 +143,44 @@ func NewServer(opts ServerOpts) *Server {
 		}
 
 		capabilities.ReferencesProvider = &protocol.ReferenceOptions{}
-
-		return protocol.InitializeResult{
-			Capabilities: capabilities,
-			ServerInfo: &protocol.InitializeResultServerInfo{
-				Name:    opts.Name,
-				Version: &opts.Version,
+		capabilities.RenameProvider = true
+		capabilities.DocumentSymbolProvider = true
+		capabilities.WorkspaceSymbolProvider = true
+		capabilities.CodeLensProvider = &protocol.CodeLensOptions{}
+
+		// Rewrite the links when notes are renamed from the editor.
+		fileKind := protocol.FileOperationPatternKindFile
+		noteFilters := &protocol.FileOperationRegistrationOptions{
+			Filters: []protocol.FileOperationFilter{{
+				Scheme:  stringPtr("file"),
+				Pattern: protocol.FileOperationPattern{Glob: "**/*", Matches: &fileKind},
+			}},
+		}
+		capabilities.Workspace = &protocol.ServerCapabilitiesWorkspace{
+			WorkspaceFolders: &protocol.WorkspaceFoldersServerCapabilities{
+				Supported:           boolPtr(true),
+				ChangeNotifications: &protocol.BoolOrString{Value: true},
 			},
-		}, nil
+			FileOperations: &protocol.ServerCapabilitiesWorkspaceFileOperations{
+				WillRename: noteFilters,
+				DidRename:  noteFilters,
+			},
+		}
+
+		return handler.newInitializeResult(capabilities, &protocol.InitializeResultServerInfo{
+			Name:    opts.Name,
+			Version: &opts.Version,
+		})
 	}
 
 	handler.Initialized = func(context *glsp.Context, params *protocol.InitializedParams) error {
+		server.registerFileWatchers(context, clientCapabilities)
 		return nil
 	}
 
 	handler.Shutdown = func(context *glsp.Context) error {
 		protocol.SetTraceValue(protocol.TraceValueOff)
+		server.stopOnce.Do(func() { close(server.stopWatching) })
 		return nil
 	}
 
@@ -168,6 +228,11 @@ func NewServer(opts ServerOpts) *Server {
 			return nil
 		}
 
+		// The watcher already reindexes the saved notes.
+		if server.isWatched(notebook) {
+			return nil
+		}
+
 		_, err = notebook.Index(false)
 		server.logger.Err(err)
 		return nil
@@ -196,6 +261,10 @@ func NewServer(opts ServerOpts) *Server {
 			return server.buildLinkCompletionList(doc, notebook, params)
 		}
 
+		if anchor := doc.AnchorAt(params.Position); anchor != nil {
+			return server.buildAnchorCompletionList(doc, notebook, *anchor, params.Position)
+		}
+
 		switch doc.LookBehind(params.Position, 1) {
 		case "#":
 			if notebook.Config.Format.Markdown.Hashtags {
@@ -319,6 +388,21 @@ func NewServer(opts ServerOpts) *Server {
 			return nil, err
 		}
 
+		// Jump to the heading of the section matching the link anchor, if any.
+		var targetRange protocol.Range
+		if _, anchor := core.SplitHref(link.Href); anchor != "" {
+			section, err := server.findSection(notebook, target, anchor)
+			if err != nil {
+				return nil, err
+			}
+			if section != nil {
+				targetRange, err = server.sectionRange(target, *section)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
 		// FIXME: Waiting for https://github.com/tliron/glsp/pull/3 to be
 		// merged before using LocationLink.
 		if false && isTrue(clientCapabilities.TextDocument.Definition.LinkSupport) {
@@ -328,44 +412,114 @@ func NewServer(opts ServerOpts) *Server {
 			}, nil
 		} else {
 			return protocol.Location{
-				URI: target.URI,
+				URI:   target.URI,
+				Range: targetRange,
 			}, nil
 		}
 	}
 
+	handler.TextDocumentDocumentSymbol = func(context *glsp.Context, params *protocol.DocumentSymbolParams) (interface{}, error) {
+		doc, ok := server.documents.Get(params.TextDocument.URI)
+		if !ok {
+			return nil, nil
+		}
+
+		hierarchical := false
+		if textDocument := clientCapabilities.TextDocument; textDocument != nil && textDocument.DocumentSymbol != nil {
+			hierarchical = isTrue(textDocument.DocumentSymbol.HierarchicalDocumentSymbolSupport)
+		}
+		return server.documentSymbols(doc, hierarchical)
+	}
+
+	handler.WorkspaceSymbol = func(context *glsp.Context, params *protocol.WorkspaceSymbolParams) ([]protocol.SymbolInformation, error) {
+		return server.workspaceSymbols(params.Query)
+	}
+
+	handler.TextDocumentRename = func(context *glsp.Context, params *protocol.RenameParams) (*protocol.WorkspaceEdit, error) {
+		doc, ok := server.documents.Get(params.TextDocument.URI)
+		if !ok {
+			return nil, nil
+		}
+
+		return server.renameLinkTarget(doc, params.Position, params.NewName, clientCapabilities)
+	}
+
+	handler.WorkspaceWillRenameFiles = func(context *glsp.Context, params *protocol.RenameFilesParams) (*protocol.WorkspaceEdit, error) {
+		return server.willRenameFiles(params.Files)
+	}
+
+	handler.WorkspaceDidRenameFiles = func(context *glsp.Context, params *protocol.RenameFilesParams) error {
+		server.didRenameFiles(params.Files)
+		return nil
+	}
+
+	handler.WorkspaceDidChangeWorkspaceFolders = func(context *glsp.Context, params *protocol.DidChangeWorkspaceFoldersParams) error {
+		server.removeWorkspaceFolders(params.Event.Removed)
+		server.addWorkspaceFolders(params.Event.Added)
+		return nil
+	}
+
+	handler.WorkspaceDidChangeWatchedFiles = func(context *glsp.Context, params *protocol.DidChangeWatchedFilesParams) error {
+		server.didChangeWatchedFiles(params.Changes, context.Notify)
+		return nil
+	}
+
 	handler.WorkspaceExecuteCommand = func(context *glsp.Context, params *protocol.ExecuteCommandParams) (interface{}, error) {
 		switch params.Command {
 		case cmdIndex:
 			return server.executeCommandIndex(params.Arguments)
 		case cmdNew:
 			return server.executeCommandNew(context, params.Arguments)
+		case cmdJournal:
+			return server.executeCommandJournal(context, params.Arguments)
+		case cmdList:
+			return server.executeCommandList(params.Arguments)
+		case cmdBacklinks:
+			return server.executeCommandBacklinks(params.Arguments)
 		default:
 			return nil, fmt.Errorf("unknown zk LSP command: %s", params.Command)
 		}
 	}
 
 	handler.TextDocumentCodeAction = func(context *glsp.Context, params *protocol.CodeActionParams) (interface{}, error) {
-		if isRangeEmpty(params.Range) {
-			return nil, nil
-		}
-
 		doc, ok := server.documents.Get(params.TextDocument.URI)
 		if !ok {
 			return nil, nil
 		}
 		wd := filepath.Dir(doc.Path)
 
-		actions := []protocol.CodeAction{}
+		notebook, err := server.notebookOf(doc)
+		if err != nil {
+			// Not part of a notebook.
+			return nil, nil
+		}
+
+		actions, err := server.deadLinkQuickFixes(doc, notebook, params.Context.Diagnostics)
+		if err != nil {
+			return nil, err
+		}
+
+		if isRangeEmpty(params.Range) {
+			return actions, nil
+		}
+
+		selection := doc.ContentAtRange(params.Range)
 
-		addAction := func(dir string, actionTitle string) error {
+		addAction := func(dir string, actionTitle string, kind string, extract bool) error {
 			opts := cmdNewOpts{
-				Title: doc.ContentAtRange(params.Range),
+				Title: selection,
 				Dir:   dir,
 				InsertLinkAtLocation: &protocol.Location{
 					URI:   params.TextDocument.URI,
 					Range: params.Range,
 				},
 			}
+			if extract {
+				opts.Title, opts.Content = splitExtractedNote(selection)
+				if opts.Title == "" {
+					opts.Title = notebook.Config.Note.DefaultTitle
+				}
+			}
 
 			var jsonOpts map[string]interface{}
 			err := unmarshalJSON(opts, &jsonOpts)
@@ -375,7 +529,7 @@ func NewServer(opts ServerOpts) *Server {
 
 			actions = append(actions, protocol.CodeAction{
 				Title: actionTitle,
-				Kind:  stringPtr(protocol.CodeActionKindRefactor),
+				Kind:  stringPtr(kind),
 				Command: &protocol.Command{
 					Command:   cmdNew,
 					Arguments: []interface{}{wd, jsonOpts},
@@ -385,8 +539,10 @@ func NewServer(opts ServerOpts) *Server {
 			return nil
 		}
 
-		addAction(wd, "New note in current directory")
-		addAction("", "New note in top directory")
+		addAction(wd, "New note in current directory", protocol.CodeActionKindRefactor, false)
+		addAction("", "New note in top directory", protocol.CodeActionKindRefactor, false)
+		addAction(wd, "Extract to a new note in current directory", protocol.CodeActionKindRefactorExtract, true)
+		addAction("", "Extract to a new note in top directory", protocol.CodeActionKindRefactorExtract, true)
 
 		return actions, nil
 	}
@@ -398,7 +554,7 @@ func NewServer(opts ServerOpts) *Server {
 		}
 
 		link, err := doc.DocumentLinkAt(params.Position)
-		if link == nil || err != nil {
+		if err != nil {
 			return nil, err
 		}
 
@@ -407,53 +563,40 @@ func NewServer(opts ServerOpts) *Server {
 			return nil, err
 		}
 
-		target, err := server.noteForHref(link.Href, doc, notebook)
-		if link == nil || target == nil || err != nil {
-			return nil, err
+		// Outside of a link, the references are the backlinks of the
+		// document itself, e.g. when activating the backlinks code lens.
+		if link == nil {
+			path, err := notebook.RelPath(doc.Path)
+			if err != nil {
+				return nil, err
+			}
+			return server.findBacklinks(notebook, path)
 		}
 
-		p, err := notebook.RelPath(target.Path)
-		if err != nil {
+		target, err := server.noteForHref(link.Href, doc, notebook)
+		if target == nil || err != nil {
 			return nil, err
 		}
 
-		opts := core.NoteFindOpts{
-			LinkTo: &core.LinkFilter{Paths: []string{p}},
-		}
+		return server.findBacklinks(notebook, target.Path)
+	}
 
-		notes, err := notebook.FindNotes(opts)
-		if err != nil {
-			return nil, err
+	handler.TextDocumentCodeLens = func(context *glsp.Context, params *protocol.CodeLensParams) ([]protocol.CodeLens, error) {
+		doc, ok := server.documents.Get(params.TextDocument.URI)
+		if !ok {
+			return nil, nil
 		}
 
-		var locations []protocol.Location
-
-		for _, note := range notes {
-			pos := strings.Index(note.RawContent, target.Path[0:len(target.Path)-3])
-			var line uint32 = 0
-			if pos < 0 {
-				line = 0
-			} else {
-				linePos := strings.Count(note.RawContent[0:pos], "\n")
-				line = uint32(linePos)
-			}
+		return server.codeLenses(doc)
+	}
 
-			locations = append(locations, protocol.Location{
-				URI: pathToURI(filepath.Join(notebook.Path, note.Path)),
-				Range: protocol.Range{
-					Start: protocol.Position{
-						Line:      line,
-						Character: 0,
-					},
-					End: protocol.Position{
-						Line:      line,
-						Character: 0,
-					},
-				},
-			})
+	handler.TextDocumentInlayHint = func(context *glsp.Context, params *inlayHintParams) ([]inlayHint, error) {
+		doc, ok := server.documents.Get(params.TextDocument.URI)
+		if !ok {
+			return nil, nil
 		}
 
-		return locations, nil
+		return server.inlayHints(doc, params.Range)
 	}
 
 	return server
@@ -466,13 +609,16 @@ func (s *Server) Run() error {
 
 const cmdIndex = "zk.index"
 
+// executeCommandIndex indexes the notebook containing the path given as first
+// argument, or all the notebooks of the workspace without path.
 func (s *Server) executeCommandIndex(args []interface{}) (interface{}, error) {
-	if len(args) == 0 {
-		return nil, fmt.Errorf("zk.index expects a notebook path as first argument")
-	}
-	path, ok := args[0].(string)
-	if !ok {
-		return nil, fmt.Errorf("zk.index expects a notebook path as first argument, got: %v", args[0])
+	path := ""
+	if len(args) > 0 && args[0] != nil {
+		var ok bool
+		path, ok = args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("zk.index expects a notebook path as first argument, got: %v", args[0])
+		}
 	}
 
 	force := false
@@ -486,6 +632,10 @@ func (s *Server) executeCommandIndex(args []interface{}) (interface{}, error) {
 		}
 	}
 
+	if path == "" {
+		return s.indexWorkspace(force), nil
+	}
+
 	notebook, err := s.notebooks.Open(path)
 	if err != nil {
 		return nil, err
@@ -496,11 +646,73 @@ func (s *Server) executeCommandIndex(args []interface{}) (interface{}, error) {
 
 const cmdNew = "zk.new"
 
+const cmdBacklinks = "zk.backlinks"
+
+// cmdShowReferences is a client-side command run by the backlinks code lens,
+// with the URI and position of the note. Clients are expected to show the
+// result of a textDocument/references request at this position.
+const cmdShowReferences = "zk.showReferences"
+
+func (s *Server) executeCommandBacklinks(args []interface{}) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("zk.backlinks expects a note path as first argument")
+	}
+	path, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("zk.backlinks expects a note path as first argument, got: %v", args[0])
+	}
+
+	notebook, err := s.notebooks.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	path, err = notebook.RelPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.findBacklinks(notebook, path)
+}
+
+var extractedHeadingRegex = regexp.MustCompile(`^\s{0,3}#{1,6}\s+(.*?)(?:\s+#+)?\s*$`)
+
+// splitExtractedNote finds the title and content of a new note extracted
+// from the given selection.
+//
+// The title is taken from the first heading, or the first line otherwise.
+// A heading opening the selection is removed from the content, as it is
+// usually rendered by the note template.
+func splitExtractedNote(selection string) (title string, content string) {
+	content = selection
+	lines := strings.Split(selection, "\n")
+
+	opening := true
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if match := extractedHeadingRegex.FindStringSubmatch(line); match != nil {
+			title = match[1]
+			if opening {
+				content = strings.TrimLeft(strings.Join(lines[i+1:], "\n"), "\n")
+			}
+			return
+		}
+		if title == "" {
+			title = strings.TrimSpace(line)
+		}
+		opening = false
+	}
+	return
+}
+
 type cmdNewOpts struct {
 	Title                string             `json:"title,omitempty"`
 	Content              string             `json:"content,omitempty"`
 	Dir                  string             `json:"dir,omitempty"`
+	Filename             string             `json:"filename,omitempty"`
 	Group                string             `json:"group,omitempty"`
+	Parent               string             `json:"parent,omitempty"`
 	Template             string             `json:"template,omitempty"`
 	Extra                map[string]string  `json:"extra,omitempty"`
 	Date                 string             `json:"date,omitempty"`
@@ -543,7 +755,9 @@ func (s *Server) executeCommandNew(context *glsp.Context, args []interface{}) (i
 		Title:     opt.NewNotEmptyString(opts.Title),
 		Content:   opts.Content,
 		Directory: opt.NewNotEmptyString(opts.Dir),
+		Filename:  opt.NewNotEmptyString(opts.Filename),
 		Group:     opt.NewNotEmptyString(opts.Group),
+		Parent:    opt.NewNotEmptyString(opts.Parent),
 		Template:  opt.NewNotEmptyString(opts.Template),
 		Extra:     opts.Extra,
 		Date:      date,
@@ -553,6 +767,8 @@ func (s *Server) executeCommandNew(context *glsp.Context, args []interface{}) (i
 		if !errors.As(err, &noteExists) {
 			return nil, err
 		}
+		// The user can't be asked from the editor, so the existing note is
+		// used with the `ask` conflict policy.
 		path = noteExists.Path
 	}
 
@@ -599,7 +815,83 @@ func (s *Server) executeCommandNew(context *glsp.Context, args []interface{}) (i
 }
 
 func (s *Server) notebookOf(doc *document) (*core.Notebook, error) {
-	return s.notebooks.Open(doc.Path)
+	notebook, err := s.notebooks.Open(doc.Path)
+	if err == nil && s.watch {
+		s.watchNotebook(notebook)
+	}
+	return notebook, err
+}
+
+// notebookWatcher controls the goroutine watching a notebook.
+type notebookWatcher struct {
+	// Closed to stop watching the notebook.
+	stop chan struct{}
+	// Closed once the watcher returned.
+	done chan struct{}
+}
+
+// watchNotebook starts watching the given notebook to keep its index up to
+// date, unless it is already watched.
+func (s *Server) watchNotebook(notebook *core.Notebook) {
+	s.watchMutex.Lock()
+	defer s.watchMutex.Unlock()
+
+	if s.watchedNotebooks[notebook.Path] != nil {
+		return
+	}
+	watcher := &notebookWatcher{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	s.watchedNotebooks[notebook.Path] = watcher
+
+	// Stops either when the notebook is unwatched or the server shuts down.
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-watcher.stop:
+		case <-s.stopWatching:
+		case <-watcher.done:
+		}
+		close(stop)
+	}()
+
+	go func() {
+		defer close(watcher.done)
+		err := notebook.Watch(stop, func(stats core.NoteIndexingStats, err error) {
+			s.logger.Err(err)
+		})
+		s.logger.Err(err)
+
+		// The saved documents are indexed manually when the watcher is not
+		// running anymore, e.g. if it failed to start.
+		s.watchMutex.Lock()
+		if s.watchedNotebooks[notebook.Path] == watcher {
+			delete(s.watchedNotebooks, notebook.Path)
+		}
+		s.watchMutex.Unlock()
+	}()
+}
+
+// isWatched returns whether the given notebook is currently watched.
+func (s *Server) isWatched(notebook *core.Notebook) bool {
+	s.watchMutex.Lock()
+	defer s.watchMutex.Unlock()
+	return s.watchedNotebooks[notebook.Path] != nil
+}
+
+// unwatchNotebook stops watching the notebook at the given path, waiting for
+// the changes being indexed to be saved.
+func (s *Server) unwatchNotebook(path string) {
+	s.watchMutex.Lock()
+	watcher := s.watchedNotebooks[path]
+	delete(s.watchedNotebooks, path)
+	s.watchMutex.Unlock()
+
+	if watcher != nil {
+		close(watcher.stop)
+		<-watcher.done
+	}
 }
 
 // noteForHref returns the LSP documentUri for the note at the given HREF.
@@ -631,80 +923,52 @@ type Note struct {
 	URI protocol.DocumentUri
 }
 
-func (s *Server) refreshDiagnosticsOfDocument(doc *document, notify glsp.NotifyFunc, delay bool) {
-	if doc.NeedsRefreshDiagnostics { // Already refreshing
-		return
+// findSection returns the section of the target note matching the given
+// anchor, or nil if there is none.
+func (s *Server) findSection(notebook *core.Notebook, target *Note, anchor string) (*core.Section, error) {
+	sections, err := notebook.FindSections([]core.NoteID{target.ID})
+	if err != nil {
+		return nil, err
 	}
+	return core.FindSectionByAnchor(sections[target.ID], anchor), nil
+}
 
-	notebook, err := s.notebookOf(doc)
+// hasSection returns whether the target note has a section matching the
+// given anchor. Errors are logged and considered as a match, to avoid
+// reporting false positives.
+func (s *Server) hasSection(notebook *core.Notebook, target *Note, anchor string) bool {
+	section, err := s.findSection(notebook, target, anchor)
 	if err != nil {
 		s.logger.Err(err)
-		return
+		return true
 	}
+	return section != nil
+}
 
-	diagConfig := notebook.Config.LSP.Diagnostics
-	if diagConfig.WikiTitle == core.LSPDiagnosticNone && diagConfig.DeadLink == core.LSPDiagnosticNone {
-		// No diagnostic enabled.
-		return
-	}
-
-	doc.NeedsRefreshDiagnostics = true
-	go func() {
-		if delay {
-			time.Sleep(1 * time.Second)
-		}
-		doc.NeedsRefreshDiagnostics = false
-
-		diagnostics := []protocol.Diagnostic{}
-		links, err := doc.DocumentLinks()
+// sectionRange returns the range of the heading line introducing the given
+// section in the target note.
+func (s *Server) sectionRange(target *Note, section core.Section) (protocol.Range, error) {
+	var content string
+	if doc, ok := s.documents.Get(target.URI); ok {
+		content = doc.Content
+	} else {
+		path, err := uriToPath(target.URI)
 		if err != nil {
-			s.logger.Err(err)
-			return
+			return protocol.Range{}, err
 		}
-
-		for _, link := range links {
-			if strutil.IsURL(link.Href) {
-				continue
-			}
-			target, err := s.noteForHref(link.Href, doc, notebook)
-			if err != nil {
-				s.logger.Err(err)
-				continue
-			}
-
-			var severity protocol.DiagnosticSeverity
-			var message string
-			if target == nil {
-				if diagConfig.DeadLink == core.LSPDiagnosticNone {
-					continue
-				}
-				severity = protocol.DiagnosticSeverity(diagConfig.DeadLink)
-				message = "not found"
-			} else {
-				if link.HasTitle || diagConfig.WikiTitle == core.LSPDiagnosticNone {
-					continue
-				}
-				severity = protocol.DiagnosticSeverity(diagConfig.WikiTitle)
-				message = target.Title
-			}
-
-			diagnostics = append(diagnostics, protocol.Diagnostic{
-				Range:    link.Range,
-				Severity: &severity,
-				Source:   stringPtr("zk"),
-				Message:  message,
-			})
+		bytes, err := ioutil.ReadFile(path)
+		if err != nil {
+			return protocol.Range{}, err
 		}
+		content = string(bytes)
+	}
 
-		go notify(protocol.ServerTextDocumentPublishDiagnostics, protocol.PublishDiagnosticsParams{
-			URI:         doc.URI,
-			Diagnostics: diagnostics,
-		})
-	}()
+	pos := positionAt(content, section.Start)
+	return protocol.Range{Start: pos, End: pos}, nil
 }
 
 func (s *Server) buildTagCompletionList(notebook *core.Notebook, triggerChar string) ([]protocol.CompletionItem, error) {
-	tags, err := notebook.FindCollections(core.CollectionKindTag)
+	tags, err := notebook.FindCollections(core.CollectionKindTag, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -737,6 +1001,46 @@ func (s *Server) buildInsertForTag(name string, triggerChar string, config core.
 	return &name
 }
 
+// buildAnchorCompletionList lists the headings of the note targeted by a
+// link, to complete its #anchor.
+func (s *Server) buildAnchorCompletionList(doc *document, notebook *core.Notebook, anchor documentAnchor, pos protocol.Position) ([]protocol.CompletionItem, error) {
+	target, err := s.noteForHref(anchor.Href, doc, notebook)
+	if target == nil || err != nil {
+		return nil, err
+	}
+
+	sections, err := notebook.FindSections([]core.NoteID{target.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	kind := protocol.CompletionItemKindReference
+	var items []protocol.CompletionItem
+	for _, section := range sections[target.ID] {
+		items = append(items, protocol.CompletionItem{
+			Label:      section.Title,
+			Kind:       &kind,
+			Detail:     stringPtr(strings.Repeat("#", section.Level) + " " + section.Title),
+			FilterText: stringPtr(section.Title + " " + section.Slug),
+			TextEdit: protocol.TextEdit{
+				NewText: formatAnchor(section.Slug, anchor.IsWikiLink, notebook.Config),
+				Range:   rangeFromPosition(pos, -len(anchor.Partial), 0),
+			},
+		})
+	}
+
+	return items, nil
+}
+
+// formatAnchor encodes a section slug to be inserted in a link, according to
+// the notebook configuration.
+func formatAnchor(slug string, isWikiLink bool, config core.Config) string {
+	if !isWikiLink && config.Format.Markdown.LinkEncodePath {
+		return url.PathEscape(slug)
+	}
+	return slug
+}
+
 func (s *Server) buildLinkCompletionList(doc *document, notebook *core.Notebook, params *protocol.CompletionParams) ([]protocol.CompletionItem, error) {
 	linkFormatter, err := newLinkFormatter(doc, notebook, params)
 	if err != nil {
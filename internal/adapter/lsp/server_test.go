@@ -0,0 +1,28 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+)
+
+func TestSplitExtractedNote(t *testing.T) {
+	test := func(selection string, expectedTitle string, expectedContent string) {
+		title, content := splitExtractedNote(selection)
+		assert.Equal(t, title, expectedTitle)
+		assert.Equal(t, content, expectedContent)
+	}
+
+	// Heading first
+	test("# A title\n\nSome content\n", "A title", "Some content\n")
+	test("## A title ##\nSome content", "A title", "Some content")
+	// Leading blank lines
+	test("\n  \n# A title\n\nSome content", "A title", "Some content")
+	// Heading after a paragraph, kept in the content
+	test("Some content\n\n# A heading\nMore", "A heading", "Some content\n\n# A heading\nMore")
+	// No heading
+	test("  First line  \nSecond line", "First line", "  First line  \nSecond line")
+	// Empty selection
+	test("", "", "")
+	test(" \n\n", "", " \n\n")
+}
@@ -0,0 +1,246 @@
+package lsp
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/mickael-menu/zk/internal/core"
+	"github.com/mickael-menu/zk/internal/util/opt"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// documentSymbols returns the outline of the headings of the given document.
+// The symbols are nested by heading level when the client supports
+// hierarchical document symbols.
+func (s *Server) documentSymbols(doc *document, hierarchical bool) (interface{}, error) {
+	notebook, err := s.notebookOf(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the editor buffer, which might differ from the indexed note.
+	sections, err := notebook.ParseSections(doc.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	if hierarchical {
+		symbols, _ := sectionSymbols(doc.Content, sections, 0)
+		return symbols, nil
+	}
+
+	symbols := []protocol.SymbolInformation{}
+	parents := []core.Section{}
+	for _, section := range sections {
+		for len(parents) > 0 && parents[len(parents)-1].Level >= section.Level {
+			parents = parents[:len(parents)-1]
+		}
+
+		var container *string
+		if len(parents) > 0 {
+			container = stringPtr(parents[len(parents)-1].Title)
+		}
+		symbols = append(symbols, protocol.SymbolInformation{
+			Name: section.Title,
+			Kind: protocol.SymbolKindString,
+			Location: protocol.Location{
+				URI:   doc.URI,
+				Range: sectionSymbolRange(doc.Content, section),
+			},
+			ContainerName: container,
+		})
+		parents = append(parents, section)
+	}
+	return symbols, nil
+}
+
+// sectionSymbols builds the tree of symbols of the given sections, until
+// reaching a section of a level lower or equal to `minLevel`. The remaining
+// sections are returned.
+func sectionSymbols(content string, sections []core.Section, minLevel int) ([]protocol.DocumentSymbol, []core.Section) {
+	symbols := []protocol.DocumentSymbol{}
+
+	for len(sections) > 0 && sections[0].Level > minLevel {
+		section := sections[0]
+		var children []protocol.DocumentSymbol
+		children, sections = sectionSymbols(content, sections[1:], section.Level)
+
+		symbols = append(symbols, protocol.DocumentSymbol{
+			Name:           section.Title,
+			Kind:           protocol.SymbolKindString,
+			Range:          sectionSymbolRange(content, section),
+			SelectionRange: headingRange(content, section),
+			Children:       children,
+		})
+	}
+
+	return symbols, sections
+}
+
+// workspaceSymbolsLimit is the maximum number of symbols returned when
+// searching the workspace.
+const workspaceSymbolsLimit = 100
+
+// workspaceSymbols searches the titles, aliases and headings of the notes in
+// the notebooks of the workspace.
+//
+// An empty query lists the first notes, as clients send one when opening
+// their symbol picker.
+func (s *Server) workspaceSymbols(query string) ([]protocol.SymbolInformation, error) {
+	symbols := []protocol.SymbolInformation{}
+	query = strings.TrimSpace(query)
+
+	// The index filters the notes case-insensitively, but the names still
+	// need to be checked as a note can have several aliases and headings.
+	lowerQuery := strings.ToLower(query)
+	matches := func(name string) bool {
+		return strings.Contains(strings.ToLower(name), lowerQuery)
+	}
+
+	for _, notebook := range s.workspaceNotebooks() {
+		if len(symbols) >= workspaceSymbolsLimit {
+			break
+		}
+
+		opts := core.NoteFindOpts{Limit: workspaceSymbolsLimit}
+		if query != "" {
+			opts.TitleMatch = opt.NewString(query)
+		}
+		notes, err := notebook.FindMinimalNotes(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, note := range notes {
+			noteLocation := protocol.Location{
+				URI: pathToURI(filepath.Join(notebook.Path, note.Path)),
+			}
+
+			name := note.Title
+			if name == "" {
+				name = note.Path
+			}
+			if matches(name) {
+				symbols = append(symbols, protocol.SymbolInformation{
+					Name:     name,
+					Kind:     protocol.SymbolKindFile,
+					Location: noteLocation,
+				})
+			}
+
+			if query == "" {
+				continue
+			}
+			for _, alias := range noteAliases(note.Metadata) {
+				if matches(alias) {
+					symbols = append(symbols, protocol.SymbolInformation{
+						Name:          alias,
+						Kind:          protocol.SymbolKindFile,
+						Location:      noteLocation,
+						ContainerName: stringPtr(note.Title),
+					})
+				}
+			}
+		}
+
+		if query == "" {
+			continue
+		}
+		headingNotes, err := notebook.FindMinimalNotes(core.NoteFindOpts{
+			HeadingMatch: opt.NewString(query),
+			Limit:        workspaceSymbolsLimit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		ids := []core.NoteID{}
+		for _, note := range headingNotes {
+			ids = append(ids, note.ID)
+		}
+		sections, err := notebook.FindSections(ids)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, note := range headingNotes {
+			path := filepath.Join(notebook.Path, note.Path)
+
+			// The content is only read when a heading matches, to locate it.
+			var content *string
+			for _, section := range sections[note.ID] {
+				if !matches(section.Title) {
+					continue
+				}
+				if content == nil {
+					bytes, err := s.fs.Read(path)
+					if err != nil {
+						s.logger.Err(err)
+						break
+					}
+					content = stringPtr(string(bytes))
+				}
+
+				symbols = append(symbols, protocol.SymbolInformation{
+					Name: section.Title,
+					Kind: protocol.SymbolKindString,
+					Location: protocol.Location{
+						URI:   pathToURI(path),
+						Range: sectionSymbolRange(*content, section),
+					},
+					ContainerName: stringPtr(note.Title),
+				})
+			}
+		}
+	}
+
+	if len(symbols) > workspaceSymbolsLimit {
+		symbols = symbols[:workspaceSymbolsLimit]
+	}
+	return symbols, nil
+}
+
+// noteAliases returns the aliases declared in the YAML frontmatter of a
+// note, e.g.
+//
+//	aliases: [Alias 1, Alias 2]
+func noteAliases(metadata map[string]interface{}) []string {
+	aliases := []string{}
+	switch value := metadata["aliases"].(type) {
+	case string:
+		aliases = append(aliases, value)
+	case []interface{}:
+		for _, alias := range value {
+			if alias, ok := alias.(string); ok {
+				aliases = append(aliases, alias)
+			}
+		}
+	}
+	return aliases
+}
+
+// sectionSymbolRange returns the range of a whole section.
+func sectionSymbolRange(content string, section core.Section) protocol.Range {
+	return protocol.Range{
+		Start: positionAt(content, section.Start),
+		End:   positionAt(content, section.End),
+	}
+}
+
+// headingRange returns the range of the heading line introducing a section.
+func headingRange(content string, section core.Section) protocol.Range {
+	start := section.Start
+	if start > len(content) {
+		start = len(content)
+	}
+	end := start
+	if i := strings.Index(content[start:], "\n"); i >= 0 {
+		end += i
+	} else {
+		end = len(content)
+	}
+	return protocol.Range{
+		Start: positionAt(content, start),
+		End:   positionAt(content, end),
+	}
+}
@@ -3,8 +3,10 @@ package lsp
 import (
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/mickael-menu/zk/internal/util/errors"
+	protocol "github.com/tliron/glsp/protocol_3_16"
 )
 
 func pathToURI(path string) string {
@@ -26,6 +28,31 @@ func uriToPath(uri string) (string, error) {
 	return parsed.Path, nil
 }
 
+// positionAt converts a byte offset in the given content into a LSP
+// position, whose character is counted in UTF-16 code units.
+func positionAt(content string, offset int) protocol.Position {
+	if offset > len(content) {
+		offset = len(content)
+	}
+	before := content[:offset]
+	line := strings.Count(before, "\n")
+	lineStart := strings.LastIndex(before, "\n") + 1
+
+	character := 0
+	for _, r := range before[lineStart:] {
+		if r >= 0x10000 {
+			character += 2
+		} else {
+			character++
+		}
+	}
+
+	return protocol.Position{
+		Line:      uint32(line),
+		Character: uint32(character),
+	}
+}
+
 // jsonBoolean can be unmarshalled from integers or strings.
 // Neovim cannot send a boolean easily, so it's useful to support integers too.
 type jsonBoolean bool
@@ -0,0 +1,148 @@
+package lsp
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mickael-menu/zk/internal/core"
+	strutil "github.com/mickael-menu/zk/internal/util/strings"
+	"github.com/tliron/glsp"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// registerFileWatchers asks the client to notify the server of the notes
+// changed outside the editor, e.g. by the CLI or a git pull.
+func (s *Server) registerFileWatchers(context *glsp.Context, capabilities protocol.ClientCapabilities) {
+	if capabilities.Workspace == nil || capabilities.Workspace.DidChangeWatchedFiles == nil ||
+		!isTrue(capabilities.Workspace.DidChangeWatchedFiles.DynamicRegistration) {
+		return
+	}
+
+	// Reload the configuration of the notebooks when it changes.
+	watchers := []protocol.FileSystemWatcher{
+		{GlobPattern: "**/.zk/config.toml"},
+	}
+	for _, ext := range s.noteExtensions() {
+		watchers = append(watchers, protocol.FileSystemWatcher{
+			GlobPattern: fmt.Sprintf("**/*.%s", ext),
+		})
+	}
+
+	go context.Call(protocol.ServerClientRegisterCapability, protocol.RegistrationParams{
+		Registrations: []protocol.Registration{{
+			ID:     "zk-watched-files",
+			Method: string(protocol.MethodWorkspaceDidChangeWatchedFiles),
+			RegisterOptions: protocol.DidChangeWatchedFilesRegistrationOptions{
+				Watchers: watchers,
+			},
+		}},
+	}, nil)
+}
+
+// noteExtensions returns the file extensions of the notes of the notebooks
+// in the workspace.
+func (s *Server) noteExtensions() []string {
+	exts := []string{"md"}
+	for _, notebook := range s.workspaceNotebooks() {
+		exts = append(exts, notebook.Config.Note.Extension)
+		for _, group := range notebook.Config.Groups {
+			if group.Note.Extension != "" {
+				exts = append(exts, group.Note.Extension)
+			}
+		}
+	}
+	exts = strutil.RemoveDuplicates(exts)
+	sort.Strings(exts)
+	return exts
+}
+
+// didChangeWatchedFiles reindexes the notes changed outside the editor, then
+// refreshes the diagnostics of the open documents which might be affected.
+func (s *Server) didChangeWatchedFiles(changes []protocol.FileEvent, notify glsp.NotifyFunc) {
+	notebooks := map[string]*core.Notebook{}
+	changedPaths := map[string][]string{}
+
+	for _, change := range changes {
+		path, err := uriToPath(change.URI)
+		if err != nil {
+			s.logger.Err(err)
+			continue
+		}
+		if isNotebookConfig(path) {
+			s.reloadNotebookConfig(path, notify)
+			continue
+		}
+		notebook, err := s.notebooks.Open(path)
+		if err != nil {
+			// Not in a notebook.
+			continue
+		}
+		notebooks[notebook.Path] = notebook
+		changedPaths[notebook.Path] = append(changedPaths[notebook.Path], path)
+	}
+
+	for notebookPath, paths := range changedPaths {
+		notebook := notebooks[notebookPath]
+		watched := s.isWatched(notebook)
+
+		// The watcher already reindexes the changed notes.
+		if !watched {
+			relPaths := []string{}
+			for _, path := range paths {
+				relPath, err := notebook.RelPath(path)
+				if err != nil {
+					s.logger.Err(err)
+					continue
+				}
+				relPaths = append(relPaths, relPath)
+			}
+			_, err := notebook.IndexPaths(relPaths)
+			if err != nil {
+				s.logger.Err(err)
+				continue
+			}
+		}
+
+		// Any note might link to an open document and change its orphan
+		// status.
+		checkOrphans := notebook.Config.LSP.Diagnostics.Orphan != core.LSPDiagnosticNone
+		for _, doc := range s.documents.All() {
+			if !strings.HasPrefix(doc.Path, notebookPath+string(filepath.Separator)) {
+				continue
+			}
+			if checkOrphans || linksToAny(doc, paths) {
+				// Leave some time to the watcher to reindex the notes.
+				s.refreshDiagnosticsOfDocument(doc, notify, watched)
+			}
+		}
+	}
+}
+
+// linksToAny returns whether the given document links to one of the notes
+// at the given absolute paths.
+func linksToAny(doc *document, paths []string) bool {
+	links, err := doc.DocumentLinks()
+	if err != nil {
+		return false
+	}
+
+	for _, link := range links {
+		if strutil.IsURL(link.Href) {
+			continue
+		}
+		href, _ := core.SplitHref(link.Href)
+		if href == "" {
+			continue
+		}
+		// Hrefs may omit the extension or be a prefix of the path, e.g. an ID.
+		target := filepath.Clean(filepath.Join(filepath.Dir(doc.Path), href))
+		for _, path := range paths {
+			if strings.HasPrefix(path, target) {
+				return true
+			}
+		}
+	}
+	return false
+}
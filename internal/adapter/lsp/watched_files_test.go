@@ -0,0 +1,29 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+)
+
+func TestLinksToAny(t *testing.T) {
+	test := func(content string, paths []string, expected bool) {
+		doc := &document{Path: "/notebook/dir/a.md", Content: content}
+		assert.Equal(t, linksToAny(doc, paths), expected)
+	}
+
+	paths := []string{"/notebook/dir/note.md", "/notebook/other/a3f9-title.md"}
+
+	test("", paths, false)
+	test("No links here", paths, false)
+	test("[Note](note.md)", paths, true)
+	test("[Note](note.md#section)", paths, true)
+	test("[Note](note)", paths, true)
+	test("[Note](../dir/note.md)", paths, true)
+	test("[[../other/a3f9]]", paths, true)
+	test("[Other](../other/a3f9-title.md)", paths, true)
+	test("[Other](other.md)", paths, false)
+	test("[Anchor](#section)", paths, false)
+	test("[URL](https://example.com/dir/note.md)", paths, false)
+	test("[Note](note.md)", []string{}, false)
+}
@@ -0,0 +1,163 @@
+package lsp
+
+import (
+	"path/filepath"
+
+	"github.com/mickael-menu/zk/internal/core"
+	"github.com/tliron/glsp"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// addWorkspaceFolders adds the given folders to the workspace and indexes
+// their notebooks in the background.
+func (s *Server) addWorkspaceFolders(folders []protocol.WorkspaceFolder) {
+	paths := []string{}
+	for _, folder := range folders {
+		path, err := uriToPath(folder.URI)
+		if err != nil {
+			s.logger.Err(err)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	s.addWorkspacePaths(paths)
+}
+
+// addWorkspacePaths adds the given root paths to the workspace and indexes
+// their notebooks in the background.
+func (s *Server) addWorkspacePaths(paths []string) {
+	s.workspaceMutex.Lock()
+	for _, path := range paths {
+		s.workspacePaths[path] = true
+	}
+	s.workspaceMutex.Unlock()
+
+	go func() {
+		for _, path := range paths {
+			notebook, err := s.notebooks.Open(path)
+			if err != nil {
+				// The folder is not a notebook.
+				continue
+			}
+			_, err = notebook.Index(false)
+			s.logger.Err(err)
+		}
+	}()
+}
+
+// removeWorkspaceFolders removes the given folders from the workspace, and
+// stops watching their notebooks unless they are still part of it.
+func (s *Server) removeWorkspaceFolders(folders []protocol.WorkspaceFolder) {
+	removed := []*core.Notebook{}
+
+	s.workspaceMutex.Lock()
+	for _, folder := range folders {
+		path, err := uriToPath(folder.URI)
+		if err != nil {
+			s.logger.Err(err)
+			continue
+		}
+		delete(s.workspacePaths, path)
+
+		if notebook, err := s.notebooks.Open(path); err == nil {
+			removed = append(removed, notebook)
+		}
+	}
+	s.workspaceMutex.Unlock()
+
+	remaining := map[string]bool{}
+	for _, notebook := range s.workspaceNotebooks() {
+		remaining[notebook.Path] = true
+	}
+	for _, notebook := range removed {
+		if !remaining[notebook.Path] {
+			s.unwatchNotebook(notebook.Path)
+		}
+	}
+}
+
+// workspaceNotebooks returns the notebooks found in the workspace folders and
+// the ones containing the opened documents.
+func (s *Server) workspaceNotebooks() []*core.Notebook {
+	notebooks := []*core.Notebook{}
+	found := map[string]bool{}
+	add := func(path string) {
+		notebook, err := s.notebooks.Open(path)
+		if err != nil || found[notebook.Path] {
+			return
+		}
+		found[notebook.Path] = true
+		notebooks = append(notebooks, notebook)
+	}
+
+	s.workspaceMutex.Lock()
+	paths := []string{}
+	for path := range s.workspacePaths {
+		paths = append(paths, path)
+	}
+	s.workspaceMutex.Unlock()
+
+	for _, path := range paths {
+		add(path)
+	}
+	for _, doc := range s.documents.All() {
+		add(doc.Path)
+	}
+
+	return notebooks
+}
+
+// indexWorkspace indexes each notebook of the workspace independently,
+// returning the statistics by notebook path. A notebook failing to be
+// indexed doesn't prevent indexing the other ones.
+func (s *Server) indexWorkspace(force bool) map[string]core.NoteIndexingStats {
+	stats := map[string]core.NoteIndexingStats{}
+	for _, notebook := range s.workspaceNotebooks() {
+		notebookStats, err := notebook.Index(force)
+		if err != nil {
+			s.logger.Err(err)
+			continue
+		}
+		stats[notebook.Path] = notebookStats
+	}
+	return stats
+}
+
+// isNotebookConfig returns whether the given path is the configuration file
+// of a notebook.
+func isNotebookConfig(path string) bool {
+	return filepath.Base(path) == "config.toml" && filepath.Base(filepath.Dir(path)) == ".zk"
+}
+
+// reloadNotebookConfig reloads the notebook of the given configuration file
+// after it changed, then refreshes the diagnostics of its open documents.
+func (s *Server) reloadNotebookConfig(configPath string, notify glsp.NotifyFunc) {
+	previous, err := s.notebooks.Open(configPath)
+	if err != nil {
+		s.logger.Err(err)
+		return
+	}
+	// The watcher uses the configuration of the previous instance, so it is
+	// restarted with the new one.
+	watched := s.isWatched(previous)
+	s.unwatchNotebook(previous.Path)
+
+	notebook, err := s.notebooks.Reload(configPath)
+	s.logger.Err(err)
+	if notebook == nil {
+		// The previous instance is kept when the configuration is invalid.
+		notebook = previous
+	}
+	if watched {
+		s.watchNotebook(notebook)
+	}
+	if notebook == previous {
+		return
+	}
+
+	for _, doc := range s.documents.All() {
+		if docNotebook, err := s.notebooks.Open(doc.Path); err == nil && docNotebook == notebook {
+			s.refreshDiagnosticsOfDocument(doc, notify, false)
+		}
+	}
+}
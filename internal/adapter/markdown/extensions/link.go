@@ -0,0 +1,121 @@
+package extensions
+
+import (
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Attributes holding the byte offsets of a link destination in the source.
+// They are set only when the destination is written inline in the link,
+// e.g. not for reference links.
+var (
+	HrefStartAttr = []byte("zk-href-start")
+	HrefEndAttr   = []byte("zk-href-end")
+)
+
+// HrefRange returns the byte offsets of the destination of the given link in
+// the source, if known.
+func HrefRange(link *ast.Link) (start int, end int, ok bool) {
+	startAttr, ok := link.Attribute(HrefStartAttr)
+	if !ok {
+		return 0, 0, false
+	}
+	endAttr, ok := link.Attribute(HrefEndAttr)
+	if !ok {
+		return 0, 0, false
+	}
+	return startAttr.(int), endAttr.(int), true
+}
+
+func setHrefRange(link *ast.Link, start int, end int) {
+	link.SetAttribute(HrefStartAttr, start)
+	link.SetAttribute(HrefEndAttr, end)
+}
+
+// InlineParsers returns the default inline parsers of goldmark, with the
+// link parser replaced by one recording the range of the link destinations.
+func InlineParsers() []util.PrioritizedValue {
+	parsers := parser.DefaultInlineParsers()
+	for i, p := range parsers {
+		if p.Value == parser.NewLinkParser() {
+			parsers[i] = util.Prioritized(&linkParser{parser.NewLinkParser()}, p.Priority)
+		}
+	}
+	return parsers
+}
+
+// linkParser wraps the goldmark link parser, which doesn't keep the position
+// of the link destinations in the AST.
+type linkParser struct {
+	parser.InlineParser
+}
+
+func (p *linkParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	node := p.InlineParser.Parse(parent, block, pc)
+	link, ok := node.(*ast.Link)
+	if !ok || len(line) == 0 || line[0] != ']' {
+		return node
+	}
+
+	// The closing bracket of an inline link is directly followed by its
+	// destination: ](destination "title")
+	source := block.Source()
+	closer := segment.Start
+	if closer+1 >= len(source) || source[closer+1] != '(' {
+		return node
+	}
+
+	// Look for the closing parenthesis backward, as the reader might have
+	// moved to the next line.
+	_, pos := block.Position()
+	end := pos.Start
+	for end > closer+2 && source[end-1] != ')' {
+		end--
+	}
+	end-- // Skips the closing parenthesis.
+
+	start := closer + 2
+	for start < end && util.IsSpace(source[start]) {
+		start++
+	}
+
+	if start < end && source[start] == '<' {
+		// <destination with spaces>
+		start++
+		stop := start
+		for stop < end && source[stop] != '>' {
+			if source[stop] == '\\' {
+				stop++
+			}
+			stop++
+		}
+		if stop < end {
+			setHrefRange(link, start, stop)
+		}
+		return node
+	}
+
+	stop := start
+	for stop < end && !util.IsSpace(source[stop]) {
+		if source[stop] == '\\' {
+			stop++
+		}
+		stop++
+	}
+	if stop > end {
+		stop = end
+	}
+	if start < stop {
+		setHrefRange(link, start, stop)
+	}
+	return node
+}
+
+func (p *linkParser) CloseBlock(parent ast.Node, block text.Reader, pc parser.Context) {
+	if closer, ok := p.InlineParser.(parser.CloseBlocker); ok {
+		closer.CloseBlock(parent, block, pc)
+	}
+}
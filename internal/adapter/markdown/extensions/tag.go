@@ -3,6 +3,7 @@ package extensions
 import (
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
@@ -17,6 +18,12 @@ type Tags struct {
 	gast.BaseInline
 	// Tags in this list.
 	Tags []string
+	// Start byte offset of the tags in the source, including the # or :
+	// delimiters.
+	Start int
+	// End byte offset of the tags in the source, including the # or :
+	// delimiters.
+	End int
 }
 
 func (n *Tags) Dump(source []byte, level int) {
@@ -79,7 +86,7 @@ func (p *hashtagParser) Trigger() []byte {
 
 func (p *hashtagParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
 	previousChar := block.PrecendingCharacter()
-	line, _ := block.PeekLine()
+	line, segment := block.PeekLine()
 
 	// A hashtag can't be directly preceded by a # or any other valid character.
 	if isValidTagChar(previousChar, '\x00') {
@@ -94,8 +101,8 @@ func (p *hashtagParser) Parse(parent ast.Node, block text.Reader, pc parser.Cont
 	var (
 		escaping            = false // Found a backslash, next character will be literal
 		parsingMultiWordTag = false // Finished parsing a hashtag, now attempt parsing a Bear multi-word tag
-		endPos              = 0     // Last position of the tag in the line
-		multiWordTagEndPos  = 0     // Last position of the multi-word tag in the line
+		charEnd             = 0     // End offset in the line of the current character
+		tagEnd              = 0     // End offset in the line of the tag
 	)
 
 	appendChar := func(c rune) {
@@ -103,15 +110,12 @@ func (p *hashtagParser) Parse(parent ast.Node, block text.Reader, pc parser.Cont
 			multiWordTagCandidate += string(c)
 		} else {
 			tag += string(c)
+			tagEnd = charEnd
 		}
 	}
 
 	for i, char := range string(line[1:]) {
-		if parsingMultiWordTag {
-			multiWordTagEndPos = i
-		} else {
-			endPos = i
-		}
+		charEnd = 1 + i + utf8.RuneLen(char)
 
 		if escaping {
 			// Currently escaping? The character will be appended literally.
@@ -130,7 +134,7 @@ func (p *hashtagParser) Parse(parent ast.Node, block text.Reader, pc parser.Cont
 				// A valid multi-word tag must not have a space before the closing #.
 				if !unicode.IsSpace(previousChar) {
 					tag = multiWordTagCandidate
-					endPos = multiWordTagEndPos
+					tagEnd = charEnd
 				}
 				break
 			}
@@ -161,11 +165,13 @@ func (p *hashtagParser) Parse(parent ast.Node, block text.Reader, pc parser.Cont
 		return nil
 	}
 
-	block.Advance(endPos)
+	block.Advance(tagEnd)
 
 	return &Tags{
 		BaseInline: gast.BaseInline{},
 		Tags:       []string{tag},
+		Start:      segment.Start,
+		End:        segment.Start + tagEnd,
 	}
 }
 
@@ -187,7 +193,7 @@ func (p *colontagParser) Trigger() []byte {
 
 func (p *colontagParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
 	previousChar := block.PrecendingCharacter()
-	line, _ := block.PeekLine()
+	line, segment := block.PeekLine()
 
 	// A colontag can't be directly preceded by a : or any other valid character.
 	if isValidTagChar(previousChar, '\x00') {
@@ -202,6 +208,7 @@ func (p *colontagParser) Parse(parent ast.Node, block text.Reader, pc parser.Con
 	var (
 		escaping = false // Found a backslash, next character will be literal
 		endPos   = 0     // Last position of the colontags in the line
+		tagsEnd  = 0     // End offset in the line of the last closing colon
 	)
 
 	appendChar := func(c rune) {
@@ -227,6 +234,7 @@ func (p *colontagParser) Parse(parent ast.Node, block text.Reader, pc parser.Con
 			}
 			tags = append(tags, tag)
 			tag = ""
+			tagsEnd = 1 + i + 1
 
 		} else if !isValidTagChar(char, ':') {
 			// Found an invalid character, the colontag is complete.
@@ -246,6 +254,8 @@ func (p *colontagParser) Parse(parent ast.Node, block text.Reader, pc parser.Con
 	return &Tags{
 		BaseInline: gast.BaseInline{},
 		Tags:       tags,
+		Start:      segment.Start,
+		End:        segment.Start + tagsEnd,
 	}
 }
 
@@ -256,3 +266,38 @@ func isValidTagChar(r rune, excluded rune) bool {
 		r == '&' || r == '+' || r == '=' || r == ':' ||
 		r == '#')
 }
+
+// FormatHashtag returns the given tag formatted as a #hashtag, escaping any
+// invalid character. Bear's #multi-word tags# are used when the tag contains
+// whitespaces and multiWordTagEnabled is true.
+func FormatHashtag(tag string, multiWordTagEnabled bool) string {
+	multiWord := multiWordTagEnabled && strings.IndexFunc(tag, unicode.IsSpace) >= 0
+
+	res := "#"
+	for _, char := range tag {
+		if !isValidTagChar(char, '#') && !(multiWord && char == ' ') {
+			res += "\\"
+		}
+		res += string(char)
+	}
+	if multiWord {
+		res += "#"
+	}
+	return res
+}
+
+// FormatColontags returns the given tags formatted as :colon:tags:, escaping
+// any invalid character.
+func FormatColontags(tags []string) string {
+	res := ":"
+	for _, tag := range tags {
+		for _, char := range tag {
+			if !isValidTagChar(char, ':') {
+				res += "\\"
+			}
+			res += string(char)
+		}
+		res += ":"
+	}
+	return res
+}
@@ -2,6 +2,7 @@ package extensions
 
 import (
 	"strings"
+	"unicode"
 
 	"github.com/mickael-menu/zk/internal/core"
 	"github.com/yuin/goldmark"
@@ -18,6 +19,14 @@ var WikiLinkExt = &wikiLink{}
 
 type wikiLink struct{}
 
+var wikiLinkAttr = []byte("zk-wiki-link")
+
+// IsWikiLink returns whether the given link was parsed from a [[wiki link]].
+func IsWikiLink(link *ast.Link) bool {
+	_, ok := link.Attribute(wikiLinkAttr)
+	return ok
+}
+
 func (w *wikiLink) Extend(m goldmark.Markdown) {
 	m.Parser().AddOptions(
 		parser.WithInlineParsers(
@@ -33,7 +42,7 @@ func (p *wlParser) Trigger() []byte {
 }
 
 func (p *wlParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
-	line, _ := block.PeekLine()
+	line, segment := block.PeekLine()
 
 	var (
 		href  string
@@ -49,6 +58,9 @@ func (p *wlParser) Parse(parent ast.Node, block text.Reader, pc parser.Context)
 		openerCharCount = 0     // Number of [ encountered
 		closerCharCount = 0     // Number of ] encountered
 		endPos          = 0     // Last position of the link in the line
+		hrefStart       = -1    // Start position of the href in the line
+		hrefEnd         = -1    // End position of the href in the line
+		closerPos       = 0     // Position of the first ] of the closing brackets
 	)
 
 	appendRune := func(c rune) {
@@ -86,11 +98,17 @@ func (p *wlParser) Parse(parent ast.Node, block text.Reader, pc parser.Context)
 			}
 		}
 		opened = true
+		if hrefStart < 0 {
+			hrefStart = i
+		}
 
 		if !escaping {
 			switch char {
 
 			case '|': // [[href | label]]
+				if !parsingLabel {
+					hrefEnd = i
+				}
 				parsingLabel = true
 				continue
 
@@ -99,9 +117,15 @@ func (p *wlParser) Parse(parent ast.Node, block text.Reader, pc parser.Context)
 				continue
 
 			case ']':
+				if closerCharCount == 0 {
+					closerPos = i
+				}
 				closerCharCount += 1
 				if closerCharCount == openerCharCount {
 					closed = true
+					if hrefEnd < 0 {
+						hrefEnd = closerPos
+					}
 					// Neuron's legacy [[[Folgezettel]]].
 					if closerCharCount == 3 {
 						rel = core.LinkRelationDown
@@ -141,5 +165,12 @@ func (p *wlParser) Parse(parent ast.Node, block text.Reader, pc parser.Context)
 	link.Title = []byte(rel)
 	link.AppendChild(link, ast.NewString([]byte(label)))
 
+	// Excludes the whitespaces around the href from its range.
+	rawHref := string(line[hrefStart:hrefEnd])
+	hrefStart += len(rawHref) - len(strings.TrimLeftFunc(rawHref, unicode.IsSpace))
+	hrefEnd -= len(rawHref) - len(strings.TrimRightFunc(rawHref, unicode.IsSpace))
+	setHrefRange(link, segment.Start+hrefStart, segment.Start+hrefEnd)
+	link.SetAttribute(wikiLinkAttr, true)
+
 	return link
 }
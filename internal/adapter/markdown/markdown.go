@@ -8,6 +8,7 @@ import (
 
 	"github.com/mickael-menu/zk/internal/adapter/markdown/extensions"
 	"github.com/mickael-menu/zk/internal/core"
+	"github.com/mickael-menu/zk/internal/util/errors"
 	"github.com/mickael-menu/zk/internal/util/opt"
 	strutil "github.com/mickael-menu/zk/internal/util/strings"
 	"github.com/mickael-menu/zk/internal/util/yaml"
@@ -22,7 +23,8 @@ import (
 
 // Parser parses the content of Markdown notes.
 type Parser struct {
-	md goldmark.Markdown
+	md   goldmark.Markdown
+	opts ParserOpts
 }
 
 type ParserOpts struct {
@@ -38,6 +40,11 @@ type ParserOpts struct {
 func NewParser(options ParserOpts) *Parser {
 	return &Parser{
 		md: goldmark.New(
+			goldmark.WithParser(parser.NewParser(
+				parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+				parser.WithInlineParsers(extensions.InlineParsers()...),
+				parser.WithParagraphTransformers(parser.DefaultParagraphTransformers()...),
+			)),
 			goldmark.WithExtensions(
 				meta.Meta,
 				extension.NewLinkify(
@@ -57,6 +64,7 @@ func NewParser(options ParserOpts) *Parser {
 				},
 			),
 		),
+		opts: options,
 	}
 }
 
@@ -91,20 +99,27 @@ func (p *Parser) Parse(content string) (*core.ParsedNote, error) {
 		return nil, err
 	}
 
+	sections, err := parseSections(root, bytes)
+	if err != nil {
+		return nil, err
+	}
+
 	return &core.ParsedNote{
 		Title:    title,
 		Body:     body,
 		Lead:     parseLead(body),
 		Links:    links,
 		Tags:     tags,
+		Sections: sections,
 		Metadata: frontmatter.values,
 	}, nil
 }
 
 // parseTitle extracts the note title with its node.
 func parseTitle(frontmatter frontmatter, root ast.Node, source []byte) (title opt.String, bodyStart int, err error) {
+	// Without title, the body starts after the frontmatter.
+	bodyStart = frontmatter.end
 	if title = frontmatter.getString("title", "Title"); !title.IsNull() {
-		bodyStart = frontmatter.end
 		return
 	}
 
@@ -135,6 +150,48 @@ func parseTitle(frontmatter frontmatter, root ast.Node, source []byte) (title op
 	return
 }
 
+// parseSections extracts the sections introduced by the headings of the note.
+func parseSections(root ast.Node, source []byte) ([]core.Section, error) {
+	sections := make([]core.Section, 0)
+
+	err := ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+
+		lines := heading.Lines()
+		if lines.Len() == 0 {
+			return ast.WalkSkipChildren, nil
+		}
+		// The heading segments exclude the leading # markers.
+		start := lines.At(0).Start
+		for start > 0 && source[start-1] != '\n' {
+			start--
+		}
+
+		sections = append(sections, core.Section{
+			Level: heading.Level,
+			Title: string(heading.Text(source)),
+			Start: start,
+		})
+		return ast.WalkSkipChildren, nil
+	})
+
+	// A section ends before the next heading of the same or a higher level.
+	for i := range sections {
+		sections[i].End = len(source)
+		for _, next := range sections[i+1:] {
+			if next.Level <= sections[i].Level {
+				sections[i].End = next.Start
+				break
+			}
+		}
+	}
+
+	return sections, err
+}
+
 // parseBody extracts the whole content after the title.
 func parseBody(startIndex int, source []byte) opt.String {
 	return opt.NewNotEmptyString(
@@ -218,6 +275,7 @@ func parseLinks(root ast.Node, source []byte) ([]core.Link, error) {
 				href := string(link.Destination)
 				if href != "" {
 					snippet, snStart, snEnd := extractLines(n, source)
+					hrefStart, hrefEnd, _ := extensions.HrefRange(link)
 					links = append(links, core.Link{
 						Title:        string(link.Text(source)),
 						Href:         href,
@@ -226,6 +284,9 @@ func parseLinks(root ast.Node, source []byte) ([]core.Link, error) {
 						Snippet:      snippet,
 						SnippetStart: snStart,
 						SnippetEnd:   snEnd,
+						HrefStart:    hrefStart,
+						HrefEnd:      hrefEnd,
+						IsWikiLink:   extensions.IsWikiLink(link),
 					})
 				}
 
@@ -293,7 +354,7 @@ func parseFrontmatter(context parser.Context, source []byte) (frontmatter, error
 
 	values, err := meta.TryGet(context)
 	if err != nil {
-		return front, err
+		return front, errors.Wrap(err, "invalid YAML frontmatter")
 	}
 
 	// The YAML parser parses nested maps as map[interface{}]interface{}
@@ -83,6 +83,12 @@ Paragraph:
 title: A title
 ---
 
+Paragraph
+`, "Paragraph")
+	test(`---
+author: Mickaël
+---
+
 Paragraph
 `, "Paragraph")
 }
@@ -166,6 +172,8 @@ func TestParseHashtags(t *testing.T) {
 	test("#", []string{})
 	test("##", []string{})
 	test("# No tags around here", []string{})
+	test("#a", []string{"a"})
+	test("#a #b", []string{"a", "b"})
 	test("#single-hashtag", []string{"single-hashtag"})
 	test("a #tag in the middle", []string{"tag"})
 	test("#multiple #hashtags", []string{"multiple", "hashtags"})
@@ -386,6 +394,8 @@ Neuron links with titles: [[trailing|Trailing link]]# #[[leading |  Leading link
 			Snippet:      "Heading with a [link](heading)",
 			SnippetStart: 3,
 			SnippetEnd:   33,
+			HrefStart:    25,
+			HrefEnd:      32,
 		},
 		{
 			Title:      "multiple links",
@@ -396,6 +406,8 @@ Neuron links with titles: [[trailing|Trailing link]]# #[[leading |  Leading link
 A link can have [one relation](one "rel-1") or [several relations](several "rel-1 rel-2").`,
 			SnippetStart: 35,
 			SnippetEnd:   222,
+			HrefStart:    77,
+			HrefEnd:      96,
 		},
 		{
 			Title:      "relative",
@@ -406,6 +418,8 @@ A link can have [one relation](one "rel-1") or [several relations](several "rel-
 A link can have [one relation](one "rel-1") or [several relations](several "rel-1 rel-2").`,
 			SnippetStart: 35,
 			SnippetEnd:   222,
+			HrefStart:    121,
+			HrefEnd:      129,
 		},
 		{
 			Title:      "one relation",
@@ -416,6 +430,8 @@ A link can have [one relation](one "rel-1") or [several relations](several "rel-
 A link can have [one relation](one "rel-1") or [several relations](several "rel-1 rel-2").`,
 			SnippetStart: 35,
 			SnippetEnd:   222,
+			HrefStart:    163,
+			HrefEnd:      166,
 		},
 		{
 			Title:      "several relations",
@@ -426,6 +442,8 @@ A link can have [one relation](one "rel-1") or [several relations](several "rel-
 A link can have [one relation](one "rel-1") or [several relations](several "rel-1 rel-2").`,
 			SnippetStart: 35,
 			SnippetEnd:   222,
+			HrefStart:    199,
+			HrefEnd:      206,
 		},
 		{
 			Title:        "https://inline-link.com",
@@ -453,6 +471,9 @@ A link can have [one relation](one "rel-1") or [several relations](several "rel-
 			Snippet:      "A [[Wiki link]] is surrounded by [[2-brackets | two brackets]].",
 			SnippetStart: 288,
 			SnippetEnd:   351,
+			HrefStart:    292,
+			HrefEnd:      301,
+			IsWikiLink:   true,
 		},
 		{
 			Title:        "two brackets",
@@ -462,6 +483,9 @@ A link can have [one relation](one "rel-1") or [several relations](several "rel-
 			Snippet:      "A [[Wiki link]] is surrounded by [[2-brackets | two brackets]].",
 			SnippetStart: 288,
 			SnippetEnd:   351,
+			HrefStart:    323,
+			HrefEnd:      333,
+			IsWikiLink:   true,
 		},
 		{
 			Title:        "lien accentué",
@@ -471,6 +495,9 @@ A link can have [one relation](one "rel-1") or [several relations](several "rel-
 			Snippet:      "[[lien accentué]]",
 			SnippetStart: 353,
 			SnippetEnd:   371,
+			HrefStart:    355,
+			HrefEnd:      369,
+			IsWikiLink:   true,
 		},
 		{
 			Title:        `esca]]ped [chara\cters`,
@@ -480,6 +507,9 @@ A link can have [one relation](one "rel-1") or [several relations](several "rel-
 			Snippet:      `It can contain [[esca]\]ped \[chara\\cters]].`,
 			SnippetStart: 373,
 			SnippetEnd:   418,
+			HrefStart:    390,
+			HrefEnd:      415,
+			IsWikiLink:   true,
 		},
 		{
 			Title:        "Folgezettel link",
@@ -489,6 +519,9 @@ A link can have [one relation](one "rel-1") or [several relations](several "rel-
 			Snippet:      "A [[[Folgezettel link]]] is surrounded by three brackets.",
 			SnippetStart: 420,
 			SnippetEnd:   477,
+			HrefStart:    425,
+			HrefEnd:      441,
+			IsWikiLink:   true,
 		},
 		{
 			Title:        "trailing hash",
@@ -498,6 +531,9 @@ A link can have [one relation](one "rel-1") or [several relations](several "rel-
 			Snippet:      "Neuron also supports a [[trailing hash]]# for Folgezettel links.",
 			SnippetStart: 479,
 			SnippetEnd:   543,
+			HrefStart:    504,
+			HrefEnd:      517,
+			IsWikiLink:   true,
 		},
 		{
 			Title:        "leading hash",
@@ -507,6 +543,9 @@ A link can have [one relation](one "rel-1") or [several relations](several "rel-
 			Snippet:      "A #[[leading hash]] is used for #uplinks.",
 			SnippetStart: 545,
 			SnippetEnd:   586,
+			HrefStart:    550,
+			HrefEnd:      562,
+			IsWikiLink:   true,
 		},
 		{
 			Title:        "Trailing link",
@@ -516,6 +555,9 @@ A link can have [one relation](one "rel-1") or [several relations](several "rel-
 			Snippet:      "Neuron links with titles: [[trailing|Trailing link]]# #[[leading |  Leading link]]",
 			SnippetStart: 588,
 			SnippetEnd:   670,
+			HrefStart:    616,
+			HrefEnd:      624,
+			IsWikiLink:   true,
 		},
 		{
 			Title:        "Leading link",
@@ -525,6 +567,9 @@ A link can have [one relation](one "rel-1") or [several relations](several "rel-
 			Snippet:      "Neuron links with titles: [[trailing|Trailing link]]# #[[leading |  Leading link]]",
 			SnippetStart: 588,
 			SnippetEnd:   670,
+			HrefStart:    645,
+			HrefEnd:      652,
+			IsWikiLink:   true,
 		},
 		{
 			Title:        "External links",
@@ -534,6 +579,8 @@ A link can have [one relation](one "rel-1") or [several relations](several "rel-
 			Snippet:      `[External links](http://example.com) are marked [as such](ftp://domain).`,
 			SnippetStart: 672,
 			SnippetEnd:   744,
+			HrefStart:    689,
+			HrefEnd:      707,
 		},
 		{
 			Title:        "as such",
@@ -543,10 +590,57 @@ A link can have [one relation](one "rel-1") or [several relations](several "rel-
 			Snippet:      `[External links](http://example.com) are marked [as such](ftp://domain).`,
 			SnippetStart: 672,
 			SnippetEnd:   744,
+			HrefStart:    730,
+			HrefEnd:      742,
 		},
 	})
 }
 
+func TestParseSections(t *testing.T) {
+	test := func(source string, expectedSections []core.Section) {
+		content := parse(t, source)
+		assert.Equal(t, content.Sections, expectedSections)
+	}
+
+	test("", []core.Section{})
+	test("Paragraph without heading", []core.Section{})
+
+	test(`# Title
+
+Intro
+
+## Section 1
+Body
+
+### Sub-section
+Text
+
+## Section 2
+End
+`, []core.Section{
+		{Level: 1, Title: "Title", Start: 0, End: 74},
+		{Level: 2, Title: "Section 1", Start: 16, End: 57},
+		{Level: 3, Title: "Sub-section", Start: 35, End: 57},
+		{Level: 2, Title: "Section 2", Start: 57, End: 74},
+	})
+
+	// Formatting is stripped from the section titles.
+	test("## A **bold** [heading](http://stripped)", []core.Section{
+		{Level: 2, Title: "A bold heading", Start: 0, End: 40},
+	})
+
+	// Offsets are relative to the whole content, including the frontmatter.
+	test(`---
+title: Note
+---
+
+# Heading
+Content
+`, []core.Section{
+		{Level: 1, Title: "Heading", Start: 21, End: 39},
+	})
+}
+
 func TestParseMetadataFromFrontmatter(t *testing.T) {
 	test := func(source string, expectedMetadata map[string]interface{}) {
 		content := parse(t, source)
@@ -588,3 +682,27 @@ func parseWithOptions(t *testing.T, source string, options ParserOpts) core.Pars
 	assert.Nil(t, err)
 	return *content
 }
+
+func TestParseLinkHrefRanges(t *testing.T) {
+	test := func(source string, hrefs []string) {
+		content := parse(t, source)
+		actual := []string{}
+		for _, link := range content.Links {
+			actual = append(actual, source[link.HrefStart:link.HrefEnd])
+		}
+		assert.Equal(t, actual, hrefs)
+	}
+
+	// Single-letter and empty labels.
+	test("[x](a.md) and [](b.md)", []string{"a.md", "b.md"})
+	// Titles, angle brackets and nested parentheses.
+	test(`[a](a.md "title") [b](<dir/b c.md>) [c](c(1).md) [d]( d.md )`, []string{"a.md", "dir/b c.md", "c(1).md", "d.md"})
+	// Escaped and percent-encoded hrefs are kept as written.
+	test(`[a](a\)b.md) [b](my%20note.md#anchor)`, []string{`a\)b.md`, "my%20note.md#anchor"})
+	// Wiki links, without the surrounding whitespaces.
+	test("[[a]] [[[b]]] #[[ c ]] [[d | label]] [[esca]\\]ped]]", []string{"a", "b", "c", "d", `esca]\]ped`})
+	// Links in code are ignored.
+	test("`[a](a.md)` and `[[b]]`\n\n```\n[c](c.md) [[d]]\n```\n\n    [e](e.md)\n", []string{})
+	// Reference links don't have a range.
+	test("[a][ref]\n\n[ref]: a.md\n", []string{""})
+}
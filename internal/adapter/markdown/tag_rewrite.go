@@ -0,0 +1,332 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mickael-menu/zk/internal/adapter/markdown/extensions"
+	"github.com/mickael-menu/zk/internal/core"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// RewriteTags implements core.TagRewriter.
+//
+// Tags are rewritten in the YAML frontmatter (tags and keywords keys), as well
+// as inline #hashtags, #multi-word tags# and :colon:tags:, according to the
+// parser options.
+func (p *Parser) RewriteTags(content string, mapping map[string]string) (string, error) {
+	source := []byte(content)
+	root := p.md.Parser().Parse(
+		text.NewReader(source),
+		parser.WithContext(parser.NewContext()),
+	)
+
+	edits := rewriteFrontmatterTags(content, mapping)
+
+	// Each #hashtag is a separate node, so the inline tags which are not
+	// renamed are collected first to prevent duplicates when merging tags.
+	seen := map[string]bool{}
+	err := walkInlineTags(root, func(tagsNode *extensions.Tags) {
+		for _, tag := range tagsNode.Tags {
+			if _, ok := mapping[tag]; !ok {
+				seen[tag] = true
+			}
+		}
+	})
+	if err != nil {
+		return content, err
+	}
+
+	err = walkInlineTags(root, func(tagsNode *extensions.Tags) {
+		tags, changed := mapTags(tagsNode.Tags, mapping, seen)
+		if !changed {
+			return
+		}
+
+		newText := ""
+		if len(tags) > 0 {
+			if source[tagsNode.Start] == ':' {
+				newText = extensions.FormatColontags(tags)
+			} else {
+				newText = extensions.FormatHashtag(tags[0], p.opts.MultiWordTagEnabled)
+			}
+		}
+
+		edit := core.TextEdit{
+			Start:   tagsNode.Start,
+			End:     tagsNode.End,
+			NewText: newText,
+		}
+		if newText == "" {
+			edit = removingAdjacentSpace(content, edit)
+		}
+		edit.OldText = content[edit.Start:edit.End]
+		edits = append(edits, edit)
+	})
+
+	return core.ApplyTextEdits(content, edits), err
+}
+
+// walkInlineTags calls the visitor with each non-empty inline tags node of
+// the given Markdown tree, in the order of the document.
+func walkInlineTags(root ast.Node, visitor func(tagsNode *extensions.Tags)) error {
+	return ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		tagsNode, ok := n.(*extensions.Tags)
+		if ok && entering && tagsNode.End > tagsNode.Start {
+			visitor(tagsNode)
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// mapTags renames or removes the given tags according to the mapping, while
+// preventing duplicates. seen holds the tags found elsewhere in the note: a
+// renamed tag is removed when its new name is already in it. Returns whether
+// any tag was changed.
+func mapTags(tags []string, mapping map[string]string, seen map[string]bool) ([]string, bool) {
+	res := []string{}
+	changed := false
+
+	for _, tag := range tags {
+		newTag, renamed := mapping[tag]
+		if renamed {
+			changed = true
+			tag = newTag
+		}
+		if tag == "" {
+			continue
+		}
+		if containsString(res, tag) || (renamed && seen[tag]) {
+			changed = true
+			continue
+		}
+		res = append(res, tag)
+		seen[tag] = true
+	}
+
+	return res, changed
+}
+
+// removingAdjacentSpace extends the range of a deletion to a surrounding
+// space, to avoid leaving double spaces behind.
+func removingAdjacentSpace(content string, edit core.TextEdit) core.TextEdit {
+	if edit.Start > 0 && content[edit.Start-1] == ' ' {
+		edit.Start--
+	} else if edit.End < len(content) && content[edit.End] == ' ' {
+		edit.End++
+	}
+	return edit
+}
+
+var frontmatterTagKeyRegex = regexp.MustCompile(`(?i)^(?:tags?|keywords?)[ \t]*:[ \t]*(.*?)\s*$`)
+var frontmatterListItemRegex = regexp.MustCompile(`^\s*-[ \t]+(.*?)\s*$`)
+
+// rewriteFrontmatterTags returns the edits needed to rename or remove tags
+// listed in the YAML frontmatter, either as a block list, a flow list or a
+// space-separated string.
+func rewriteFrontmatterTags(content string, mapping map[string]string) []core.TextEdit {
+	edits := []core.TextEdit{}
+
+	// The frontmatter must be at the top of the note.
+	index := frontmatterRegex.FindStringIndex(content)
+	if index == nil || index[0] != 0 {
+		return edits
+	}
+
+	lines := splitLinesWithOffsets(content[index[0]:index[1]], index[0])
+	for i, line := range lines {
+		match := frontmatterTagKeyRegex.FindStringSubmatchIndex(line.text)
+		if match == nil {
+			continue
+		}
+		value := yamlValue{
+			text:  line.text[match[2]:match[3]],
+			start: line.start + match[2],
+		}
+
+		switch {
+		case value.text == "":
+			// Block list:
+			// tags:
+			//   - tag1
+			//   - tag2
+			items := []yamlValue{}
+			itemLines := []textLine{}
+			for _, itemLine := range lines[i+1:] {
+				itemMatch := frontmatterListItemRegex.FindStringSubmatchIndex(itemLine.text)
+				if itemMatch == nil {
+					break
+				}
+				items = append(items, yamlValue{
+					text:  itemLine.text[itemMatch[2]:itemMatch[3]],
+					start: itemLine.start + itemMatch[2],
+				})
+				itemLines = append(itemLines, itemLine)
+			}
+
+			names := []string{}
+			for j, item := range items {
+				newText, keep := item.mapTag(mapping, &names)
+				if !keep {
+					// Remove the whole line, including its line break.
+					edits = append(edits, core.TextEdit{
+						Start:   itemLines[j].start,
+						End:     itemLines[j].end + 1,
+						OldText: itemLines[j].text + "\n",
+					})
+				} else if newText != item.text {
+					edits = append(edits, core.TextEdit{
+						Start:   item.start,
+						End:     item.end(),
+						OldText: item.text,
+						NewText: newText,
+					})
+				}
+			}
+
+		case strings.HasPrefix(value.text, "[") && strings.HasSuffix(value.text, "]"):
+			// Flow list: tags: [tag1, tag2]
+			names := []string{}
+			newItems := []string{}
+			for _, item := range strings.Split(value.text[1:len(value.text)-1], ",") {
+				item = strings.TrimSpace(item)
+				if item == "" {
+					continue
+				}
+				newText, keep := yamlValue{text: item}.mapTag(mapping, &names)
+				if keep {
+					newItems = append(newItems, newText)
+				}
+			}
+			if newText := "[" + strings.Join(newItems, ", ") + "]"; newText != value.text {
+				edits = append(edits, core.TextEdit{
+					Start:   value.start,
+					End:     value.end(),
+					OldText: value.text,
+					NewText: newText,
+				})
+			}
+
+		default:
+			// Space-separated string: tags: tag1 tag2
+			quote := ""
+			str := value.text
+			if len(str) >= 2 && (str[0] == '"' || str[0] == '\'') && str[len(str)-1] == str[0] {
+				quote = str[:1]
+				str = str[1 : len(str)-1]
+			}
+			names := []string{}
+			newItems := []string{}
+			for _, item := range strings.Fields(str) {
+				prefix := ""
+				if strings.HasPrefix(item, "#") {
+					prefix = "#"
+					item = item[1:]
+				}
+				if newTag, ok := mapping[item]; ok {
+					item = newTag
+				}
+				if item != "" && !containsString(names, item) {
+					names = append(names, item)
+					newItems = append(newItems, prefix+item)
+				}
+			}
+			if newText := quote + strings.Join(newItems, " ") + quote; newText != value.text {
+				edits = append(edits, core.TextEdit{
+					Start:   value.start,
+					End:     value.end(),
+					OldText: value.text,
+					NewText: newText,
+				})
+			}
+		}
+	}
+
+	return edits
+}
+
+// yamlValue is a raw YAML scalar found in the frontmatter.
+type yamlValue struct {
+	text  string
+	start int
+}
+
+func (v yamlValue) end() int {
+	return v.start + len(v.text)
+}
+
+// mapTag renames the tag held by this YAML value, keeping its original
+// quotes and # prefix. names holds the tags already found in the list, to
+// prevent duplicates.
+//
+// Returns false if the tag must be removed.
+func (v yamlValue) mapTag(mapping map[string]string, names *[]string) (string, bool) {
+	quote := ""
+	str := v.text
+	if len(str) >= 2 && (str[0] == '"' || str[0] == '\'') && str[len(str)-1] == str[0] {
+		quote = str[:1]
+		str = str[1 : len(str)-1]
+	}
+	prefix := ""
+	if strings.HasPrefix(str, "#") {
+		prefix = "#"
+		str = str[1:]
+	}
+
+	newText := v.text
+	if newTag, ok := mapping[str]; ok {
+		str = newTag
+		if quote == "" && !yamlPlainTagRegex.MatchString(prefix+str) {
+			quote = `"`
+		}
+		if quote == `"` {
+			newText = quote + prefix + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(str) + quote
+		} else {
+			newText = quote + prefix + strings.ReplaceAll(str, "'", "''") + quote
+		}
+	}
+
+	if str == "" || containsString(*names, str) {
+		return "", false
+	}
+	*names = append(*names, str)
+	return newText, true
+}
+
+// yamlPlainTagRegex matches the tags which can be written in YAML without
+// quotes.
+var yamlPlainTagRegex = regexp.MustCompile(`^[\p{L}\p{N}_/~+=$.][\p{L}\p{N}_/~+=$.@'&%-]*$`)
+
+// textLine is a line of text with its position in the source.
+type textLine struct {
+	text string
+	// Start byte offset of the line in the source.
+	start int
+	// End byte offset of the line in the source, excluding the line break.
+	end int
+}
+
+func splitLinesWithOffsets(str string, offset int) []textLine {
+	lines := []textLine{}
+	for _, line := range strings.SplitAfter(str, "\n") {
+		text := strings.TrimSuffix(line, "\n")
+		lines = append(lines, textLine{
+			text:  text,
+			start: offset,
+			end:   offset + len(text),
+		})
+		offset += len(line)
+	}
+	return lines
+}
+
+func containsString(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/mickael-menu/zk/internal/core"
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+)
+
+func TestRewriteInlineTags(t *testing.T) {
+	test := func(source string, mapping map[string]string, expected string) {
+		assert.Equal(t, rewriteTags(t, source, mapping, true), expected)
+	}
+
+	rename := map[string]string{"old": "new"}
+	test("", rename, "")
+	test("A #old tag", rename, "A #new tag")
+	test("#old, #other", rename, "#new, #other")
+	test("#older #old", rename, "#older #new")
+	test("Colon :a:old:b: tags", rename, "Colon :a:new:b: tags")
+	test("In `#old` code", rename, "In `#old` code")
+	test("#multi word# end", map[string]string{"multi word": "single"}, "#single end")
+	test("A #old tag", map[string]string{"old": "new tag"}, "A #new tag# tag")
+	test(":old:", map[string]string{"old": "new tag"}, `:new\ tag:`)
+
+	// Merging tags
+	test(":a:b:", map[string]string{"a": "b"}, ":b:")
+	test("#a #b", map[string]string{"a": "b"}, "#b")
+	test("#b and #a", map[string]string{"a": "b"}, "#b and")
+	test("#a #c", map[string]string{"a": "b", "c": "b"}, "#b")
+	// Existing duplicates are not touched.
+	test("#b #b #a", map[string]string{"a": "c"}, "#b #b #c")
+
+	// Deleting tags
+	remove := map[string]string{"old": ""}
+	test("A #old tag", remove, "A tag")
+	test("#old tag", remove, "tag")
+	test("Text :a:old: more", remove, "Text :a: more")
+	test("Text :old: more", remove, "Text more")
+}
+
+func TestRewriteInlineTagsWithoutMultiWordTags(t *testing.T) {
+	assert.Equal(t,
+		rewriteTags(t, "A #old tag", map[string]string{"old": "new tag"}, false),
+		`A #new\ tag tag`,
+	)
+}
+
+func TestRewriteTagsInFrontmatter(t *testing.T) {
+	test := func(source string, mapping map[string]string, expected string) {
+		assert.Equal(t, rewriteTags(t, source, mapping, true), expected)
+	}
+
+	test(`---
+title: A note
+tags:
+  - old
+  - "#other"
+  - misc
+---
+Body #old
+`, map[string]string{"old": "new", "other": "new-other"}, `---
+title: A note
+tags:
+  - new
+  - "#new-other"
+  - misc
+---
+Body #new
+`)
+
+	test(`---
+tags: [old, 'other', "#old"]
+keywords: old other
+---
+`, map[string]string{"old": "new tag"}, `---
+tags: ["new tag", 'other']
+keywords: new tag other
+---
+`)
+
+	// Merging and deleting
+	test(`---
+Tags:
+  - a
+  - b
+  - c
+keyword: a b
+---
+`, map[string]string{"a": "b", "c": ""}, `---
+Tags:
+  - b
+keyword: b
+---
+`)
+
+	// The frontmatter must be at the top of the note.
+	test(`# Title
+---
+tags: [old]
+---
+`, map[string]string{"old": "new"}, `# Title
+---
+tags: [old]
+---
+`)
+}
+
+func TestRewriteFrontmatterTagsEdits(t *testing.T) {
+	content := "---\ntags: [old]\n---\n"
+	assert.Equal(t, rewriteFrontmatterTags(content, map[string]string{"old": "new"}), []core.TextEdit{
+		{Start: 10, End: 15, OldText: "[old]", NewText: "[new]"},
+	})
+}
+
+func rewriteTags(t *testing.T, source string, mapping map[string]string, multiWordTagEnabled bool) string {
+	parser := NewParser(ParserOpts{
+		HashtagEnabled:      true,
+		MultiWordTagEnabled: multiWordTagEnabled,
+		ColontagEnabled:     true,
+	})
+	res, err := parser.RewriteTags(source, mapping)
+	assert.Nil(t, err)
+	return res
+}
@@ -198,10 +198,40 @@ func (db *DB) migrate() error {
 					key TEXT PRIMARY KEY NOT NULL,
 					value TEXT NO NULL
 				)`,
+
+				// Sections
+				`CREATE TABLE IF NOT EXISTS sections (
+					id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+					note_id INTEGER NOT NULL REFERENCES notes(id)
+						ON DELETE CASCADE,
+					level INTEGER NOT NULL,
+					title TEXT DEFAULT('') NOT NULL,
+					slug TEXT DEFAULT('') NOT NULL,
+					start INTEGER NOT NULL,
+					end INTEGER NOT NULL
+				)`,
+				`CREATE INDEX IF NOT EXISTS index_sections_note_id ON sections (note_id)`,
+
+				`PRAGMA user_version = 4`,
+			})
+			if err != nil {
+				return err
+			}
+
+			needsReindexing = true
+		}
+
+		if version <= 4 {
+			err = tx.ExecStmts([]string{
+				// The indexed body of the notes without title doesn't include
+				// their YAML frontmatter anymore.
+				`PRAGMA user_version = 5`,
 			})
 			if err != nil {
 				return err
 			}
+
+			needsReindexing = true
 		}
 
 		if needsReindexing {
@@ -8,6 +8,7 @@ import (
 
 // Known metadata keys.
 var reindexingRequiredKey = "zk.reindexing_required"
+var counterKeyPrefix = "zk.counter."
 
 // MetadataDAO persists arbitrary key/value pairs in the SQLite database.
 type MetadataDAO struct {
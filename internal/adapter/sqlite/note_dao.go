@@ -26,15 +26,20 @@ type NoteDAO struct {
 
 	// Prepared SQL statements
 	indexedStmt            *LazyStmt
+	checksumsStmt          *LazyStmt
 	addStmt                *LazyStmt
 	updateStmt             *LazyStmt
 	removeStmt             *LazyStmt
+	moveStmt               *LazyStmt
 	findIdByPathStmt       *LazyStmt
 	findIdByPathPrefixStmt *LazyStmt
 	findByIdStmt           *LazyStmt
 	addLinkStmt            *LazyStmt
 	setLinksTargetStmt     *LazyStmt
+	unsetLinksTargetStmt   *LazyStmt
 	removeLinksStmt        *LazyStmt
+	addSectionStmt         *LazyStmt
+	removeSectionsStmt     *LazyStmt
 }
 
 // NewNoteDAO creates a new instance of a DAO working on the given database
@@ -50,6 +55,12 @@ func NewNoteDAO(tx Transaction, logger util.Logger) *NoteDAO {
 			 ORDER BY sortable_path ASC
 		`),
 
+		// Get the checksums of all indexed notes.
+		checksumsStmt: tx.PrepareLazy(`
+			SELECT path, checksum from notes
+			 WHERE checksum IS NOT NULL AND checksum <> ''
+		`),
+
 		// Add a new note to the index.
 		addStmt: tx.PrepareLazy(`
 			INSERT INTO notes (path, sortable_path, title, lead, body, raw_content, word_count, metadata, checksum, created, modified)
@@ -69,6 +80,13 @@ func NewNoteDAO(tx Transaction, logger util.Logger) *NoteDAO {
 			 WHERE id = ?
 		`),
 
+		// Change the path of a note.
+		moveStmt: tx.PrepareLazy(`
+			UPDATE notes
+			   SET path = ?, sortable_path = ?
+			 WHERE id = ?
+		`),
+
 		// Find a note ID from its exact path.
 		findIdByPathStmt: tx.PrepareLazy(`
 			SELECT id FROM notes
@@ -103,11 +121,31 @@ func NewNoteDAO(tx Transaction, logger util.Logger) *NoteDAO {
 			 WHERE target_id IS NULL AND external = 0 AND ? LIKE href || '%'
 		`),
 
+		// Unset the target ID of the links pointing to the given note, whose
+		// href doesn't match the given path anymore.
+		unsetLinksTargetStmt: tx.PrepareLazy(`
+			UPDATE links
+			   SET target_id = NULL
+			 WHERE target_id = ? AND ? NOT LIKE href || '%'
+		`),
+
 		// Remove all the outbound links of a note.
 		removeLinksStmt: tx.PrepareLazy(`
 			DELETE FROM links
 			 WHERE source_id = ?
 		`),
+
+		// Add a new section.
+		addSectionStmt: tx.PrepareLazy(`
+			INSERT INTO sections (note_id, level, title, slug, start, end)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`),
+
+		// Remove all the sections of a note.
+		removeSectionsStmt: tx.PrepareLazy(`
+			DELETE FROM sections
+			 WHERE note_id = ?
+		`),
 	}
 }
 
@@ -148,19 +186,32 @@ func (d *NoteDAO) Indexed() (<-chan paths.Metadata, error) {
 	return c, nil
 }
 
+// Checksums returns the content checksums of all indexed notes, by path.
+func (d *NoteDAO) Checksums() (map[string]string, error) {
+	rows, err := d.checksumsStmt.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checksums := map[string]string{}
+	var path, checksum string
+	for rows.Next() {
+		err := rows.Scan(&path, &checksum)
+		if err != nil {
+			return nil, err
+		}
+		checksums[path] = checksum
+	}
+
+	return checksums, rows.Err()
+}
+
 // Add inserts a new note to the index.
 func (d *NoteDAO) Add(note core.Note) (core.NoteID, error) {
-	// For sortable_path, we replace in path / by the shortest non printable
-	// character available to make it sortable. Without this, sorting by the
-	// path would be a lexicographical sort instead of being the same order
-	// returned by filepath.Walk.
-	// \x01 is used instead of \x00, because SQLite treats \x00 as and end of
-	// string.
-	sortablePath := strings.ReplaceAll(note.Path, "/", "\x01")
-
 	metadata := d.metadataToJSON(note)
 	res, err := d.addStmt.Exec(
-		note.Path, sortablePath, note.Title, note.Lead, note.Body,
+		note.Path, sortablePath(note.Path), note.Title, note.Lead, note.Body,
 		note.RawContent, note.WordCount, metadata, note.Checksum, note.Created,
 		note.Modified,
 	)
@@ -175,9 +226,24 @@ func (d *NoteDAO) Add(note core.Note) (core.NoteID, error) {
 
 	id := core.NoteID(lastId)
 	err = d.addLinks(id, note)
+	if err != nil {
+		return id, err
+	}
+
+	err = d.addSections(id, note)
 	return id, err
 }
 
+// sortablePath replaces in path / by the shortest non printable character
+// available to make it sortable. Without this, sorting by the path would be a
+// lexicographical sort instead of being the same order returned by
+// filepath.Walk.
+// \x01 is used instead of \x00, because SQLite treats \x00 as and end of
+// string.
+func sortablePath(path string) string {
+	return strings.ReplaceAll(path, "/", "\x01")
+}
+
 // Update modifies an existing note.
 func (d *NoteDAO) Update(note core.Note) (core.NoteID, error) {
 	id, err := d.findIdByPath(note.Path)
@@ -203,6 +269,16 @@ func (d *NoteDAO) Update(note core.Note) (core.NoteID, error) {
 	}
 
 	err = d.addLinks(id, note)
+	if err != nil {
+		return id, err
+	}
+
+	_, err = d.removeSectionsStmt.Exec(d.idToSql(id))
+	if err != nil {
+		return id, err
+	}
+
+	err = d.addSections(id, note)
 	return id, err
 }
 
@@ -249,6 +325,28 @@ func joinLinkRels(rels []core.LinkRelation) string {
 	return res
 }
 
+// splitLinkRels is the inverse of joinLinkRels.
+func splitLinkRels(rels string) []core.LinkRelation {
+	res := []core.LinkRelation{}
+	for _, rel := range strings.Split(rels, "\x01") {
+		if rel != "" {
+			res = append(res, core.LinkRelation(rel))
+		}
+	}
+	return res
+}
+
+// addSections inserts all the sections of the given note.
+func (d *NoteDAO) addSections(id core.NoteID, note core.Note) error {
+	for _, section := range note.Sections {
+		_, err := d.addSectionStmt.Exec(id, section.Level, section.Title, section.Slug, section.Start, section.End)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Remove deletes the note with the given path from the index.
 func (d *NoteDAO) Remove(path string) error {
 	id, err := d.findIdByPath(path)
@@ -263,6 +361,32 @@ func (d *NoteDAO) Remove(path string) error {
 	return err
 }
 
+// Move changes the path of the note at oldPath, keeping its ID and links.
+func (d *NoteDAO) Move(oldPath string, newPath string) error {
+	id, err := d.findIdByPath(oldPath)
+	if err != nil {
+		return err
+	}
+	if !id.IsValid() {
+		return errors.New("note not found in the index")
+	}
+
+	_, err = d.moveStmt.Exec(newPath, sortablePath(newPath), id)
+	if err != nil {
+		return err
+	}
+
+	// The inbound links whose href doesn't match the new path are dead.
+	_, err = d.unsetLinksTargetStmt.Exec(int64(id), newPath)
+	if err != nil {
+		return err
+	}
+
+	// Links which were not resolved might match the new path.
+	_, err = d.setLinksTargetStmt.Exec(int64(id), newPath)
+	return err
+}
+
 func (d *NoteDAO) findIdByPath(path string) (core.NoteID, error) {
 	row, err := d.findIdByPathStmt.QueryRow(path)
 	if err != nil {
@@ -315,6 +439,104 @@ func idForRow(row *sql.Row) (core.NoteID, error) {
 	}
 }
 
+// FindLinksBetweenNotes returns the links whose source and target are both
+// among the given notes.
+func (d *NoteDAO) FindLinksBetweenNotes(ids []core.NoteID) ([]core.ResolvedLink, error) {
+	links := make([]core.ResolvedLink, 0)
+	if len(ids) == 0 {
+		return links, nil
+	}
+
+	idsList := "(" + d.joinIds(ids, ",") + ")"
+	rows, err := d.tx.Query(`
+		SELECT l.source_id, s.path, l.target_id, t.path, l.title, l.href, l.external, l.rels, l.snippet, l.snippet_start, l.snippet_end
+		  FROM links l
+		  JOIN notes s ON s.id = l.source_id
+		  JOIN notes t ON t.id = l.target_id
+		 WHERE l.source_id IN ` + idsList + `
+		   AND l.target_id IN ` + idsList + `
+		 ORDER BY l.id
+	`)
+	if err != nil {
+		return links, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		link, err := d.scanResolvedLink(rows)
+		if err != nil {
+			d.logger.Err(err)
+			continue
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+// FindDeadLinks returns the internal links whose target could not be found in
+// the index.
+func (d *NoteDAO) FindDeadLinks() ([]core.ResolvedLink, error) {
+	links := make([]core.ResolvedLink, 0)
+
+	rows, err := d.tx.Query(`
+		SELECT l.source_id, s.path, 0, '', l.title, l.href, l.external, l.rels, l.snippet, l.snippet_start, l.snippet_end
+		  FROM links l
+		  JOIN notes s ON s.id = l.source_id
+		 WHERE l.target_id IS NULL AND l.external = 0
+		 ORDER BY s.sortable_path, l.id
+	`)
+	if err != nil {
+		return links, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		link, err := d.scanResolvedLink(rows)
+		if err != nil {
+			d.logger.Err(err)
+			continue
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+func (d *NoteDAO) scanResolvedLink(row RowScanner) (core.ResolvedLink, error) {
+	var (
+		sourceID, targetID         int64
+		sourcePath, targetPath     string
+		title, href, rels, snippet string
+		isExternal                 bool
+		snippetStart, snippetEnd   int
+	)
+
+	err := row.Scan(
+		&sourceID, &sourcePath, &targetID, &targetPath, &title, &href,
+		&isExternal, &rels, &snippet, &snippetStart, &snippetEnd,
+	)
+	if err != nil {
+		return core.ResolvedLink{}, err
+	}
+
+	return core.ResolvedLink{
+		SourceID:   core.NoteID(sourceID),
+		SourcePath: sourcePath,
+		TargetID:   core.NoteID(targetID),
+		TargetPath: targetPath,
+		Link: core.Link{
+			Title:        title,
+			Href:         href,
+			IsExternal:   isExternal,
+			Rels:         splitLinkRels(rels),
+			Snippet:      snippet,
+			SnippetStart: snippetStart,
+			SnippetEnd:   snippetEnd,
+		},
+	}, nil
+}
+
 func (d *NoteDAO) FindMinimal(opts core.NoteFindOpts) ([]core.MinimalNote, error) {
 	notes := make([]core.MinimalNote, 0)
 
@@ -345,21 +567,27 @@ func (d *NoteDAO) FindMinimal(opts core.NoteFindOpts) ([]core.MinimalNote, error
 
 func (d *NoteDAO) scanMinimalNote(row RowScanner) (*core.MinimalNote, error) {
 	var (
-		id          int
-		path, title string
+		id                        int
+		path, title, metadataJSON string
 	)
 
-	err := row.Scan(&id, &path, &title)
+	err := row.Scan(&id, &path, &title, &metadataJSON)
 	switch {
 	case err == sql.ErrNoRows:
 		return nil, nil
 	case err != nil:
 		return nil, err
 	default:
+		metadata, err := unmarshalMetadata(metadataJSON)
+		if err != nil {
+			d.logger.Err(errors.Wrap(err, path))
+		}
+
 		return &core.MinimalNote{
-			ID:    core.NoteID(id),
-			Path:  path,
-			Title: title,
+			ID:       core.NoteID(id),
+			Path:     path,
+			Title:    title,
+			Metadata: metadata,
 		}, nil
 	}
 }
@@ -379,6 +607,7 @@ func (d *NoteDAO) Find(opts core.NoteFindOpts) ([]core.ContextualNote, error) {
 	}
 	defer rows.Close()
 
+	ids := []core.NoteID{}
 	for rows.Next() {
 		note, err := d.scanNote(rows)
 		if err != nil {
@@ -387,12 +616,95 @@ func (d *NoteDAO) Find(opts core.NoteFindOpts) ([]core.ContextualNote, error) {
 		}
 		if note != nil {
 			notes = append(notes, *note)
+			ids = append(ids, note.ID)
+		}
+	}
+
+	sections, err := d.FindSections(ids)
+	if err != nil {
+		return notes, err
+	}
+	for i, note := range notes {
+		notes[i].Sections = sections[note.ID]
+		if !opts.Match.IsNull() {
+			notes[i].MatchedSection = matchedSection(notes[i], opts)
 		}
 	}
 
 	return notes, nil
 }
 
+// FindSections returns the sections of the given notes, ordered by their
+// position in the notes.
+func (d *NoteDAO) FindSections(ids []core.NoteID) (map[core.NoteID][]core.Section, error) {
+	sections := map[core.NoteID][]core.Section{}
+	if len(ids) == 0 {
+		return sections, nil
+	}
+
+	rows, err := d.tx.Query(`
+		SELECT note_id, level, title, slug, start, end
+		  FROM sections
+		 WHERE note_id IN (` + d.joinIds(ids, ",") + `)
+		 ORDER BY note_id, start
+	`)
+	if err != nil {
+		return sections, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var section core.Section
+		err := rows.Scan(&id, &section.Level, &section.Title, &section.Slug, &section.Start, &section.End)
+		if err != nil {
+			return sections, err
+		}
+		sections[core.NoteID(id)] = append(sections[core.NoteID(id)], section)
+	}
+
+	return sections, rows.Err()
+}
+
+// matchedSection returns the section of the note containing the first match
+// of the search query, located from the highlighted snippet.
+func matchedSection(note core.ContextualNote, opts core.NoteFindOpts) *core.Section {
+	if len(note.Sections) == 0 {
+		return nil
+	}
+
+	offset := -1
+	if opts.ExactMatch {
+		offset = strings.Index(strings.ToLower(note.RawContent), strings.ToLower(opts.Match.String()))
+
+	} else if len(note.Snippets) > 0 {
+		snippet := note.Snippets[0]
+		start := strings.Index(snippet, "<zk:match>")
+		end := strings.Index(snippet, "</zk:match>")
+		if start < 0 || end < start {
+			return nil
+		}
+		// The text preceding the match disambiguates the term occurrence.
+		prefix := snippet[:start]
+		if i := strings.LastIndex(prefix, "…"); i >= 0 {
+			prefix = prefix[i+len("…"):]
+		}
+		prefix = strings.ReplaceAll(strings.ReplaceAll(prefix, "<zk:match>", ""), "</zk:match>", "")
+		term := snippet[start+len("<zk:match>") : end]
+
+		if i := strings.Index(note.RawContent, prefix+term); i >= 0 {
+			offset = i + len(prefix)
+		} else {
+			offset = strings.Index(note.RawContent, term)
+		}
+	}
+
+	if offset < 0 {
+		return nil
+	}
+	return core.SectionAt(note.Sections, offset)
+}
+
 func (d *NoteDAO) scanNote(row RowScanner) (*core.ContextualNote, error) {
 	var (
 		id, wordCount                 int
@@ -594,6 +906,23 @@ func (d *NoteDAO) findRows(opts core.NoteFindOpts, minimal bool) (*sql.Rows, err
 		}
 	}
 
+	if !opts.TitleMatch.IsNull() {
+		whereExprs = append(whereExprs, `(n.title LIKE '%' || ? || '%' ESCAPE '\' OR EXISTS (
+SELECT 1 FROM json_each(n.metadata, '$.aliases') a
+WHERE a.type = 'text' AND a.value LIKE '%' || ? || '%' ESCAPE '\'
+))`)
+		term := escapeLikeTerm(opts.TitleMatch.String(), '\\')
+		args = append(args, term, term)
+	}
+
+	if !opts.HeadingMatch.IsNull() {
+		whereExprs = append(whereExprs, `n.id IN (
+SELECT note_id FROM sections
+WHERE title LIKE '%' || ? || '%' ESCAPE '\'
+)`)
+		args = append(args, escapeLikeTerm(opts.HeadingMatch.String(), '\\'))
+	}
+
 	if opts.IncludePaths != nil {
 		regexes := make([]string, 0)
 		for _, path := range opts.IncludePaths {
@@ -660,6 +989,19 @@ WHERE collection_id IN (SELECT id FROM collections t WHERE kind = '%s' AND (%s))
 		}
 	}
 
+	if opts.ExactTags != nil {
+		whereExprs = append(whereExprs, fmt.Sprintf(`n.id IN (
+SELECT note_id FROM notes_collections
+WHERE collection_id IN (SELECT id FROM collections t WHERE kind = '%s' AND t.name IN (%s))
+)`,
+			core.CollectionKindTag,
+			strings.TrimSuffix(strings.Repeat("?, ", len(opts.ExactTags)), ", "),
+		))
+		for _, tag := range opts.ExactTags {
+			args = append(args, tag)
+		}
+	}
+
 	if opts.MentionedBy != nil {
 		ids, err := d.findIdsByPathPrefixes(opts.MentionedBy)
 		if err != nil {
@@ -728,6 +1070,29 @@ WHERE collection_id IN (SELECT id FROM collections t WHERE kind = '%s' AND (%s))
 		args = append(args, opts.ModifiedEnd)
 	}
 
+	linkCountFilters := []struct {
+		count *int
+		expr  string
+		op    string
+	}{
+		{opts.MinBacklinks, backlinkCountExpr, ">="},
+		{opts.MaxBacklinks, backlinkCountExpr, "<="},
+		{opts.MinLinks, linkCountExpr, ">="},
+		{opts.MaxLinks, linkCountExpr, "<="},
+	}
+	for _, filter := range linkCountFilters {
+		if filter.count != nil {
+			whereExprs = append(whereExprs, filter.expr+" "+filter.op+" ?")
+			args = append(args, *filter.count)
+		}
+	}
+
+	for _, filter := range opts.Metadata {
+		expr, exprArgs := metadataFilterExpr(filter)
+		whereExprs = append(whereExprs, expr)
+		args = append(args, exprArgs...)
+	}
+
 	if opts.ExcludeIDs != nil {
 		whereExprs = append(whereExprs, "n.id NOT IN ("+d.joinIds(opts.ExcludeIDs, ",")+")")
 	}
@@ -771,9 +1136,10 @@ WHERE collection_id IN (SELECT id FROM collections t WHERE kind = '%s' AND (%s))
 		query += "\n)\n"
 	}
 
-	query += "SELECT n.id, n.path, n.title"
-	if !minimal {
-		query += fmt.Sprintf(", n.lead, n.body, n.raw_content, n.word_count, n.created, n.modified, n.metadata, n.checksum, n.tags, %s AS snippet", snippetCol)
+	if minimal {
+		query += "SELECT n.id, n.path, n.title, n.metadata"
+	} else {
+		query += fmt.Sprintf("SELECT n.id, n.path, n.title, n.lead, n.body, n.raw_content, n.word_count, n.created, n.modified, n.metadata, n.checksum, n.tags, %s AS snippet", snippetCol)
 	}
 
 	query += "\nFROM notes_with_metadata n\n"
@@ -802,6 +1168,86 @@ WHERE collection_id IN (SELECT id FROM collections t WHERE kind = '%s' AND (%s))
 	return d.tx.Query(query, args...)
 }
 
+var isoDateRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}([T ]\d{2}:\d{2}(:\d{2})?)?$`)
+
+// metadataFilterExpr returns an SQL expression matching the notes with a
+// frontmatter value satisfying the given filter, using the JSON1 extension.
+//
+// Values are compared as numbers or dates when the filter value looks like
+// one, and as strings otherwise. With a list, any of its items can match.
+func metadataFilterExpr(filter core.MetadataFilter) (string, []interface{}) {
+	path := metadataJSONPath(filter.Key)
+	args := []interface{}{path}
+
+	var expr string
+	if filter.Operator == core.MetadataExists {
+		expr = "json_type(n.metadata, ?) IS NOT NULL"
+
+	} else {
+		var valueExpr string
+		switch filter.Operator {
+		case core.MetadataEqual:
+			// Booleans are returned as 0 and 1 by json_each.
+			textValue := "CASE m.type WHEN 'true' THEN 'true' WHEN 'false' THEN 'false' ELSE CAST(m.value AS TEXT) END"
+			if strings.ContainsAny(filter.Value, "*?[") {
+				valueExpr = textValue + " GLOB ?"
+			} else {
+				valueExpr = textValue + " = ?"
+			}
+			args = append(args, filter.Value)
+
+		default:
+			op := metadataOperators[filter.Operator]
+			if number, err := strconv.ParseFloat(filter.Value, 64); err == nil {
+				valueExpr = "m.type IN ('integer', 'real') AND m.value " + op + " ?"
+				args = append(args, number)
+			} else if isoDateRegex.MatchString(filter.Value) {
+				valueExpr = "m.type = 'text' AND datetime(m.value) " + op + " datetime(?)"
+				args = append(args, filter.Value)
+			} else {
+				valueExpr = "m.type = 'text' AND m.value " + op + " ?"
+				args = append(args, filter.Value)
+			}
+		}
+
+		expr = "EXISTS (SELECT 1 FROM json_each(n.metadata, ?) m WHERE " + valueExpr + ")"
+	}
+
+	if filter.Negate {
+		expr = "NOT " + expr
+	}
+	return expr, args
+}
+
+var metadataOperators = map[core.MetadataOperator]string{
+	core.MetadataLess:           "<",
+	core.MetadataLessOrEqual:    "<=",
+	core.MetadataGreater:        ">",
+	core.MetadataGreaterOrEqual: ">=",
+}
+
+// metadataJSONPath returns the JSON1 path to the frontmatter value with the
+// given key. The top-level keys are stored in lowercase.
+func metadataJSONPath(key string) string {
+	path := "$"
+	for i, part := range strings.Split(key, ".") {
+		if i == 0 {
+			part = strings.ToLower(part)
+		}
+		path += `."` + strings.TrimSpace(part) + `"`
+	}
+	return path
+}
+
+// Number of links pointing to a note from other notes.
+const backlinkCountExpr = "(SELECT COUNT(*) FROM links WHERE target_id = n.id AND source_id <> n.id)"
+
+// Number of internal links found in a note, including dead links.
+const linkCountExpr = "(SELECT COUNT(*) FROM links WHERE source_id = n.id AND external = 0)"
+
+// Number of external links found in a note.
+const externalLinkCountExpr = "(SELECT COUNT(*) FROM links WHERE source_id = n.id AND external = 1)"
+
 func orderTerm(sorter core.NoteSorter) string {
 	order := " ASC"
 	if !sorter.Ascending {
@@ -821,6 +1267,12 @@ func orderTerm(sorter core.NoteSorter) string {
 		return "n.title" + order
 	case core.NoteSortWordCount:
 		return "n.word_count" + order
+	case core.NoteSortBacklinkCount:
+		return backlinkCountExpr + order
+	case core.NoteSortLinkCount:
+		return linkCountExpr + order
+	case core.NoteSortExternalLinkCount:
+		return externalLinkCountExpr + order
 	case core.NoteSortPathLength:
 		return "LENGTH(path)" + order
 	default:
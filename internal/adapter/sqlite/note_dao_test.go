@@ -102,6 +102,22 @@ func TestNoteDAOIndexed(t *testing.T) {
 	})
 }
 
+func TestNoteDAOChecksums(t *testing.T) {
+	testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
+		checksums, err := dao.Checksums()
+		assert.Nil(t, err)
+		assert.Equal(t, checksums, map[string]string{
+			"log/2021-01-03.md": "qwfpgj",
+			"log/2021-01-04.md": "arstde",
+			"index.md":          "iaefhv",
+			"f39c8.md":          "irkwyc",
+			"ref/test/b.md":     "yvwbae",
+			"ref/test/a.md":     "iecywst",
+			"log/2021-02-04.md": "earkte",
+		})
+	})
+}
+
 func TestNoteDAOAdd(t *testing.T) {
 	testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
 		_, err := dao.Add(core.Note{
@@ -351,6 +367,43 @@ func TestNoteDAOUpdateWithLinks(t *testing.T) {
 	})
 }
 
+func TestNoteDAOUpdateWithSections(t *testing.T) {
+	testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
+		update := func(sections []core.Section) {
+			_, err := dao.Update(core.Note{
+				Path:     "log/2021-01-03.md",
+				Sections: sections,
+			})
+			assert.Nil(t, err)
+		}
+
+		update([]core.Section{
+			{Level: 1, Title: "Daily note", Slug: "daily-note", Start: 0, End: 40},
+			{Level: 2, Title: "Content", Slug: "content", Start: 20, End: 40},
+		})
+		sections, err := dao.FindSections([]core.NoteID{1})
+		assert.Nil(t, err)
+		assert.Equal(t, sections, map[core.NoteID][]core.Section{
+			1: {
+				{Level: 1, Title: "Daily note", Slug: "daily-note", Start: 0, End: 40},
+				{Level: 2, Title: "Content", Slug: "content", Start: 20, End: 40},
+			},
+		})
+
+		// The previous sections are replaced.
+		update([]core.Section{
+			{Level: 1, Title: "Updated", Slug: "updated", Start: 0, End: 12},
+		})
+		sections, err = dao.FindSections([]core.NoteID{1})
+		assert.Nil(t, err)
+		assert.Equal(t, sections, map[core.NoteID][]core.Section{
+			1: {
+				{Level: 1, Title: "Updated", Slug: "updated", Start: 0, End: 12},
+			},
+		})
+	})
+}
+
 func TestNoteDAORemove(t *testing.T) {
 	testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
 		_, err := queryNoteRow(tx, `path = "ref/test/a.md"`)
@@ -391,19 +444,201 @@ func TestNoteDAORemoveCascadeLinks(t *testing.T) {
 	})
 }
 
+func TestNoteDAOMove(t *testing.T) {
+	testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
+		err := dao.Move("log/2021-01-03.md", "archive/daily.md")
+		assert.Nil(t, err)
+
+		id, err := dao.findIdByPath("archive/daily.md")
+		assert.Nil(t, err)
+		assert.Equal(t, id, core.NoteID(1))
+
+		id, err = dao.findIdByPath("log/2021-01-03.md")
+		assert.Nil(t, err)
+		assert.Equal(t, id.IsValid(), false)
+
+		// The inbound links don't match the new path anymore.
+		links := queryLinkRows(t, tx, `id = 4`)
+		assert.Nil(t, links[0].TargetId)
+	})
+}
+
+func TestNoteDAOMoveKeepsMatchingLinks(t *testing.T) {
+	testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
+		err := dao.Move("ref/test/a.md", "ref/test/a-renamed.md")
+		assert.Nil(t, err)
+
+		// Inbound links still matching the new path are preserved.
+		links := queryLinkRows(t, tx, `id IN (5, 6)`)
+		assert.Equal(t, *links[0].TargetId, core.NoteID(6))
+		assert.Equal(t, *links[1].TargetId, core.NoteID(6))
+	})
+}
+
+func TestNoteDAOMoveFillsLinksMissingTargetId(t *testing.T) {
+	testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
+		err := dao.Move("ref/test/b.md", "missing.md")
+		assert.Nil(t, err)
+
+		links := queryLinkRows(t, tx, `id = 1`)
+		assert.Equal(t, *links[0].TargetId, core.NoteID(5))
+	})
+}
+
+func TestNoteDAOMoveUnknown(t *testing.T) {
+	testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
+		err := dao.Move("unknown.md", "other.md")
+		assert.Err(t, err, "note not found in the index")
+	})
+}
+
+func TestNoteDAOFindSections(t *testing.T) {
+	testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
+		id, err := dao.Add(core.Note{
+			Path: "log/added.md",
+			Sections: []core.Section{
+				{Level: 1, Title: "Title", Slug: "title", Start: 0, End: 30},
+				{Level: 2, Title: "Sub-section", Slug: "sub-section", Start: 10, End: 30},
+			},
+		})
+		assert.Nil(t, err)
+
+		sections, err := dao.FindSections([]core.NoteID{1, id})
+		assert.Nil(t, err)
+		assert.Equal(t, sections, map[core.NoteID][]core.Section{
+			id: {
+				{Level: 1, Title: "Title", Slug: "title", Start: 0, End: 30},
+				{Level: 2, Title: "Sub-section", Slug: "sub-section", Start: 10, End: 30},
+			},
+		})
+	})
+}
+
+func TestNoteDAOFindSectionsWithoutIDs(t *testing.T) {
+	testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
+		sections, err := dao.FindSections([]core.NoteID{})
+		assert.Nil(t, err)
+		assert.Equal(t, sections, map[core.NoteID][]core.Section{})
+	})
+}
+
+func TestNoteDAORemoveCascadeSections(t *testing.T) {
+	testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
+		_, err := dao.Update(core.Note{
+			Path: "log/2021-01-03.md",
+			Sections: []core.Section{
+				{Level: 1, Title: "Daily note", Slug: "daily-note", Start: 0, End: 40},
+			},
+		})
+		assert.Nil(t, err)
+
+		err = dao.Remove("log/2021-01-03.md")
+		assert.Nil(t, err)
+
+		var count int
+		err = tx.QueryRow("SELECT COUNT(*) FROM sections WHERE note_id = 1").Scan(&count)
+		assert.Nil(t, err)
+		assert.Equal(t, count, 0)
+	})
+}
+
+func TestNoteDAOFindLinksBetweenNotes(t *testing.T) {
+	testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
+		links, err := dao.FindLinksBetweenNotes([]core.NoteID{1, 2, 3, 4})
+		assert.Nil(t, err)
+		assert.Equal(t, links, []core.ResolvedLink{
+			{
+				SourceID:   1,
+				SourcePath: "log/2021-01-03.md",
+				TargetID:   2,
+				TargetPath: "log/2021-01-04.md",
+				Link: core.Link{
+					Title:   "An internal link",
+					Href:    "log/2021-01-04.md",
+					Rels:    []core.LinkRelation{},
+					Snippet: "[[An internal link]]",
+				},
+			},
+			{
+				SourceID:   4,
+				SourcePath: "f39c8.md",
+				TargetID:   1,
+				TargetPath: "log/2021-01-03.md",
+				Link: core.Link{
+					Title:   "Another link",
+					Href:    "log/2021-01-03.md",
+					Rels:    []core.LinkRelation{},
+					Snippet: "[[Another link]]",
+				},
+			},
+			{
+				SourceID:   2,
+				SourcePath: "log/2021-01-04.md",
+				TargetID:   3,
+				TargetPath: "index.md",
+				Link: core.Link{
+					Title:   "A transition link",
+					Href:    "index.md",
+					Rels:    []core.LinkRelation{},
+					Snippet: "[[A transition link]]",
+				},
+			},
+			{
+				SourceID:   3,
+				SourcePath: "index.md",
+				TargetID:   4,
+				TargetPath: "f39c8.md",
+				Link: core.Link{
+					Title:   "Another transition link",
+					Href:    "f39c8.md",
+					Rels:    []core.LinkRelation{},
+					Snippet: "[[Another transition link]]",
+				},
+			},
+		})
+	})
+}
+
+func TestNoteDAOFindLinksBetweenNotesWithoutIDs(t *testing.T) {
+	testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
+		links, err := dao.FindLinksBetweenNotes([]core.NoteID{})
+		assert.Nil(t, err)
+		assert.Equal(t, links, []core.ResolvedLink{})
+	})
+}
+
+func TestNoteDAOFindDeadLinks(t *testing.T) {
+	testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
+		links, err := dao.FindDeadLinks()
+		assert.Nil(t, err)
+		assert.Equal(t, links, []core.ResolvedLink{
+			{
+				SourceID:   3,
+				SourcePath: "index.md",
+				Link: core.Link{
+					Title:   "Missing target",
+					Href:    "missing",
+					Rels:    []core.LinkRelation{},
+					Snippet: "There's a Missing target",
+				},
+			},
+		})
+	})
+}
+
 func TestNoteDAOFindMinimalAll(t *testing.T) {
 	testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
 		notes, err := dao.FindMinimal(core.NoteFindOpts{})
 		assert.Nil(t, err)
 
 		assert.Equal(t, notes, []core.MinimalNote{
-			{ID: 5, Path: "ref/test/b.md", Title: "A nested note"},
-			{ID: 4, Path: "f39c8.md", Title: "An interesting note"},
-			{ID: 6, Path: "ref/test/a.md", Title: "Another nested note"},
-			{ID: 1, Path: "log/2021-01-03.md", Title: "Daily note"},
-			{ID: 7, Path: "log/2021-02-04.md", Title: "February 4, 2021"},
-			{ID: 3, Path: "index.md", Title: "Index"},
-			{ID: 2, Path: "log/2021-01-04.md", Title: "January 4, 2021"},
+			{ID: 5, Path: "ref/test/b.md", Title: "A nested note", Metadata: map[string]interface{}{}},
+			{ID: 4, Path: "f39c8.md", Title: "An interesting note", Metadata: map[string]interface{}{}},
+			{ID: 6, Path: "ref/test/a.md", Title: "Another nested note", Metadata: map[string]interface{}{"alias": "a.md"}},
+			{ID: 1, Path: "log/2021-01-03.md", Title: "Daily note", Metadata: map[string]interface{}{"author": "Dom"}},
+			{ID: 7, Path: "log/2021-02-04.md", Title: "February 4, 2021", Metadata: map[string]interface{}{}},
+			{ID: 3, Path: "index.md", Title: "Index", Metadata: map[string]interface{}{"aliases": []interface{}{"First page"}}},
+			{ID: 2, Path: "log/2021-01-04.md", Title: "January 4, 2021", Metadata: map[string]interface{}{}},
 		})
 	})
 }
@@ -418,9 +653,9 @@ func TestNoteDAOFindMinimalWithFilter(t *testing.T) {
 		assert.Nil(t, err)
 
 		assert.Equal(t, notes, []core.MinimalNote{
-			{ID: 1, Path: "log/2021-01-03.md", Title: "Daily note"},
-			{ID: 3, Path: "index.md", Title: "Index"},
-			{ID: 7, Path: "log/2021-02-04.md", Title: "February 4, 2021"},
+			{ID: 1, Path: "log/2021-01-03.md", Title: "Daily note", Metadata: map[string]interface{}{"author": "Dom"}},
+			{ID: 3, Path: "index.md", Title: "Index", Metadata: map[string]interface{}{"aliases": []interface{}{"First page"}}},
+			{ID: 7, Path: "log/2021-02-04.md", Title: "February 4, 2021", Metadata: map[string]interface{}{}},
 		})
 	})
 }
@@ -458,6 +693,72 @@ func TestNoteDAOFindTag(t *testing.T) {
 	test([]string{"NOTfiction"}, []string{"ref/test/b.md", "f39c8.md", "ref/test/a.md", "log/2021-02-04.md", "index.md", "log/2021-01-04.md"})
 }
 
+func TestNoteDAOFindExactTags(t *testing.T) {
+	test := func(tags []string, expectedPaths []string) {
+		testNoteDAOFindPaths(t, core.NoteFindOpts{ExactTags: tags}, expectedPaths)
+	}
+
+	test([]string{"fiction"}, []string{"log/2021-01-03.md"})
+	test([]string{"fiction", "fantasy"}, []string{"f39c8.md", "log/2021-01-03.md"})
+	test([]string{"fiction|fantasy"}, []string{})
+	test([]string{"fict*"}, []string{})
+	test([]string{" adventure "}, []string{})
+	test([]string{}, []string{})
+}
+
+func TestNoteDAOFindMetadata(t *testing.T) {
+	test := func(filters []string, expectedPaths []string) {
+		metadata, err := core.MetadataFiltersFromStrings(filters)
+		assert.Nil(t, err)
+
+		testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
+			for path, metadata := range map[string]string{
+				"f39c8.md":          `{"status": "draft", "priority": 3, "review": "2021-06-01", "published": false, "project": {"name": "zk"}}`,
+				"ref/test/b.md":     `{"status": "done", "priority": 1.5, "review": "2021-07-15T10:00:00Z", "published": true}`,
+				"log/2021-02-04.md": `{"status": ["draft", "review"], "priority": "high", "archived": null}`,
+			} {
+				_, err := tx.Exec("UPDATE notes SET metadata = ? WHERE path = ?", metadata, path)
+				assert.Nil(t, err)
+			}
+
+			matches, err := dao.Find(core.NoteFindOpts{Metadata: metadata})
+			assert.Nil(t, err)
+
+			actual := make([]string, 0)
+			for _, m := range matches {
+				actual = append(actual, m.Path)
+			}
+			assert.Equal(t, actual, expectedPaths)
+		})
+	}
+
+	// Equality
+	test([]string{"author=Dom"}, []string{"log/2021-01-03.md"})
+	test([]string{"Author=Dom"}, []string{"log/2021-01-03.md"})
+	test([]string{"author=dom"}, []string{})
+	test([]string{"status=draft"}, []string{"f39c8.md", "log/2021-02-04.md"})
+	test([]string{"aliases=First page"}, []string{"index.md"})
+	test([]string{"status=d*"}, []string{"ref/test/b.md", "f39c8.md", "log/2021-02-04.md"})
+	test([]string{"priority=3"}, []string{"f39c8.md"})
+	test([]string{"published=false"}, []string{"f39c8.md"})
+	test([]string{"project.name=zk"}, []string{"f39c8.md"})
+	test([]string{"status!=draft"}, []string{"ref/test/b.md", "ref/test/a.md", "log/2021-01-03.md", "index.md", "log/2021-01-04.md"})
+
+	// Existence
+	test([]string{"alias"}, []string{"ref/test/a.md"})
+	test([]string{"archived"}, []string{"log/2021-02-04.md"})
+	test([]string{"status", "-archived"}, []string{"ref/test/b.md", "f39c8.md"})
+
+	// Comparisons
+	test([]string{"priority>2"}, []string{"f39c8.md"})
+	test([]string{"priority>=1.5"}, []string{"ref/test/b.md", "f39c8.md"})
+	test([]string{"priority<3"}, []string{"ref/test/b.md"})
+	test([]string{"review<2021-07-01"}, []string{"f39c8.md"})
+	test([]string{"review>=2021-06-01"}, []string{"ref/test/b.md", "f39c8.md"})
+	test([]string{"review>2021-07-15 09:00"}, []string{"ref/test/b.md"})
+	test([]string{"status>draft"}, []string{"log/2021-02-04.md"})
+}
+
 func TestNoteDAOFindMatch(t *testing.T) {
 	testNoteDAOFind(t,
 		core.NoteFindOpts{Match: opt.NewString("daily | index")},
@@ -542,6 +843,40 @@ func TestNoteDAOFindMatch(t *testing.T) {
 	)
 }
 
+func TestNoteDAOFindMatchedSection(t *testing.T) {
+	testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
+		sections := []core.Section{
+			{Level: 1, Title: "Index", Slug: "index", Start: 0, End: 65},
+			{Level: 2, Title: "Daily notes", Slug: "daily-notes", Start: 35, End: 65},
+		}
+		_, err := dao.Update(core.Note{
+			Path:       "index.md",
+			Title:      "Index",
+			Body:       "Index of the Zettelkasten\n\n## Daily notes\nList of entries",
+			RawContent: "# Index\nIndex of the Zettelkasten\n\n## Daily notes\nList of entries",
+			Sections:   sections,
+		})
+		assert.Nil(t, err)
+
+		test := func(match string, exact bool, expected *core.Section) {
+			notes, err := dao.Find(core.NoteFindOpts{
+				Match:        opt.NewString(match),
+				ExactMatch:   exact,
+				IncludePaths: []string{"index.md"},
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, len(notes), 1)
+			assert.Equal(t, notes[0].Sections, sections)
+			assert.Equal(t, notes[0].MatchedSection, expected)
+		}
+
+		test("zettelkasten", false, &sections[0])
+		test("entries", false, &sections[1])
+		test("of entries", true, &sections[1])
+		test("index of", true, &sections[0])
+	})
+}
+
 func TestNoteDAOFindMatchWithSort(t *testing.T) {
 	testNoteDAOFindPaths(t,
 		core.NoteFindOpts{
@@ -576,6 +911,44 @@ func TestNoteDAOFindExactMatch(t *testing.T) {
 	test(`[exact% ch\ar_acters]`, []string{"ref/test/a.md"})
 }
 
+func TestNoteDAOFindTitleMatch(t *testing.T) {
+	test := func(match string, expected []string) {
+		testNoteDAOFindPaths(t, core.NoteFindOpts{TitleMatch: opt.NewString(match)}, expected)
+	}
+
+	// Case insensitive
+	test("NESTED", []string{"ref/test/b.md", "ref/test/a.md"})
+	// Aliases
+	test("first", []string{"index.md"})
+	// Special characters
+	test("%", []string{})
+}
+
+func TestNoteDAOFindHeadingMatch(t *testing.T) {
+	testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
+		_, err := tx.Exec(`
+			INSERT INTO sections (note_id, level, title, slug, start, end)
+			VALUES (1, 1, 'Daily review', 'daily-review', 0, 10),
+			       (4, 2, 'Weekly review', 'weekly-review', 0, 10)
+		`)
+		assert.Nil(t, err)
+
+		test := func(match string, expected []string) {
+			notes, err := dao.FindMinimal(core.NoteFindOpts{HeadingMatch: opt.NewString(match)})
+			assert.Nil(t, err)
+			actual := []string{}
+			for _, note := range notes {
+				actual = append(actual, note.Path)
+			}
+			assert.Equal(t, actual, expected)
+		}
+
+		test("REVIEW", []string{"f39c8.md", "log/2021-01-03.md"})
+		test("weekly", []string{"f39c8.md"})
+		test("monthly", []string{})
+	})
+}
+
 func TestNoteDAOFindExactMatchCannotBeUsedWithMention(t *testing.T) {
 	testNoteDAO(t, func(tx Transaction, dao *NoteDAO) {
 		_, err := dao.Find(core.NoteFindOpts{
@@ -969,6 +1342,32 @@ func TestNoteDAOFindOrphan(t *testing.T) {
 	)
 }
 
+func TestNoteDAOFindBacklinkCount(t *testing.T) {
+	test := func(min, max *int, expectedPaths []string) {
+		testNoteDAOFindPaths(t, core.NoteFindOpts{MinBacklinks: min, MaxBacklinks: max}, expectedPaths)
+	}
+
+	test(intPointer(2), nil, []string{"ref/test/a.md"})
+	test(nil, intPointer(0), []string{"ref/test/b.md", "log/2021-02-04.md"})
+	test(intPointer(1), intPointer(1), []string{"f39c8.md", "log/2021-01-03.md", "index.md", "log/2021-01-04.md"})
+}
+
+func TestNoteDAOFindLinkCount(t *testing.T) {
+	test := func(min, max *int, expectedPaths []string) {
+		testNoteDAOFindPaths(t, core.NoteFindOpts{MinLinks: min, MaxLinks: max}, expectedPaths)
+	}
+
+	test(intPointer(2), nil, []string{"f39c8.md", "index.md"})
+	test(nil, intPointer(0), []string{"ref/test/b.md", "ref/test/a.md", "log/2021-02-04.md"})
+}
+
+func TestNoteDAOFindBacklinkAndLinkCount(t *testing.T) {
+	testNoteDAOFindPaths(t,
+		core.NoteFindOpts{MinBacklinks: intPointer(1), MaxLinks: intPointer(1)},
+		[]string{"ref/test/a.md", "log/2021-01-03.md", "log/2021-01-04.md"},
+	)
+}
+
 func TestNoteDAOFindCreatedOn(t *testing.T) {
 	start := time.Date(2020, 11, 22, 0, 0, 0, 0, time.UTC)
 	end := time.Date(2020, 11, 23, 0, 0, 0, 0, time.UTC)
@@ -1088,6 +1487,31 @@ func TestNoteDAOFindSortWordCount(t *testing.T) {
 	})
 }
 
+func TestNoteDAOFindSortBacklinkCount(t *testing.T) {
+	testNoteDAOFindSort(t, core.NoteSortBacklinkCount, true, []string{
+		"ref/test/b.md", "log/2021-02-04.md", "f39c8.md", "log/2021-01-03.md",
+		"index.md", "log/2021-01-04.md", "ref/test/a.md",
+	})
+	testNoteDAOFindSort(t, core.NoteSortBacklinkCount, false, []string{
+		"ref/test/a.md", "f39c8.md", "log/2021-01-03.md", "index.md",
+		"log/2021-01-04.md", "ref/test/b.md", "log/2021-02-04.md",
+	})
+}
+
+func TestNoteDAOFindSortLinkCount(t *testing.T) {
+	testNoteDAOFindSort(t, core.NoteSortLinkCount, false, []string{
+		"f39c8.md", "index.md", "log/2021-01-03.md", "log/2021-01-04.md",
+		"ref/test/b.md", "ref/test/a.md", "log/2021-02-04.md",
+	})
+}
+
+func TestNoteDAOFindSortExternalLinkCount(t *testing.T) {
+	testNoteDAOFindSort(t, core.NoteSortExternalLinkCount, false, []string{
+		"log/2021-01-03.md", "ref/test/b.md", "f39c8.md", "ref/test/a.md",
+		"log/2021-02-04.md", "index.md", "log/2021-01-04.md",
+	})
+}
+
 func testNoteDAOFindSort(t *testing.T, field core.NoteSortField, ascending bool, expected []string) {
 	testNoteDAOFindPaths(t,
 		core.NoteFindOpts{
@@ -1183,6 +1607,10 @@ func queryLinkRows(t *testing.T, tx Transaction, where string) []linkRow {
 	return links
 }
 
+func intPointer(i int) *int {
+	return &i
+}
+
 func idPointer(i int64) *core.NoteID {
 	id := core.NoteID(i)
 	return &id
@@ -1,6 +1,8 @@
 package sqlite
 
 import (
+	"strconv"
+
 	"github.com/mickael-menu/zk/internal/core"
 	"github.com/mickael-menu/zk/internal/util"
 	"github.com/mickael-menu/zk/internal/util/errors"
@@ -46,10 +48,37 @@ func (ni *NoteIndex) FindMinimal(opts core.NoteFindOpts) (notes []core.MinimalNo
 	return
 }
 
+// FindLinksBetweenNotes implements core.NoteIndex.
+func (ni *NoteIndex) FindLinksBetweenNotes(ids []core.NoteID) (links []core.ResolvedLink, err error) {
+	err = ni.commit(func(dao *dao) error {
+		links, err = dao.notes.FindLinksBetweenNotes(ids)
+		return err
+	})
+	return
+}
+
+// FindDeadLinks implements core.NoteIndex.
+func (ni *NoteIndex) FindDeadLinks() (links []core.ResolvedLink, err error) {
+	err = ni.commit(func(dao *dao) error {
+		links, err = dao.notes.FindDeadLinks()
+		return err
+	})
+	return
+}
+
+// FindSections implements core.NoteIndex.
+func (ni *NoteIndex) FindSections(ids []core.NoteID) (sections map[core.NoteID][]core.Section, err error) {
+	err = ni.commit(func(dao *dao) error {
+		sections, err = dao.notes.FindSections(ids)
+		return err
+	})
+	return
+}
+
 // FindCollections implements core.NoteIndex.
-func (ni *NoteIndex) FindCollections(kind core.CollectionKind) (collections []core.Collection, err error) {
+func (ni *NoteIndex) FindCollections(kind core.CollectionKind, sorters []core.CollectionSorter) (collections []core.Collection, err error) {
 	err = ni.commit(func(dao *dao) error {
-		collections, err = dao.collections.FindAll(kind)
+		collections, err = dao.collections.FindAll(kind, sorters)
 		return err
 	})
 	return
@@ -65,6 +94,16 @@ func (ni *NoteIndex) IndexedPaths() (metadata <-chan paths.Metadata, err error)
 	return
 }
 
+// IndexedChecksums implements core.NoteIndex.
+func (ni *NoteIndex) IndexedChecksums() (checksums map[string]string, err error) {
+	err = ni.commit(func(dao *dao) error {
+		checksums, err = dao.notes.Checksums()
+		return err
+	})
+	err = errors.Wrap(err, "failed to get the checksums of indexed notes")
+	return
+}
+
 // Add implements core.NoteIndex.
 func (ni *NoteIndex) Add(note core.Note) (id core.NoteID, err error) {
 	err = ni.commit(func(dao *dao) error {
@@ -122,6 +161,14 @@ func (ni *NoteIndex) Remove(path string) error {
 	return errors.Wrapf(err, "%v: failed to remove note from index", path)
 }
 
+// Move implements core.NoteIndex
+func (ni *NoteIndex) Move(oldPath string, newPath string) error {
+	err := ni.commit(func(dao *dao) error {
+		return dao.notes.Move(oldPath, newPath)
+	})
+	return errors.Wrapf(err, "%v: failed to move note to %v in the index", oldPath, newPath)
+}
+
 // Commit implements core.NoteIndex.
 func (ni *NoteIndex) Commit(transaction func(idx core.NoteIndex) error) error {
 	return ni.commit(func(dao *dao) error {
@@ -155,6 +202,31 @@ func (ni *NoteIndex) SetNeedsReindexing(needsReindexing bool) error {
 	})
 }
 
+// Counter implements core.NoteIndex.
+func (ni *NoteIndex) Counter(name string) (value int, err error) {
+	err = ni.commit(func(dao *dao) error {
+		res, err := dao.metadata.Get(counterKeyPrefix + name)
+		if err != nil || res == "" {
+			return err
+		}
+		value, err = strconv.Atoi(res)
+		return errors.Wrapf(err, "invalid value for the counter %s", name)
+	})
+	return
+}
+
+// SetCounter implements core.NoteIndex.
+func (ni *NoteIndex) SetCounter(name string, value int) error {
+	return ni.commit(func(dao *dao) error {
+		return dao.metadata.Set(counterKeyPrefix+name, strconv.Itoa(value))
+	})
+}
+
+// Close implements core.NoteIndex.
+func (ni *NoteIndex) Close() error {
+	return ni.db.Close()
+}
+
 func (ni *NoteIndex) commit(transaction func(dao *dao) error) error {
 	if ni.dao != nil {
 		return transaction(ni.dao)
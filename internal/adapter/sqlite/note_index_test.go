@@ -48,6 +48,26 @@ func TestNoteIndexUpdateWithTags(t *testing.T) {
 	assertSQL(true)
 }
 
+func TestNoteIndexCounter(t *testing.T) {
+	_, index := testNoteIndex(t)
+
+	value, err := index.Counter("id")
+	assert.Nil(t, err)
+	assert.Equal(t, value, 0)
+
+	err = index.SetCounter("id", 42)
+	assert.Nil(t, err)
+	err = index.SetCounter("id.log", 3)
+	assert.Nil(t, err)
+
+	value, err = index.Counter("id")
+	assert.Nil(t, err)
+	assert.Equal(t, value, 42)
+	value, err = index.Counter("id.log")
+	assert.Nil(t, err)
+	assert.Equal(t, value, 3)
+}
+
 func testNoteIndex(t *testing.T) (*DB, *NoteIndex) {
 	db := testDB(t)
 	return db, NewNoteIndex(db, &util.NullLogger)
@@ -2,10 +2,12 @@ package term
 
 import (
 	"os"
+	"strconv"
 	"strings"
 
 	survey "github.com/AlecAivazis/survey/v2"
 	"github.com/mattn/go-isatty"
+	"github.com/mickael-menu/zk/internal/core"
 )
 
 // Terminal offers utilities to interact with the terminal.
@@ -49,3 +51,54 @@ func (t *Terminal) Confirm(msg string, defaultAnswer bool) (confirmed, skipped b
 	survey.AskOne(prompt, &confirmed)
 	return confirmed, false
 }
+
+// PromptTemplateVariable asks the user for the value of a template variable.
+// It implements core.TemplateVariablePrompter.
+func (t *Terminal) PromptTemplateVariable(name string, variable core.TemplateVariable) (string, error) {
+	msg := variable.Description
+	if msg == "" {
+		msg = name
+	}
+
+	var value string
+	var err error
+	switch {
+	case len(variable.Choices) > 0:
+		prompt := &survey.Select{
+			Message: msg,
+			Options: variable.Choices,
+		}
+		if def := variable.Default.Unwrap(); def != "" {
+			prompt.Default = def
+		}
+		err = survey.AskOne(prompt, &value)
+
+	case variable.Type == core.TemplateVariableBoolean:
+		defaultAnswer, _ := strconv.ParseBool(variable.Default.Unwrap())
+		confirmed := false
+		err = survey.AskOne(&survey.Confirm{
+			Message: msg,
+			Default: defaultAnswer,
+		}, &confirmed)
+		value = strconv.FormatBool(confirmed)
+
+	default:
+		opts := []survey.AskOpt{
+			survey.WithValidator(func(ans interface{}) error {
+				if value, ok := ans.(string); ok && value != "" {
+					return variable.Validate(value)
+				}
+				return nil
+			}),
+		}
+		if variable.Required && variable.Default.IsNull() {
+			opts = append(opts, survey.WithValidator(survey.Required))
+		}
+		err = survey.AskOne(&survey.Input{
+			Message: msg,
+			Default: variable.Default.Unwrap(),
+		}, &value, opts...)
+	}
+
+	return value, err
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/mickael-menu/zk/internal/cli"
+	"github.com/mickael-menu/zk/internal/core"
+	"github.com/mickael-menu/zk/internal/util/strings"
+)
+
+// Check reports the issues found in the notebook, such as dead links.
+type Check struct {
+	Only   []string `placeholder:KIND help:"Only report the given kinds of issues among: dead-link, broken-anchor, parse-error, duplicate-title, orphan."`
+	Format string   `short:f placeholder:FORMAT default:text help:"Format of the report among: text, json."`
+	Quiet  bool     `short:q help:"Do not print the total number of issues found."`
+}
+
+func (cmd *Check) Run(container *cli.Container) error {
+	if cmd.Format != "text" && cmd.Format != "json" {
+		return fmt.Errorf("%s: unknown report format, expected one of: text, json", cmd.Format)
+	}
+
+	kinds := []core.NoteIssueKind{}
+	for _, str := range cmd.Only {
+		kind, err := core.NoteIssueKindFromString(str)
+		if err != nil {
+			return err
+		}
+		kinds = append(kinds, kind)
+	}
+
+	notebook, err := container.CurrentNotebook()
+	if err != nil {
+		return err
+	}
+
+	issues, err := notebook.Check(kinds)
+	if err != nil {
+		return err
+	}
+
+	switch cmd.Format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		err = encoder.Encode(issues)
+		if err != nil {
+			return err
+		}
+	default:
+		for _, issue := range issues {
+			fmt.Printf("%s: %s (%s)\n", issue.Path, issue.Message, issue.Kind)
+		}
+	}
+
+	count := len(issues)
+	if !cmd.Quiet {
+		fmt.Fprintf(os.Stderr, "\nFound %d %s\n", count, strings.Pluralize("issue", count))
+	}
+
+	// Exits with a non-zero status code to be usable in scripts.
+	if count > 0 {
+		return fmt.Errorf("the notebook has %d %s", count, strings.Pluralize("issue", count))
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mickael-menu/zk/internal/adapter/fzf"
+	"github.com/mickael-menu/zk/internal/cli"
+	"github.com/mickael-menu/zk/internal/core"
+	"github.com/mickael-menu/zk/internal/util/errors"
+	strutil "github.com/mickael-menu/zk/internal/util/strings"
+)
+
+// Graph exports the links between the notes matching a set of criteria.
+type Graph struct {
+	Format string `group:format short:f placeholder:FORMAT default:dot help:"Format of the graph among: dot, graphml, json."`
+	Quiet  bool   `group:format short:q help:"Do not print the total number of notes found."`
+	cli.Filtering
+}
+
+func (cmd *Graph) Run(container *cli.Container) error {
+	render, ok := graphRenderers[cmd.Format]
+	if !ok {
+		return fmt.Errorf("%s: unknown graph format, expected one of: dot, graphml, json", cmd.Format)
+	}
+
+	notebook, err := container.CurrentNotebook()
+	if err != nil {
+		return err
+	}
+
+	findOpts, err := cmd.Filtering.NewNoteFindOpts(notebook)
+	if err != nil {
+		return errors.Wrapf(err, "incorrect criteria")
+	}
+
+	notes, err := notebook.FindNotes(findOpts)
+	if err != nil {
+		return err
+	}
+
+	filter := container.NewNoteFilter(fzf.NoteFilterOpts{
+		Interactive:  cmd.Interactive,
+		AlwaysFilter: false,
+		NotebookDir:  notebook.Path,
+	})
+
+	notes, err = filter.Apply(notes)
+	if err != nil {
+		if err == fzf.ErrCancelled {
+			return nil
+		}
+		return err
+	}
+
+	ids := make([]core.NoteID, 0)
+	for _, note := range notes {
+		ids = append(ids, note.ID)
+	}
+	links, err := notebook.FindLinksBetweenNotes(ids)
+	if err != nil {
+		return err
+	}
+
+	err = render(os.Stdout, notes, links)
+	if err == nil && !cmd.Quiet {
+		count := len(notes)
+		fmt.Fprintf(os.Stderr, "\nFound %d %s\n", count, strutil.Pluralize("note", count))
+	}
+
+	return err
+}
+
+type graphRenderer func(out io.Writer, notes []core.ContextualNote, links []core.ResolvedLink) error
+
+var graphRenderers = map[string]graphRenderer{
+	"dot":     renderGraphDOT,
+	"graphml": renderGraphML,
+	"json":    renderGraphJSON,
+}
+
+// renderGraphDOT prints the graph in the Graphviz DOT language.
+// See https://graphviz.org/doc/info/lang.html
+func renderGraphDOT(out io.Writer, notes []core.ContextualNote, links []core.ResolvedLink) error {
+	quote := func(s string) string {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+		s = strings.ReplaceAll(s, `"`, `\"`)
+		s = strings.ReplaceAll(s, "\n", `\n`)
+		return `"` + s + `"`
+	}
+
+	fmt.Fprintln(out, "digraph zk {")
+	for _, note := range notes {
+		fmt.Fprintf(out, "  %s [label=%s, tags=%s];\n",
+			quote(note.Path), quote(note.Title), quote(strings.Join(note.Tags, ",")),
+		)
+	}
+	for _, link := range links {
+		fmt.Fprintf(out, "  %s -> %s [label=%s, rels=%s];\n",
+			quote(link.SourcePath), quote(link.TargetPath),
+			quote(link.Title), quote(joinRels(link.Rels)),
+		)
+	}
+	fmt.Fprintln(out, "}")
+	return nil
+}
+
+// renderGraphML prints the graph as a GraphML XML document.
+// See http://graphml.graphdrawing.org/
+func renderGraphML(out io.Writer, notes []core.ContextualNote, links []core.ResolvedLink) error {
+	escape := func(s string) string {
+		var b strings.Builder
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+
+	fmt.Fprint(out, `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="title" for="node" attr.name="title" attr.type="string"/>
+  <key id="path" for="node" attr.name="path" attr.type="string"/>
+  <key id="tags" for="node" attr.name="tags" attr.type="string"/>
+  <key id="label" for="edge" attr.name="title" attr.type="string"/>
+  <key id="rels" for="edge" attr.name="rels" attr.type="string"/>
+  <graph id="zk" edgedefault="directed">
+`)
+	for _, note := range notes {
+		fmt.Fprintf(out, `    <node id="%s">
+      <data key="title">%s</data>
+      <data key="path">%s</data>
+      <data key="tags">%s</data>
+    </node>
+`,
+			escape(note.Path), escape(note.Title), escape(note.Path),
+			escape(strings.Join(note.Tags, ",")),
+		)
+	}
+	for _, link := range links {
+		fmt.Fprintf(out, `    <edge source="%s" target="%s">
+      <data key="label">%s</data>
+      <data key="rels">%s</data>
+    </edge>
+`,
+			escape(link.SourcePath), escape(link.TargetPath),
+			escape(link.Title), escape(joinRels(link.Rels)),
+		)
+	}
+	fmt.Fprint(out, "  </graph>\n</graphml>\n")
+	return nil
+}
+
+// renderGraphJSON prints the graph as a JSON object containing the list of
+// nodes and edges.
+func renderGraphJSON(out io.Writer, notes []core.ContextualNote, links []core.ResolvedLink) error {
+	type node struct {
+		Path  string   `json:"path"`
+		Title string   `json:"title"`
+		Tags  []string `json:"tags"`
+	}
+	type edge struct {
+		Source string   `json:"source"`
+		Target string   `json:"target"`
+		Title  string   `json:"title"`
+		Href   string   `json:"href"`
+		Rels   []string `json:"rels"`
+	}
+
+	graph := struct {
+		Nodes []node `json:"nodes"`
+		Edges []edge `json:"edges"`
+	}{
+		Nodes: []node{},
+		Edges: []edge{},
+	}
+
+	for _, note := range notes {
+		graph.Nodes = append(graph.Nodes, node{
+			Path:  note.Path,
+			Title: note.Title,
+			Tags:  note.Tags,
+		})
+	}
+	for _, link := range links {
+		rels := []string{}
+		for _, rel := range link.Rels {
+			rels = append(rels, string(rel))
+		}
+		graph.Edges = append(graph.Edges, edge{
+			Source: link.SourcePath,
+			Target: link.TargetPath,
+			Title:  link.Title,
+			Href:   link.Href,
+			Rels:   rels,
+		})
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(graph)
+}
+
+func joinRels(rels []core.LinkRelation) string {
+	strs := []string{}
+	for _, rel := range rels {
+		strs = append(strs, string(rel))
+	}
+	return strings.Join(strs, ",")
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mickael-menu/zk/internal/core"
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+)
+
+var graphTestNotes = []core.ContextualNote{
+	{Note: core.Note{ID: 1, Path: "index.md", Title: "Index", Tags: []string{"hub"}}},
+	{Note: core.Note{ID: 2, Path: "dir/a.md", Title: `A "quoted" <note>`, Tags: []string{}}},
+}
+
+var graphTestLinks = []core.ResolvedLink{
+	{
+		SourceID:   1,
+		SourcePath: "index.md",
+		TargetID:   2,
+		TargetPath: "dir/a.md",
+		Link: core.Link{
+			Title: "Link to A",
+			Href:  "dir/a",
+			Rels:  []core.LinkRelation{"down"},
+		},
+	},
+}
+
+func TestGraphRenderDOT(t *testing.T) {
+	var out strings.Builder
+	err := renderGraphDOT(&out, graphTestNotes, graphTestLinks)
+	assert.Nil(t, err)
+	assert.Equal(t, out.String(), `digraph zk {
+  "index.md" [label="Index", tags="hub"];
+  "dir/a.md" [label="A \"quoted\" <note>", tags=""];
+  "index.md" -> "dir/a.md" [label="Link to A", rels="down"];
+}
+`)
+}
+
+func TestGraphRenderGraphML(t *testing.T) {
+	var out strings.Builder
+	err := renderGraphML(&out, graphTestNotes, graphTestLinks)
+	assert.Nil(t, err)
+	assert.Equal(t, out.String(), `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="title" for="node" attr.name="title" attr.type="string"/>
+  <key id="path" for="node" attr.name="path" attr.type="string"/>
+  <key id="tags" for="node" attr.name="tags" attr.type="string"/>
+  <key id="label" for="edge" attr.name="title" attr.type="string"/>
+  <key id="rels" for="edge" attr.name="rels" attr.type="string"/>
+  <graph id="zk" edgedefault="directed">
+    <node id="index.md">
+      <data key="title">Index</data>
+      <data key="path">index.md</data>
+      <data key="tags">hub</data>
+    </node>
+    <node id="dir/a.md">
+      <data key="title">A &#34;quoted&#34; &lt;note&gt;</data>
+      <data key="path">dir/a.md</data>
+      <data key="tags"></data>
+    </node>
+    <edge source="index.md" target="dir/a.md">
+      <data key="label">Link to A</data>
+      <data key="rels">down</data>
+    </edge>
+  </graph>
+</graphml>
+`)
+}
+
+func TestGraphRenderJSON(t *testing.T) {
+	var out strings.Builder
+	err := renderGraphJSON(&out, graphTestNotes, graphTestLinks)
+	assert.Nil(t, err)
+	assert.Equal(t, out.String(), `{"nodes":[{"path":"index.md","title":"Index","tags":["hub"]},{"path":"dir/a.md","title":"A \"quoted\" <note>","tags":[]}],"edges":[{"source":"index.md","target":"dir/a.md","title":"Link to A","href":"dir/a","rels":["down"]}]}
+`)
+}
+
+func TestGraphRenderEmptyJSON(t *testing.T) {
+	var out strings.Builder
+	err := renderGraphJSON(&out, []core.ContextualNote{}, []core.ResolvedLink{})
+	assert.Nil(t, err)
+	assert.Equal(t, out.String(), "{\"nodes\":[],\"edges\":[]}\n")
+}
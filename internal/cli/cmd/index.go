@@ -2,13 +2,19 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/mickael-menu/zk/internal/cli"
+	"github.com/mickael-menu/zk/internal/core"
 )
 
 // Index indexes the content of all the notes in the notebook.
 type Index struct {
 	Force bool `short:"f" help:"Force indexing all the notes."`
+	Watch bool `short:"w" help:"Keep indexing the modified notes until interrupted."`
 	Quiet bool `short:"q" help:"Do not print statistics nor progress."`
 }
 
@@ -31,5 +37,38 @@ func (cmd *Index) Run(container *cli.Container) error {
 		fmt.Println(stats)
 	}
 
+	if cmd.Watch {
+		return cmd.watch(notebook)
+	}
+
 	return nil
 }
+
+// watch indexes the notes modified in the notebook, until the process is
+// interrupted.
+func (cmd *Index) watch(notebook *core.Notebook) error {
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		close(stop)
+	}()
+
+	if !cmd.Quiet {
+		fmt.Println("\nWatching for changes, press Ctrl-C to stop")
+	}
+
+	return notebook.Watch(stop, func(stats core.NoteIndexingStats, err error) {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "zk: error: %v\n", err)
+			return
+		}
+		if !cmd.Quiet && stats.AddedCount+stats.ModifiedCount+stats.RemovedCount+stats.MovedCount > 0 {
+			fmt.Printf("%s  + %d added, ~ %d modified, - %d removed, > %d moved\n",
+				time.Now().Format("15:04:05"),
+				stats.AddedCount, stats.ModifiedCount, stats.RemovedCount, stats.MovedCount,
+			)
+		}
+	})
+}
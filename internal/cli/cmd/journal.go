@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mickael-menu/zk/internal/cli"
+	"github.com/mickael-menu/zk/internal/core"
+	dateutil "github.com/mickael-menu/zk/internal/util/date"
+	"github.com/mickael-menu/zk/internal/util/errors"
+)
+
+// Journal opens the journal note of a period, after creating it if needed.
+type Journal struct {
+	Period    string `arg optional default:"daily" help:"Period covered by the journal note: daily, weekly or monthly. A lone date opens a daily journal note."`
+	Date      string `arg optional placeholder:DATE help:"Date included in the period, in natural language (e.g. \"yesterday\"). Defaults to today."`
+	Prev      bool   `help:"Open the journal note of the period before the date."`
+	Next      bool   `help:"Open the journal note of the period after the date."`
+	PrintPath bool   `short:p help:"Print the path of the journal note instead of editing it."`
+}
+
+func (cmd *Journal) Run(container *cli.Container) error {
+	notebook, err := container.CurrentNotebook()
+	if err != nil {
+		return err
+	}
+
+	period, date, err := cmd.periodAndDate()
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if cmd.Prev {
+		offset--
+	}
+	if cmd.Next {
+		offset++
+	}
+
+	// Prompt for the missing template variables, unless --no-input is set.
+	var promptVariable core.TemplateVariablePrompter
+	if container.Terminal.IsInteractive() {
+		promptVariable = container.Terminal.PromptTemplateVariable
+	}
+
+	path, err := notebook.JournalNote(core.JournalOpts{
+		Period:         period,
+		Date:           date,
+		Offset:         offset,
+		PromptVariable: promptVariable,
+	})
+	if err != nil {
+		return err
+	}
+
+	if cmd.PrintPath {
+		fmt.Printf("%+v\n", path)
+		return nil
+	} else {
+		editor, err := container.NewNoteEditor(notebook)
+		if err != nil {
+			return err
+		}
+		return editor.Open(path)
+	}
+}
+
+// periodAndDate parses the period and date given as arguments. A lone
+// argument which is not a period is the date of a daily journal note, e.g.
+// `zk journal yesterday`.
+func (cmd *Journal) periodAndDate() (core.JournalPeriod, time.Time, error) {
+	period, err := core.JournalPeriodFromString(cmd.Period)
+	dateArg := cmd.Date
+	if err != nil {
+		if cmd.Date != "" {
+			return period, time.Time{}, err
+		}
+		if _, dateErr := dateutil.TimeFromNatural(cmd.Period); dateErr != nil {
+			return period, time.Time{}, err
+		}
+		period = core.JournalPeriodDaily
+		dateArg = cmd.Period
+	}
+
+	date, err := dateutil.TimeFromNatural(dateArg)
+	if err != nil {
+		return period, date, errors.Wrapf(err, "%s: failed to parse the date", dateArg)
+	}
+	return period, date, nil
+}
@@ -8,7 +8,8 @@ import (
 
 // LSP starts a server implementing the Language Server Protocol.
 type LSP struct {
-	Log string `hidden type:path placeholder:PATH help:"Absolute path to the log file"`
+	Log   string `hidden type:path placeholder:PATH help:"Absolute path to the log file"`
+	Watch bool   `help:"Keep the index up to date by watching the notebook files."`
 }
 
 func (cmd *LSP) Run(container *cli.Container) error {
@@ -19,6 +20,7 @@ func (cmd *LSP) Run(container *cli.Container) error {
 		LogFile:   opt.NewNotEmptyString(cmd.Log),
 		Notebooks: container.Notebooks,
 		FS:        container.FS,
+		Watch:     cmd.Watch,
 	})
 
 	return server.Run()
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mickael-menu/zk/internal/cli"
+	"github.com/mickael-menu/zk/internal/util/strings"
+)
+
+// Move renames or moves a note, updating the links pointing to it.
+type Move struct {
+	Source string `arg placeholder:PATH help:"Path to the note to move."`
+	Dest   string `arg placeholder:PATH help:"Destination path of the note, or an existing directory."`
+	DryRun bool   `short:n help:"Print the link edits without moving the note."`
+	Quiet  bool   `short:q help:"Do not print the link edits."`
+}
+
+func (cmd *Move) Run(container *cli.Container) error {
+	notebook, err := container.CurrentNotebook()
+	if err != nil {
+		return err
+	}
+
+	move, err := notebook.PlanNoteMove(cmd.Source, cmd.Dest)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.Quiet || cmd.DryRun {
+		for _, edit := range move.Edits {
+			fmt.Printf("%s: %s -> %s\n", edit.Path, edit.OldText, edit.NewText)
+		}
+	}
+
+	count := len(move.Edits)
+	summary := fmt.Sprintf("%s to %s, updating %d %s", move.From, move.To, count, strings.Pluralize("link", count))
+
+	if cmd.DryRun {
+		fmt.Fprintf(os.Stderr, "\nWould move %s\n", summary)
+		return nil
+	}
+
+	err = notebook.MoveNote(move)
+	if err != nil {
+		return err
+	}
+
+	// Reindex the notes whose links were edited.
+	_, err = notebook.Index(false)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.Quiet {
+		fmt.Fprintf(os.Stderr, "\nMoved %s\n", summary)
+	}
+
+	return nil
+}
@@ -16,6 +16,7 @@ type New struct {
 	Directory string            `arg optional default:"." help:"Directory in which to create the note."`
 	Title     string            `short:t   placeholder:TITLE help:"Title of the new note."`
 	Group     string            `short:g   placeholder:NAME  help:"Name of the config group this note belongs to. Takes precedence over the config of the directory."`
+	Parent    string            `          placeholder:ID    help:"Path or ID of the parent note, used to generate Folgezettel IDs."`
 	Extra     map[string]string `                            help:"Extra variables passed to the templates." mapsep:","`
 	Template  string            `          placeholder:PATH  help:"Custom template used to render the note."`
 	PrintPath bool              `short:p                     help:"Print the path of the created note instead of editing it."`
@@ -32,14 +33,22 @@ func (cmd *New) Run(container *cli.Container) error {
 		return err
 	}
 
+	// Prompt for the missing template variables, unless --no-input is set.
+	var promptVariable core.TemplateVariablePrompter
+	if container.Terminal.IsInteractive() {
+		promptVariable = container.Terminal.PromptTemplateVariable
+	}
+
 	path, err := notebook.NewNote(core.NewNoteOpts{
-		Title:     opt.NewNotEmptyString(cmd.Title),
-		Content:   content.Unwrap(),
-		Directory: opt.NewNotEmptyString(cmd.Directory),
-		Group:     opt.NewNotEmptyString(cmd.Group),
-		Template:  opt.NewNotEmptyString(cmd.Template),
-		Extra:     cmd.Extra,
-		Date:      time.Now(),
+		Title:          opt.NewNotEmptyString(cmd.Title),
+		Content:        content.Unwrap(),
+		Directory:      opt.NewNotEmptyString(cmd.Directory),
+		Group:          opt.NewNotEmptyString(cmd.Group),
+		Parent:         opt.NewNotEmptyString(cmd.Parent),
+		Template:       opt.NewNotEmptyString(cmd.Template),
+		Extra:          cmd.Extra,
+		PromptVariable: promptVariable,
+		Date:           time.Now(),
 	})
 	if err != nil {
 		var noteExists core.ErrNoteExists
@@ -47,7 +56,7 @@ func (cmd *New) Run(container *cli.Container) error {
 			return err
 		}
 
-		relPath, err := notebook.RelPath(path)
+		relPath, err := notebook.RelPath(noteExists.Path)
 		if err != nil {
 			return err
 		}
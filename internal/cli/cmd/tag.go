@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mickael-menu/zk/internal/cli"
+	"github.com/mickael-menu/zk/internal/core"
+	"github.com/mickael-menu/zk/internal/util/errors"
+	"github.com/mickael-menu/zk/internal/util/strings"
+)
+
+// Tag manages the tags of the notebook.
+type Tag struct {
+	List   TagList   `cmd default:"withargs" help:"List the tags found in the notebook."`
+	Rename TagRename `cmd help:"Rename a tag in all the notes."`
+	Merge  TagMerge  `cmd help:"Merge several tags into a single one."`
+	Delete TagDelete `cmd help:"Remove tags from all the notes."`
+}
+
+// TagList lists the tags of the notebook.
+type TagList struct {
+	Format  string   `group:format short:f placeholder:TEMPLATE help:"Pretty print the list using a custom template or one of the predefined formats: name, full, json, jsonl."`
+	Sort    []string `group:sort short:s placeholder:TERM help:"Order the tags by the given criterion."`
+	NoPager bool     `group:format short:P help:"Do not pipe output into a pager."`
+	Quiet   bool     `group:format short:q help:"Do not print the total number of tags found."`
+}
+
+func (cmd *TagList) Run(container *cli.Container) error {
+	notebook, err := container.CurrentNotebook()
+	if err != nil {
+		return err
+	}
+
+	sorters, err := core.CollectionSortersFromStrings(cmd.Sort)
+	if err != nil {
+		return err
+	}
+
+	format, err := notebook.NewCollectionFormatter(cmd.tagTemplate())
+	if err != nil {
+		return err
+	}
+
+	tags, err := notebook.FindCollections(core.CollectionKindTag, sorters)
+	if err != nil {
+		return err
+	}
+
+	var delimiter, header, footer string
+	switch cmd.Format {
+	case "json":
+		delimiter = ","
+		header = "["
+		footer = "]\n"
+	default:
+		delimiter = "\n"
+		footer = "\n"
+	}
+
+	count := len(tags)
+	if count > 0 {
+		err = container.Paginate(cmd.NoPager, func(out io.Writer) error {
+			fmt.Fprint(out, header)
+			for i, tag := range tags {
+				if i > 0 {
+					fmt.Fprint(out, delimiter)
+				}
+
+				ft, err := format(tag)
+				if err != nil {
+					return err
+				}
+				fmt.Fprint(out, ft)
+			}
+			fmt.Fprint(out, footer)
+
+			return nil
+		})
+	}
+
+	if err == nil && !cmd.Quiet {
+		fmt.Fprintf(os.Stderr, "\nFound %d %s\n", count, strings.Pluralize("tag", count))
+	}
+
+	return err
+}
+
+func (cmd *TagList) tagTemplate() string {
+	format := cmd.Format
+	if format == "" {
+		format = "full"
+	}
+
+	templ, ok := defaultTagFormats[format]
+	if !ok {
+		templ = strings.ExpandWhitespaceLiterals(format)
+	}
+
+	return templ
+}
+
+var defaultTagFormats = map[string]string{
+	"json":  `{{json .}}`,
+	"jsonl": `{{json .}}`,
+	"name":  `{{name}}`,
+	"full":  `{{style "title" name}} ({{note-count}})`,
+}
+
+// TagRename renames a tag across the notebook.
+type TagRename struct {
+	Old    string `arg help:"Current name of the tag."`
+	New    string `arg help:"New name of the tag."`
+	DryRun bool   `short:n help:"Print the notes which would be modified, without modifying them."`
+	Quiet  bool   `short:q help:"Do not print the modified notes."`
+}
+
+func (cmd *TagRename) Run(container *cli.Container) error {
+	notebook, err := container.CurrentNotebook()
+	if err != nil {
+		return err
+	}
+
+	tags, err := findTagNames(notebook)
+	if err != nil {
+		return err
+	}
+	if !tags[cmd.Old] {
+		return fmt.Errorf("%s: tag not found", cmd.Old)
+	}
+	if cmd.New == "" {
+		return errors.New("the new tag name can't be empty")
+	}
+	if tags[cmd.New] {
+		return fmt.Errorf("%s: tag already exists, use `zk tag merge %s %s` instead", cmd.New, cmd.Old, cmd.New)
+	}
+
+	return rewriteTags(notebook, map[string]string{cmd.Old: cmd.New}, cmd.DryRun, cmd.Quiet,
+		fmt.Sprintf("tag %s to %s", cmd.Old, cmd.New),
+	)
+}
+
+// TagMerge merges several tags into a single one.
+type TagMerge struct {
+	Tags   []string `arg help:"Tags to merge, followed by the tag they are merged into."`
+	DryRun bool     `short:n help:"Print the notes which would be modified, without modifying them."`
+	Quiet  bool     `short:q help:"Do not print the modified notes."`
+}
+
+func (cmd *TagMerge) Run(container *cli.Container) error {
+	if len(cmd.Tags) < 2 {
+		return errors.New("at least two tags are required to merge")
+	}
+	sources := cmd.Tags[:len(cmd.Tags)-1]
+	target := cmd.Tags[len(cmd.Tags)-1]
+	if target == "" {
+		return errors.New("the target tag name can't be empty")
+	}
+
+	notebook, err := container.CurrentNotebook()
+	if err != nil {
+		return err
+	}
+
+	tags, err := findTagNames(notebook)
+	if err != nil {
+		return err
+	}
+	mapping := map[string]string{}
+	for _, source := range sources {
+		if !tags[source] {
+			return fmt.Errorf("%s: tag not found", source)
+		}
+		if source != target {
+			mapping[source] = target
+		}
+	}
+
+	return rewriteTags(notebook, mapping, cmd.DryRun, cmd.Quiet,
+		fmt.Sprintf("%s into %s", strings.Pluralize("tag", len(sources)), target),
+	)
+}
+
+// TagDelete removes tags from all the notes.
+type TagDelete struct {
+	Tags   []string `arg help:"Tags to remove."`
+	DryRun bool     `short:n help:"Print the notes which would be modified, without modifying them."`
+	Quiet  bool     `short:q help:"Do not print the modified notes."`
+}
+
+func (cmd *TagDelete) Run(container *cli.Container) error {
+	notebook, err := container.CurrentNotebook()
+	if err != nil {
+		return err
+	}
+
+	tags, err := findTagNames(notebook)
+	if err != nil {
+		return err
+	}
+	mapping := map[string]string{}
+	for _, tag := range cmd.Tags {
+		if !tags[tag] {
+			return fmt.Errorf("%s: tag not found", tag)
+		}
+		mapping[tag] = ""
+	}
+
+	return rewriteTags(notebook, mapping, cmd.DryRun, cmd.Quiet,
+		strings.Pluralize("tag", len(cmd.Tags)),
+	)
+}
+
+// findTagNames returns the set of tag names found in the notebook.
+func findTagNames(notebook *core.Notebook) (map[string]bool, error) {
+	tags, err := notebook.FindCollections(core.CollectionKindTag, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, tag := range tags {
+		names[tag.Name] = true
+	}
+	return names, nil
+}
+
+// rewriteTags applies the given tag mapping to the notes of the notebook and
+// reindexes them.
+func rewriteTags(notebook *core.Notebook, mapping map[string]string, dryRun bool, quiet bool, summary string) error {
+	paths, err := notebook.RewriteTags(mapping, dryRun)
+	if err != nil {
+		return err
+	}
+
+	if !quiet || dryRun {
+		for _, path := range paths {
+			fmt.Println(path)
+		}
+	}
+
+	count := len(paths)
+	notes := fmt.Sprintf("%d %s", count, strings.Pluralize("note", count))
+
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "\nWould update %s in %s\n", summary, notes)
+		return nil
+	}
+
+	// Reindex the modified notes.
+	_, err = notebook.Index(false)
+	if err != nil {
+		return err
+	}
+
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "\nUpdated %s in %s\n", summary, notes)
+	}
+	return nil
+}
@@ -87,13 +87,16 @@ func NewContainer(version string) (*Container, error) {
 					return nil, err
 				}
 
+				parser := markdown.NewParser(markdown.ParserOpts{
+					HashtagEnabled:      config.Format.Markdown.Hashtags,
+					MultiWordTagEnabled: config.Format.Markdown.MultiwordTags,
+					ColontagEnabled:     config.Format.Markdown.ColonTags,
+				})
+
 				notebook := core.NewNotebook(path, config, core.NotebookPorts{
-					NoteIndex: sqlite.NewNoteIndex(db, logger),
-					NoteParser: markdown.NewParser(markdown.ParserOpts{
-						HashtagEnabled:      config.Format.Markdown.Hashtags,
-						MultiWordTagEnabled: config.Format.Markdown.MultiwordTags,
-						ColontagEnabled:     config.Format.Markdown.ColonTags,
-					}),
+					NoteIndex:   sqlite.NewNoteIndex(db, logger),
+					NoteParser:  parser,
+					TagRewriter: parser,
 					TemplateLoaderFactory: func(language string) (core.TemplateLoader, error) {
 						loader := handlebars.NewLoader(handlebars.LoaderOpts{
 							LookupPaths: []string{
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/alecthomas/kong"
@@ -23,6 +24,7 @@ type Filtering struct {
 	ExactMatch     bool     `group:filter short:e                     help:"Search for exact occurrences of the --match argument (case insensitive)."`
 	Exclude        []string `group:filter short:x   placeholder:PATH  help:"Ignore notes matching the given path, including its descendants."`
 	Tag            []string `group:filter short:t                     help:"Find notes tagged with the given tags."`
+	Meta           []string `group:filter           placeholder:PREDICATE help:"Find notes whose YAML frontmatter matches the given predicate, e.g. status=draft, priority>2 or -archived."`
 	Mention        []string `group:filter           placeholder:PATH  help:"Find notes mentioning the title of the given ones."`
 	MentionedBy    []string `group:filter           placeholder:PATH  help:"Find notes whose title is mentioned in the given ones."`
 	LinkTo         []string `group:filter short:l   placeholder:PATH  help:"Find notes which are linking to the given ones."`
@@ -30,6 +32,10 @@ type Filtering struct {
 	LinkedBy       []string `group:filter short:L   placeholder:PATH  help:"Find notes which are linked by the given ones."`
 	NoLinkedBy     []string `group:filter           placeholder:PATH  help:"Find notes which are not linked by the given ones."`
 	Orphan         bool     `group:filter                             help:"Find notes which are not linked by any other note."`
+	MinBacklinks   string   `group:filter           placeholder:COUNT help:"Find notes having at least the given number of links from other notes."`
+	MaxBacklinks   string   `group:filter           placeholder:COUNT help:"Find notes having at most the given number of links from other notes."`
+	MinLinks       string   `group:filter           placeholder:COUNT help:"Find notes having at least the given number of internal links."`
+	MaxLinks       string   `group:filter           placeholder:COUNT help:"Find notes having at most the given number of internal links."`
 	Related        []string `group:filter           placeholder:PATH  help:"Find notes which might be related to the given ones."`
 	MaxDistance    int      `group:filter           placeholder:COUNT help:"Maximum distance between two linked notes."`
 	Recursive      bool     `group:filter short:r                     help:"Follow links recursively."`
@@ -75,6 +81,7 @@ func (f Filtering) ExpandNamedFilters(filters map[string]string, expandedFilters
 			actualPaths = append(actualPaths, parsedFilter.Path...)
 			f.Exclude = append(f.Exclude, parsedFilter.Exclude...)
 			f.Tag = append(f.Tag, parsedFilter.Tag...)
+			f.Meta = append(f.Meta, parsedFilter.Meta...)
 			f.Mention = append(f.Mention, parsedFilter.Mention...)
 			f.MentionedBy = append(f.MentionedBy, parsedFilter.MentionedBy...)
 			f.LinkTo = append(f.LinkTo, parsedFilter.LinkTo...)
@@ -95,6 +102,18 @@ func (f Filtering) ExpandNamedFilters(filters map[string]string, expandedFilters
 			if f.MaxDistance == 0 {
 				f.MaxDistance = parsedFilter.MaxDistance
 			}
+			if f.MinBacklinks == "" {
+				f.MinBacklinks = parsedFilter.MinBacklinks
+			}
+			if f.MaxBacklinks == "" {
+				f.MaxBacklinks = parsedFilter.MaxBacklinks
+			}
+			if f.MinLinks == "" {
+				f.MinLinks = parsedFilter.MinLinks
+			}
+			if f.MaxLinks == "" {
+				f.MaxLinks = parsedFilter.MaxLinks
+			}
 			if f.Created == "" {
 				f.Created = parsedFilter.Created
 			}
@@ -153,6 +172,13 @@ func (f Filtering) NewNoteFindOpts(notebook *core.Notebook) (core.NoteFindOpts,
 		opts.Tags = f.Tag
 	}
 
+	if len(f.Meta) > 0 {
+		opts.Metadata, err = core.MetadataFiltersFromStrings(f.Meta)
+		if err != nil {
+			return opts, err
+		}
+	}
+
 	if len(f.Mention) > 0 {
 		opts.Mention = f.Mention
 	}
@@ -195,6 +221,26 @@ func (f Filtering) NewNoteFindOpts(notebook *core.Notebook) (core.NoteFindOpts,
 
 	opts.Orphan = f.Orphan
 
+	for _, count := range []struct {
+		flag  string
+		value string
+		opt   **int
+	}{
+		{"--min-backlinks", f.MinBacklinks, &opts.MinBacklinks},
+		{"--max-backlinks", f.MaxBacklinks, &opts.MaxBacklinks},
+		{"--min-links", f.MinLinks, &opts.MinLinks},
+		{"--max-links", f.MaxLinks, &opts.MaxLinks},
+	} {
+		if count.value == "" {
+			continue
+		}
+		value, err := strconv.Atoi(count.value)
+		if err != nil || value < 0 {
+			return opts, fmt.Errorf("%s: expected a non-negative number of links, got %s", count.flag, count.value)
+		}
+		*count.opt = &value
+	}
+
 	if f.Created != "" {
 		start, end, err := parseDayRange(f.Created)
 		if err != nil {
@@ -14,6 +14,7 @@ func TestExpandNamedFiltersNone(t *testing.T) {
 		Match:          "match query",
 		Exclude:        []string{"excl-path1", "excl-path2"},
 		Tag:            []string{"tag1", "tag2"},
+		Meta:           []string{"status=draft", "-archived"},
 		Mention:        []string{"mention1", "mention2"},
 		MentionedBy:    []string{"note1", "note2"},
 		LinkTo:         []string{"link1", "link2"},
@@ -22,6 +23,8 @@ func TestExpandNamedFiltersNone(t *testing.T) {
 		NoLinkedBy:     []string{"linked3", "linked4"},
 		Related:        []string{"related1", "related2"},
 		MaxDistance:    2,
+		MinBacklinks:   "1",
+		MaxLinks:       "0",
 		Created:        "yesterday",
 		CreatedBefore:  "two days ago",
 		CreatedAfter:   "three days ago",
@@ -49,6 +52,7 @@ func TestExpandNamedFiltersJoinLists(t *testing.T) {
 		Path:        []string{"path1", "f1", "f2"},
 		Exclude:     []string{"excl-path1", "excl-path2"},
 		Tag:         []string{"tag1", "tag2"},
+		Meta:        []string{"status=draft"},
 		Mention:     []string{"mention1", "mention2"},
 		MentionedBy: []string{"note1", "note2"},
 		LinkTo:      []string{"link1", "link2"},
@@ -61,7 +65,7 @@ func TestExpandNamedFiltersJoinLists(t *testing.T) {
 
 	res, err := f.ExpandNamedFilters(
 		map[string]string{
-			"f1": "path2 --exclude excl-path3 -x excl-path4 --tag tag3 -t tag4 --mention mention3,mention4 --mentioned-by note3",
+			"f1": "path2 --exclude excl-path3 -x excl-path4 --tag tag3 -t tag4 --meta 'priority>2' --mention mention3,mention4 --mentioned-by note3",
 			"f2": "--link-to link5 --no-link-to link6 --linked-by linked5 --no-linked-by linked6 --related related3 --related related4 --sort random-",
 		},
 		[]string{},
@@ -71,6 +75,7 @@ func TestExpandNamedFiltersJoinLists(t *testing.T) {
 	assert.Equal(t, res.Path, []string{"path1", "path2"})
 	assert.Equal(t, res.Exclude, []string{"excl-path1", "excl-path2", "excl-path3", "excl-path4"})
 	assert.Equal(t, res.Tag, []string{"tag1", "tag2", "tag3", "tag4"})
+	assert.Equal(t, res.Meta, []string{"status=draft", "priority>2"})
 	assert.Equal(t, res.Mention, []string{"mention1", "mention2", "mention3", "mention4"})
 	assert.Equal(t, res.MentionedBy, []string{"note1", "note2", "note3"})
 	assert.Equal(t, res.LinkTo, []string{"link1", "link2", "link5"})
@@ -107,13 +112,17 @@ func TestExpandNamedFiltersJoinLitterals(t *testing.T) {
 	f1 := Filtering{Path: []string{"f1", "f2"}}
 	res1, err := f1.ExpandNamedFilters(
 		map[string]string{
-			"f1": "--limit 42 --created 'yesterday' --created-before '2 days ago' --created-after '3 days ago'",
-			"f2": "--max-distance 24 --modified 'tomorrow' --modified-before '2 days' --modified-after '3 days'",
+			"f1": "--limit 42 --min-backlinks 2 --max-backlinks 8 --created 'yesterday' --created-before '2 days ago' --created-after '3 days ago'",
+			"f2": "--max-distance 24 --min-links 1 --max-links 5 --modified 'tomorrow' --modified-before '2 days' --modified-after '3 days'",
 		},
 		[]string{},
 	)
 	assert.Nil(t, err)
 	assert.Equal(t, res1.Limit, 42)
+	assert.Equal(t, res1.MinBacklinks, "2")
+	assert.Equal(t, res1.MaxBacklinks, "8")
+	assert.Equal(t, res1.MinLinks, "1")
+	assert.Equal(t, res1.MaxLinks, "5")
 	assert.Equal(t, res1.MaxDistance, 24)
 	assert.Equal(t, res1.Created, "yesterday")
 	assert.Equal(t, res1.CreatedBefore, "2 days ago")
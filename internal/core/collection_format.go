@@ -0,0 +1,21 @@
+package core
+
+// CollectionFormatter formats collections, such as tags, to be printed on the
+// screen.
+type CollectionFormatter func(collection Collection) (string, error)
+
+func newCollectionFormatter(template Template) (CollectionFormatter, error) {
+	return func(collection Collection) (string, error) {
+		return template.Render(collectionFormatRenderContext{
+			Name:      collection.Name,
+			NoteCount: collection.NoteCount,
+		})
+	}, nil
+}
+
+// collectionFormatRenderContext holds the variables available to the
+// collection formatting templates.
+type collectionFormatRenderContext struct {
+	Name      string `json:"name"`
+	NoteCount int    `json:"noteCount" handlebars:"note-count"`
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+)
+
+func TestCollectionSorterFromString(t *testing.T) {
+	test := func(str string, expectedField CollectionSortField, expectedAscending bool) {
+		actual, err := CollectionSorterFromString(str)
+		assert.Nil(t, err)
+		assert.Equal(t, actual, CollectionSorter{Field: expectedField, Ascending: expectedAscending})
+	}
+
+	test("n", CollectionSortName, true)
+	test("name", CollectionSortName, true)
+	test("name-", CollectionSortName, false)
+
+	test("nc", CollectionSortNoteCount, false)
+	test("note-count", CollectionSortNoteCount, false)
+	test("note-count+", CollectionSortNoteCount, true)
+
+	_, err := CollectionSorterFromString("foobar")
+	assert.Err(t, err, "foobar: unknown sorting term")
+}
+
+func TestCollectionSortersFromStrings(t *testing.T) {
+	test := func(strs []string, expected []CollectionSorter) {
+		actual, err := CollectionSortersFromStrings(strs)
+		assert.Nil(t, err)
+		assert.Equal(t, actual, expected)
+	}
+
+	test([]string{}, []CollectionSorter{})
+
+	// It is parsed in reverse order to be able to override sort criteria set
+	// in aliases.
+	test([]string{"name", "nc+"}, []CollectionSorter{
+		{Field: CollectionSortNoteCount, Ascending: true},
+		{Field: CollectionSortName, Ascending: true},
+	})
+
+	_, err := CollectionSortersFromStrings([]string{"name", "foobar"})
+	assert.Err(t, err, "foobar: unknown sorting term")
+}
+
+func TestNewCollectionFormatter(t *testing.T) {
+	test := formatTest{format: "format"}
+	test.setup()
+
+	notebook := NewNotebook(test.rootDir, test.config, NotebookPorts{
+		TemplateLoaderFactory: func(language string) (TemplateLoader, error) {
+			return test.templateLoader, nil
+		},
+		FS: test.fs,
+	})
+	formatter, err := notebook.NewCollectionFormatter("format")
+	assert.Nil(t, err)
+
+	res, err := formatter(Collection{ID: 1, Kind: CollectionKindTag, Name: "fiction", NoteCount: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, res, "format")
+
+	assert.Equal(t, test.template.Contexts, []interface{}{
+		collectionFormatRenderContext{Name: "fiction", NoteCount: 3},
+	})
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/mickael-menu/zk/internal/util/errors"
@@ -20,6 +21,8 @@ type Config struct {
 	Filters map[string]string
 	Aliases map[string]string
 	Extra   map[string]string
+	// Variables expected by the templates, prompted when missing.
+	Variables map[string]TemplateVariable
 }
 
 // NewDefaultConfig creates a new Config with the default settings.
@@ -32,11 +35,14 @@ func NewDefaultConfig() Config {
 			Lang:             "en",
 			DefaultTitle:     "Untitled",
 			IDOptions: IDOptions{
+				Kind:    IDKindRandom,
 				Charset: CharsetAlphanum,
 				Length:  4,
 				Case:    CaseLower,
+				Layout:  "200601021504",
 			},
-			Ignore: []string{},
+			Ignore:     []string{},
+			OnConflict: NoteConflictAsk,
 		},
 		Groups: map[string]GroupConfig{},
 		Format: FormatConfig{
@@ -51,8 +57,16 @@ func NewDefaultConfig() Config {
 		},
 		LSP: LSPConfig{
 			Diagnostics: LSPDiagnosticConfig{
-				WikiTitle: LSPDiagnosticNone,
-				DeadLink:  LSPDiagnosticError,
+				WikiTitle:    LSPDiagnosticNone,
+				DeadLink:     LSPDiagnosticError,
+				BrokenAnchor: LSPDiagnosticNone,
+				Orphan:       LSPDiagnosticNone,
+				MissingTitle: LSPDiagnosticNone,
+				UnknownTag:   LSPDiagnosticNone,
+				SelfLink:     LSPDiagnosticNone,
+			},
+			CodeLens: LSPCodeLensConfig{
+				Backlinks: true,
 			},
 		},
 		Filters: map[string]string{},
@@ -64,9 +78,10 @@ func NewDefaultConfig() Config {
 // RootGroupConfig returns the default GroupConfig for the root directory and its descendants.
 func (c Config) RootGroupConfig() GroupConfig {
 	return GroupConfig{
-		Paths: []string{},
-		Note:  c.Note,
-		Extra: c.Extra,
+		Paths:     []string{},
+		Note:      c.Note,
+		Extra:     c.Extra,
+		Variables: c.Variables,
 	}
 }
 
@@ -94,6 +109,23 @@ func (c Config) GroupConfigNamed(name string) (GroupConfig, error) {
 	}
 }
 
+// JournalGroup returns the name and config of the group declaring the given
+// journal period.
+func (c Config) JournalGroup(period JournalPeriod) (string, GroupConfig, error) {
+	names := []string{}
+	for name := range c.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if group := c.Groups[name]; group.Period == period {
+			return name, group, nil
+		}
+	}
+	return "", GroupConfig{}, fmt.Errorf("no group with the %s period found in the config", period)
+}
+
 // GroupNameForPath returns the name of the GroupConfig matching the given
 // path, relative to the notebook.
 func (c Config) GroupNameForPath(path string) (string, error) {
@@ -149,12 +181,40 @@ type ToolConfig struct {
 // LSPConfig holds the Language Server Protocol configuration.
 type LSPConfig struct {
 	Diagnostics LSPDiagnosticConfig
+	CodeLens    LSPCodeLensConfig
+	InlayHints  LSPInlayHintConfig
+}
+
+// LSPCodeLensConfig holds the LSP code lenses configuration.
+type LSPCodeLensConfig struct {
+	// Backlinks shows the number of backlinks at the top of each note.
+	Backlinks bool
+}
+
+// LSPInlayHintConfig holds the LSP inlay hints configuration.
+type LSPInlayHintConfig struct {
+	// LinkTitle shows the title of the target note after the links using a
+	// bare ID, e.g. [[a3f9]].
+	LinkTitle bool
 }
 
 // LSPDiagnosticConfig holds the LSP diagnostics configuration.
 type LSPDiagnosticConfig struct {
+	// WikiTitle reports the title of the notes targeted by wiki-links.
 	WikiTitle LSPDiagnosticSeverity
-	DeadLink  LSPDiagnosticSeverity
+	// DeadLink reports links to missing notes.
+	DeadLink LSPDiagnosticSeverity
+	// BrokenAnchor reports links to missing #sections of existing notes.
+	BrokenAnchor LSPDiagnosticSeverity
+	// Orphan reports notes which are not linked by any other note.
+	Orphan LSPDiagnosticSeverity
+	// MissingTitle reports notes without any title.
+	MissingTitle LSPDiagnosticSeverity
+	// UnknownTag reports tags which are not used by any other note, most
+	// likely typos.
+	UnknownTag LSPDiagnosticSeverity
+	// SelfLink reports links pointing to their own note.
+	SelfLink LSPDiagnosticSeverity
 }
 
 type LSPDiagnosticSeverity int
@@ -183,6 +243,43 @@ type NoteConfig struct {
 	IDOptions IDOptions
 	// Path globs to ignore when indexing notes.
 	Ignore []string
+	// Policy applied when the filename of a new note is already taken.
+	OnConflict NoteConflictPolicy
+}
+
+// NoteConflictPolicy defines what to do when a new note can't be created
+// because its filename is already taken.
+type NoteConflictPolicy string
+
+const (
+	// NoteConflictAsk reports the conflict, to let the user decide.
+	NoteConflictAsk NoteConflictPolicy = "ask"
+	// NoteConflictOpen uses the existing note instead.
+	NoteConflictOpen NoteConflictPolicy = "open"
+	// NoteConflictSuffix appends a -2, -3, etc. suffix to the filename.
+	NoteConflictSuffix NoteConflictPolicy = "suffix"
+	// NoteConflictAppend appends the rendered content to the existing note.
+	NoteConflictAppend NoteConflictPolicy = "append"
+)
+
+var NoteConflictPolicies = []NoteConflictPolicy{
+	NoteConflictAsk, NoteConflictOpen, NoteConflictSuffix, NoteConflictAppend,
+}
+
+// NoteConflictPolicyFromString returns the conflict policy matching the
+// given name.
+func NoteConflictPolicyFromString(str string) (NoteConflictPolicy, error) {
+	for _, policy := range NoteConflictPolicies {
+		if string(policy) == str {
+			return policy, nil
+		}
+	}
+
+	strs := []string{}
+	for _, policy := range NoteConflictPolicies {
+		strs = append(strs, string(policy))
+	}
+	return "", fmt.Errorf("%s: unknown note conflict policy\ntry %s", str, strings.Join(strs, ", "))
 }
 
 // GroupConfig holds the user configuration for a given group of notes.
@@ -190,6 +287,10 @@ type GroupConfig struct {
 	Paths []string
 	Note  NoteConfig
 	Extra map[string]string
+	// Variables expected by the templates, prompted when missing.
+	Variables map[string]TemplateVariable
+	// Period covered by the journal notes of this group, if any.
+	Period JournalPeriod
 }
 
 // IgnoreGlobs returns all the Note.Ignore path globs for the group paths,
@@ -219,6 +320,13 @@ func (c GroupConfig) Clone() GroupConfig {
 	for k, v := range c.Extra {
 		clone.Extra[k] = v
 	}
+
+	if c.Variables != nil {
+		clone.Variables = make(map[string]TemplateVariable)
+		for k, v := range c.Variables {
+			clone.Variables[k] = v
+		}
+	}
 	return clone
 }
 
@@ -266,6 +374,15 @@ func ParseConfig(content []byte, path string, parentConfig Config) (Config, erro
 	if note.Template != "" {
 		config.Note.BodyTemplatePath = opt.NewNotEmptyString(note.Template)
 	}
+	if note.IDKind != "" {
+		config.Note.IDOptions.Kind, err = IDKindFromString(note.IDKind)
+		if err != nil {
+			return config, wrap(err)
+		}
+	}
+	if note.IDLayout != "" {
+		config.Note.IDOptions.Layout = note.IDLayout
+	}
 	if note.IDLength != 0 {
 		config.Note.IDOptions.Length = note.IDLength
 	}
@@ -284,11 +401,23 @@ func ParseConfig(content []byte, path string, parentConfig Config) (Config, erro
 	for _, v := range note.Ignore {
 		config.Note.Ignore = append(config.Note.Ignore, v)
 	}
+	if note.OnConflict != "" {
+		config.Note.OnConflict, err = NoteConflictPolicyFromString(note.OnConflict)
+		if err != nil {
+			return config, wrap(err)
+		}
+	}
 	if tomlConf.Extra != nil {
 		for k, v := range tomlConf.Extra {
 			config.Extra[k] = v
 		}
 	}
+	if len(tomlConf.Variables) > 0 {
+		config.Variables, err = mergeTemplateVariables(config.Variables, tomlConf.Variables)
+		if err != nil {
+			return config, wrap(err)
+		}
+	}
 
 	// Groups
 	for name, dirTOML := range tomlConf.Groups {
@@ -297,7 +426,10 @@ func ParseConfig(content []byte, path string, parentConfig Config) (Config, erro
 			parent = config.RootGroupConfig()
 		}
 
-		config.Groups[name] = parent.merge(dirTOML, name)
+		config.Groups[name], err = parent.merge(dirTOML, name)
+		if err != nil {
+			return config, wrap(err)
+		}
 	}
 
 	// Format
@@ -343,19 +475,34 @@ func ParseConfig(content []byte, path string, parentConfig Config) (Config, erro
 
 	// LSP
 	lspDiags := tomlConf.LSP.Diagnostics
-	if lspDiags.WikiTitle != nil {
-		config.LSP.Diagnostics.WikiTitle, err = lspDiagnosticSeverityFromString(*lspDiags.WikiTitle)
-		if err != nil {
-			return config, wrap(err)
+	for _, diag := range []struct {
+		value    *string
+		severity *LSPDiagnosticSeverity
+	}{
+		{lspDiags.WikiTitle, &config.LSP.Diagnostics.WikiTitle},
+		{lspDiags.DeadLink, &config.LSP.Diagnostics.DeadLink},
+		{lspDiags.BrokenAnchor, &config.LSP.Diagnostics.BrokenAnchor},
+		{lspDiags.Orphan, &config.LSP.Diagnostics.Orphan},
+		{lspDiags.MissingTitle, &config.LSP.Diagnostics.MissingTitle},
+		{lspDiags.UnknownTag, &config.LSP.Diagnostics.UnknownTag},
+		{lspDiags.SelfLink, &config.LSP.Diagnostics.SelfLink},
+	} {
+		if diag.value == nil {
+			continue
 		}
-	}
-	if lspDiags.DeadLink != nil {
-		config.LSP.Diagnostics.DeadLink, err = lspDiagnosticSeverityFromString(*lspDiags.DeadLink)
+		*diag.severity, err = lspDiagnosticSeverityFromString(*diag.value)
 		if err != nil {
 			return config, wrap(err)
 		}
 	}
 
+	if backlinks := tomlConf.LSP.CodeLens.Backlinks; backlinks != nil {
+		config.LSP.CodeLens.Backlinks = *backlinks
+	}
+	if linkTitle := tomlConf.LSP.InlayHints.LinkTitle; linkTitle != nil {
+		config.LSP.InlayHints.LinkTitle = *linkTitle
+	}
+
 	// Filters
 	if tomlConf.Filters != nil {
 		for k, v := range tomlConf.Filters {
@@ -373,7 +520,7 @@ func ParseConfig(content []byte, path string, parentConfig Config) (Config, erro
 	return config, nil
 }
 
-func (c GroupConfig) merge(tomlConf tomlGroupConfig, name string) GroupConfig {
+func (c GroupConfig) merge(tomlConf tomlGroupConfig, name string) (GroupConfig, error) {
 	res := c.Clone()
 
 	if tomlConf.Paths != nil {
@@ -396,6 +543,16 @@ func (c GroupConfig) merge(tomlConf tomlGroupConfig, name string) GroupConfig {
 	if note.Template != "" {
 		res.Note.BodyTemplatePath = opt.NewNotEmptyString(note.Template)
 	}
+	if note.IDKind != "" {
+		kind, err := IDKindFromString(note.IDKind)
+		if err != nil {
+			return res, errors.Wrapf(err, "group %s", name)
+		}
+		res.Note.IDOptions.Kind = kind
+	}
+	if note.IDLayout != "" {
+		res.Note.IDOptions.Layout = note.IDLayout
+	}
 	if note.IDLength != 0 {
 		res.Note.IDOptions.Length = note.IDLength
 	}
@@ -414,25 +571,47 @@ func (c GroupConfig) merge(tomlConf tomlGroupConfig, name string) GroupConfig {
 	for _, v := range note.Ignore {
 		res.Note.Ignore = append(res.Note.Ignore, v)
 	}
+	if note.OnConflict != "" {
+		policy, err := NoteConflictPolicyFromString(note.OnConflict)
+		if err != nil {
+			return res, errors.Wrapf(err, "group %s", name)
+		}
+		res.Note.OnConflict = policy
+	}
 	if tomlConf.Extra != nil {
 		for k, v := range tomlConf.Extra {
 			res.Extra[k] = v
 		}
 	}
+	if len(tomlConf.Variables) > 0 {
+		variables, err := mergeTemplateVariables(res.Variables, tomlConf.Variables)
+		if err != nil {
+			return res, errors.Wrapf(err, "group %s", name)
+		}
+		res.Variables = variables
+	}
+	if tomlConf.Period != "" {
+		period, err := JournalPeriodFromString(tomlConf.Period)
+		if err != nil {
+			return res, errors.Wrapf(err, "group %s", name)
+		}
+		res.Period = period
+	}
 
-	return res
+	return res, nil
 }
 
 // tomlConfig holds the TOML representation of Config
 type tomlConfig struct {
-	Note    tomlNoteConfig
-	Groups  map[string]tomlGroupConfig `toml:"group"`
-	Format  tomlFormatConfig
-	Tool    tomlToolConfig
-	LSP     tomlLSPConfig
-	Extra   map[string]string
-	Filters map[string]string `toml:"filter"`
-	Aliases map[string]string `toml:"alias"`
+	Note      tomlNoteConfig
+	Groups    map[string]tomlGroupConfig `toml:"group"`
+	Format    tomlFormatConfig
+	Tool      tomlToolConfig
+	LSP       tomlLSPConfig
+	Extra     map[string]string
+	Variables map[string]tomlVariableConfig
+	Filters   map[string]string `toml:"filter"`
+	Aliases   map[string]string `toml:"alias"`
 }
 
 type tomlNoteConfig struct {
@@ -441,16 +620,29 @@ type tomlNoteConfig struct {
 	Template     string
 	Lang         string   `toml:"language"`
 	DefaultTitle string   `toml:"default-title"`
+	IDKind       string   `toml:"id-kind"`
+	IDLayout     string   `toml:"id-layout"`
 	IDCharset    string   `toml:"id-charset"`
 	IDLength     int      `toml:"id-length"`
 	IDCase       string   `toml:"id-case"`
 	Ignore       []string `toml:"ignore"`
+	OnConflict   string   `toml:"on-conflict"`
 }
 
 type tomlGroupConfig struct {
-	Paths []string
-	Note  tomlNoteConfig
-	Extra map[string]string
+	Paths     []string
+	Note      tomlNoteConfig
+	Extra     map[string]string
+	Variables map[string]tomlVariableConfig
+	Period    string
+}
+
+type tomlVariableConfig struct {
+	Type        string
+	Description string
+	Default     interface{}
+	Choices     []interface{}
+	Required    bool
 }
 
 type tomlFormatConfig struct {
@@ -475,9 +667,58 @@ type tomlToolConfig struct {
 
 type tomlLSPConfig struct {
 	Diagnostics struct {
-		WikiTitle *string `toml:"wiki-title"`
-		DeadLink  *string `toml:"dead-link"`
+		WikiTitle    *string `toml:"wiki-title"`
+		DeadLink     *string `toml:"dead-link"`
+		BrokenAnchor *string `toml:"broken-anchor"`
+		Orphan       *string `toml:"orphan"`
+		MissingTitle *string `toml:"missing-title"`
+		UnknownTag   *string `toml:"unknown-tag"`
+		SelfLink     *string `toml:"self-link"`
+	}
+	CodeLens struct {
+		Backlinks *bool
+	} `toml:"code-lens"`
+	InlayHints struct {
+		LinkTitle *bool `toml:"link-title"`
+	} `toml:"inlay-hints"`
+}
+
+// mergeTemplateVariables returns the parent variables, overridden by the
+// ones declared in the TOML config.
+func mergeTemplateVariables(parent map[string]TemplateVariable, tomlVariables map[string]tomlVariableConfig) (map[string]TemplateVariable, error) {
+	variables := map[string]TemplateVariable{}
+	for name, variable := range parent {
+		variables[name] = variable
 	}
+
+	for name, tomlVariable := range tomlVariables {
+		variable := TemplateVariable{
+			Type:        TemplateVariableString,
+			Description: tomlVariable.Description,
+			Required:    tomlVariable.Required,
+		}
+		if tomlVariable.Type != "" {
+			t, err := TemplateVariableTypeFromString(tomlVariable.Type)
+			if err != nil {
+				return nil, errors.Wrapf(err, "variable %s", name)
+			}
+			variable.Type = t
+		}
+		if tomlVariable.Default != nil {
+			variable.Default = opt.NewNotEmptyString(fmt.Sprint(tomlVariable.Default))
+		}
+		for _, choice := range tomlVariable.Choices {
+			variable.Choices = append(variable.Choices, fmt.Sprint(choice))
+		}
+		if !variable.Default.IsNull() {
+			if err := variable.Validate(variable.Default.Unwrap()); err != nil {
+				return nil, errors.Wrapf(err, "variable %s: invalid default value", name)
+			}
+		}
+		variables[name] = variable
+	}
+
+	return variables, nil
 }
 
 func charsetFromString(charset string) Charset {
@@ -19,6 +19,8 @@ func TestParseDefaultConfig(t *testing.T) {
 			Extension:        "md",
 			BodyTemplatePath: opt.NullString,
 			IDOptions: IDOptions{
+				Kind:    IDKindRandom,
+				Layout:  "200601021504",
 				Length:  4,
 				Charset: CharsetAlphanum,
 				Case:    CaseLower,
@@ -26,6 +28,7 @@ func TestParseDefaultConfig(t *testing.T) {
 			DefaultTitle: "Untitled",
 			Lang:         "en",
 			Ignore:       []string{},
+			OnConflict:   NoteConflictAsk,
 		},
 		Groups: make(map[string]GroupConfig),
 		Format: FormatConfig{
@@ -46,8 +49,16 @@ func TestParseDefaultConfig(t *testing.T) {
 		},
 		LSP: LSPConfig{
 			Diagnostics: LSPDiagnosticConfig{
-				WikiTitle: LSPDiagnosticNone,
-				DeadLink:  LSPDiagnosticError,
+				WikiTitle:    LSPDiagnosticNone,
+				DeadLink:     LSPDiagnosticError,
+				BrokenAnchor: LSPDiagnosticNone,
+				Orphan:       LSPDiagnosticNone,
+				MissingTitle: LSPDiagnosticNone,
+				UnknownTag:   LSPDiagnosticNone,
+				SelfLink:     LSPDiagnosticNone,
+			},
+			CodeLens: LSPCodeLensConfig{
+				Backlinks: true,
 			},
 		},
 		Filters: make(map[string]string),
@@ -74,7 +85,10 @@ func TestParseComplete(t *testing.T) {
 		id-charset = "alphanum"
 		id-length = 4
 		id-case = "lower"
+		id-kind = "timestamp"
+		id-layout = "20060102150405"
 		ignore = ["ignored", ".git"]
+		on-conflict = "suffix"
 
 		[format.markdown]
 		hashtags = false
@@ -104,6 +118,7 @@ func TestParseComplete(t *testing.T) {
 
 		[group.log]
 		paths = ["journal/daily", "journal/weekly"]
+		period = "daily"
 
 		[group.log.note]
 		filename = "{{date}}.md"
@@ -114,7 +129,9 @@ func TestParseComplete(t *testing.T) {
 		id-charset = "letters"
 		id-length = 8
 		id-case = "mixed"
+		id-kind = "counter"
 		ignore = ["new-ignored"]
+		on-conflict = "open"
 		
 		[group.log.extra]
 		log-ext = "value"
@@ -128,6 +145,17 @@ func TestParseComplete(t *testing.T) {
 		[lsp.diagnostics]
 		wiki-title = "hint"
 		dead-link = "none"
+		broken-anchor = "warning"
+		orphan = "info"
+		missing-title = "warning"
+		unknown-tag = "hint"
+		self-link = "error"
+
+		[lsp.code-lens]
+		backlinks = false
+
+		[lsp.inlay-hints]
+		link-title = true
 	`), ".zk/config.toml", NewDefaultConfig())
 
 	assert.Nil(t, err)
@@ -137,6 +165,8 @@ func TestParseComplete(t *testing.T) {
 			Extension:        "txt",
 			BodyTemplatePath: opt.NewString("default.note"),
 			IDOptions: IDOptions{
+				Kind:    IDKindTimestamp,
+				Layout:  "20060102150405",
 				Length:  4,
 				Charset: CharsetAlphanum,
 				Case:    CaseLower,
@@ -144,6 +174,7 @@ func TestParseComplete(t *testing.T) {
 			Lang:         "fr",
 			DefaultTitle: "Sans titre",
 			Ignore:       []string{"ignored", ".git"},
+			OnConflict:   NoteConflictSuffix,
 		},
 		Groups: map[string]GroupConfig{
 			"log": {
@@ -153,6 +184,8 @@ func TestParseComplete(t *testing.T) {
 					Extension:        "note",
 					BodyTemplatePath: opt.NewString("log.md"),
 					IDOptions: IDOptions{
+						Kind:    IDKindCounter,
+						Layout:  "20060102150405",
 						Length:  8,
 						Charset: CharsetLetters,
 						Case:    CaseMixed,
@@ -160,12 +193,14 @@ func TestParseComplete(t *testing.T) {
 					Lang:         "de",
 					DefaultTitle: "Ohne Titel",
 					Ignore:       []string{"ignored", ".git", "new-ignored"},
+					OnConflict:   NoteConflictOpen,
 				},
 				Extra: map[string]string{
 					"hello":   "world",
 					"salut":   "le monde",
 					"log-ext": "value",
 				},
+				Period: JournalPeriodDaily,
 			},
 			"ref": {
 				Paths: []string{"ref"},
@@ -174,6 +209,8 @@ func TestParseComplete(t *testing.T) {
 					Extension:        "txt",
 					BodyTemplatePath: opt.NewString("default.note"),
 					IDOptions: IDOptions{
+						Kind:    IDKindTimestamp,
+						Layout:  "20060102150405",
 						Length:  4,
 						Charset: CharsetAlphanum,
 						Case:    CaseLower,
@@ -181,6 +218,7 @@ func TestParseComplete(t *testing.T) {
 					Lang:         "fr",
 					DefaultTitle: "Sans titre",
 					Ignore:       []string{"ignored", ".git"},
+					OnConflict:   NoteConflictSuffix,
 				},
 				Extra: map[string]string{
 					"hello": "world",
@@ -194,6 +232,8 @@ func TestParseComplete(t *testing.T) {
 					Extension:        "txt",
 					BodyTemplatePath: opt.NewString("default.note"),
 					IDOptions: IDOptions{
+						Kind:    IDKindTimestamp,
+						Layout:  "20060102150405",
 						Length:  4,
 						Charset: CharsetAlphanum,
 						Case:    CaseLower,
@@ -201,6 +241,7 @@ func TestParseComplete(t *testing.T) {
 					Lang:         "fr",
 					DefaultTitle: "Sans titre",
 					Ignore:       []string{"ignored", ".git"},
+					OnConflict:   NoteConflictSuffix,
 				},
 				Extra: map[string]string{
 					"hello": "world",
@@ -226,8 +267,16 @@ func TestParseComplete(t *testing.T) {
 		},
 		LSP: LSPConfig{
 			Diagnostics: LSPDiagnosticConfig{
-				WikiTitle: LSPDiagnosticHint,
-				DeadLink:  LSPDiagnosticNone,
+				WikiTitle:    LSPDiagnosticHint,
+				DeadLink:     LSPDiagnosticNone,
+				BrokenAnchor: LSPDiagnosticWarning,
+				Orphan:       LSPDiagnosticInfo,
+				MissingTitle: LSPDiagnosticWarning,
+				UnknownTag:   LSPDiagnosticHint,
+				SelfLink:     LSPDiagnosticError,
+			},
+			InlayHints: LSPInlayHintConfig{
+				LinkTitle: true,
 			},
 		},
 		Filters: map[string]string{
@@ -283,6 +332,8 @@ func TestParseMergesGroupConfig(t *testing.T) {
 			Extension:        "txt",
 			BodyTemplatePath: opt.NewString("root-template"),
 			IDOptions: IDOptions{
+				Kind:    IDKindRandom,
+				Layout:  "200601021504",
 				Length:  42,
 				Charset: CharsetLetters,
 				Case:    CaseUpper,
@@ -290,6 +341,7 @@ func TestParseMergesGroupConfig(t *testing.T) {
 			Lang:         "fr",
 			DefaultTitle: "Sans titre",
 			Ignore:       []string{"ignored", ".git"},
+			OnConflict:   NoteConflictAsk,
 		},
 		Groups: map[string]GroupConfig{
 			"log": {
@@ -299,6 +351,8 @@ func TestParseMergesGroupConfig(t *testing.T) {
 					Extension:        "txt",
 					BodyTemplatePath: opt.NewString("log-template"),
 					IDOptions: IDOptions{
+						Kind:    IDKindRandom,
+						Layout:  "200601021504",
 						Length:  8,
 						Charset: CharsetNumbers,
 						Case:    CaseMixed,
@@ -306,6 +360,7 @@ func TestParseMergesGroupConfig(t *testing.T) {
 					Lang:         "fr",
 					DefaultTitle: "Sans titre",
 					Ignore:       []string{"ignored", ".git"},
+					OnConflict:   NoteConflictAsk,
 				},
 				Extra: map[string]string{
 					"hello":   "override",
@@ -320,6 +375,8 @@ func TestParseMergesGroupConfig(t *testing.T) {
 					Extension:        "txt",
 					BodyTemplatePath: opt.NewString("root-template"),
 					IDOptions: IDOptions{
+						Kind:    IDKindRandom,
+						Layout:  "200601021504",
 						Length:  42,
 						Charset: CharsetLetters,
 						Case:    CaseUpper,
@@ -327,6 +384,7 @@ func TestParseMergesGroupConfig(t *testing.T) {
 					Lang:         "fr",
 					DefaultTitle: "Sans titre",
 					Ignore:       []string{"ignored", ".git"},
+					OnConflict:   NoteConflictAsk,
 				},
 				Extra: map[string]string{
 					"hello": "world",
@@ -346,8 +404,16 @@ func TestParseMergesGroupConfig(t *testing.T) {
 		},
 		LSP: LSPConfig{
 			Diagnostics: LSPDiagnosticConfig{
-				WikiTitle: LSPDiagnosticNone,
-				DeadLink:  LSPDiagnosticError,
+				WikiTitle:    LSPDiagnosticNone,
+				DeadLink:     LSPDiagnosticError,
+				BrokenAnchor: LSPDiagnosticNone,
+				Orphan:       LSPDiagnosticNone,
+				MissingTitle: LSPDiagnosticNone,
+				UnknownTag:   LSPDiagnosticNone,
+				SelfLink:     LSPDiagnosticNone,
+			},
+			CodeLens: LSPCodeLensConfig{
+				Backlinks: true,
 			},
 		},
 		Filters: make(map[string]string),
@@ -438,13 +504,23 @@ func TestParseLSPDiagnosticsSeverity(t *testing.T) {
 	test := func(value string, expected LSPDiagnosticSeverity) {
 		toml := fmt.Sprintf(`
 			[lsp.diagnostics]
-			wiki-title = "%s"
-			dead-link = "%s"
-		`, value, value)
+			wiki-title = "%[1]s"
+			dead-link = "%[1]s"
+			broken-anchor = "%[1]s"
+			orphan = "%[1]s"
+			missing-title = "%[1]s"
+			unknown-tag = "%[1]s"
+			self-link = "%[1]s"
+		`, value)
 		conf, err := ParseConfig([]byte(toml), ".zk/config.toml", NewDefaultConfig())
 		assert.Nil(t, err)
 		assert.Equal(t, conf.LSP.Diagnostics.WikiTitle, expected)
 		assert.Equal(t, conf.LSP.Diagnostics.DeadLink, expected)
+		assert.Equal(t, conf.LSP.Diagnostics.BrokenAnchor, expected)
+		assert.Equal(t, conf.LSP.Diagnostics.Orphan, expected)
+		assert.Equal(t, conf.LSP.Diagnostics.MissingTitle, expected)
+		assert.Equal(t, conf.LSP.Diagnostics.UnknownTag, expected)
+		assert.Equal(t, conf.LSP.Diagnostics.SelfLink, expected)
 	}
 
 	test("", LSPDiagnosticNone)
@@ -462,6 +538,120 @@ func TestParseLSPDiagnosticsSeverity(t *testing.T) {
 	assert.Err(t, err, "foobar: unknown LSP diagnostic severity - may be none, hint, info, warning or error")
 }
 
+func TestParseGroupPeriod(t *testing.T) {
+	conf, err := ParseConfig([]byte(`
+		[group.weekly]
+		period = "weekly"
+	`), ".zk/config.toml", NewDefaultConfig())
+	assert.Nil(t, err)
+	assert.Equal(t, conf.Groups["weekly"].Period, JournalPeriodWeekly)
+
+	_, err = ParseConfig([]byte(`
+		[group.log]
+		period = "yearly"
+	`), ".zk/config.toml", NewDefaultConfig())
+	assert.Err(t, err, "group log: yearly: unknown journal period")
+}
+
+func TestParseNoteOnConflict(t *testing.T) {
+	conf, err := ParseConfig([]byte(`
+		[note]
+		on-conflict = "open"
+
+		[group.log.note]
+		on-conflict = "append"
+	`), ".zk/config.toml", NewDefaultConfig())
+	assert.Nil(t, err)
+	assert.Equal(t, conf.Note.OnConflict, NoteConflictOpen)
+	assert.Equal(t, conf.Groups["log"].Note.OnConflict, NoteConflictAppend)
+
+	_, err = ParseConfig([]byte(`
+		[note]
+		on-conflict = "replace"
+	`), ".zk/config.toml", NewDefaultConfig())
+	assert.Err(t, err, "replace: unknown note conflict policy")
+
+	_, err = ParseConfig([]byte(`
+		[group.log.note]
+		on-conflict = "replace"
+	`), ".zk/config.toml", NewDefaultConfig())
+	assert.Err(t, err, "group log: replace: unknown note conflict policy")
+}
+
+func TestParseIDKind(t *testing.T) {
+	test := func(value string, expected IDKind) {
+		conf, err := ParseConfig([]byte(fmt.Sprintf(`
+			[note]
+			id-kind = "%s"
+		`, value)), ".zk/config.toml", NewDefaultConfig())
+		assert.Nil(t, err)
+		assert.Equal(t, conf.Note.IDOptions.Kind, expected)
+	}
+
+	test("random", IDKindRandom)
+	test("counter", IDKindCounter)
+	test("timestamp", IDKindTimestamp)
+	test("ulid", IDKindULID)
+	test("folgezettel", IDKindFolgezettel)
+
+	_, err := ParseConfig([]byte(`
+		[group.log.note]
+		id-kind = "uuid"
+	`), ".zk/config.toml", NewDefaultConfig())
+	assert.Err(t, err, "group log: uuid: unknown ID kind")
+}
+
+func TestParseTemplateVariables(t *testing.T) {
+	conf, err := ParseConfig([]byte(`
+		[variables.owner]
+		description = "Owner"
+		default = "ops"
+
+		[group.incident.variables.severity]
+		type = "number"
+		choices = [1, 2, 3]
+		default = 2
+		required = true
+
+		[group.incident.variables.owner]
+		required = true
+	`), ".zk/config.toml", NewDefaultConfig())
+	assert.Nil(t, err)
+
+	assert.Equal(t, conf.Variables, map[string]TemplateVariable{
+		"owner": {
+			Type:        TemplateVariableString,
+			Description: "Owner",
+			Default:     opt.NewString("ops"),
+		},
+	})
+	assert.Equal(t, conf.Groups["incident"].Variables, map[string]TemplateVariable{
+		"owner": {
+			Type:     TemplateVariableString,
+			Required: true,
+		},
+		"severity": {
+			Type:     TemplateVariableNumber,
+			Default:  opt.NewString("2"),
+			Choices:  []string{"1", "2", "3"},
+			Required: true,
+		},
+	})
+
+	_, err = ParseConfig([]byte(`
+		[group.incident.variables.severity]
+		type = "date"
+	`), ".zk/config.toml", NewDefaultConfig())
+	assert.Err(t, err, "group incident: variable severity: date: unknown variable type")
+
+	_, err = ParseConfig([]byte(`
+		[variables.severity]
+		choices = ["low", "high"]
+		default = "medium"
+	`), ".zk/config.toml", NewDefaultConfig())
+	assert.Err(t, err, "variable severity: invalid default value: medium: invalid choice")
+}
+
 func TestGroupConfigIgnoreGlobs(t *testing.T) {
 	// empty globs
 	config := GroupConfig{
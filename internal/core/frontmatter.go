@@ -0,0 +1,89 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+)
+
+// frontmatterRegex matches a YAML frontmatter at the beginning of a note
+// content, capturing its raw YAML.
+var frontmatterRegex = regexp.MustCompile(`(?s)^\s*---[ \t]*\n(.*?\n)?---[ \t]*(?:\n|$)`)
+
+// frontmatterKeyRegex matches the key of a top-level YAML entry.
+var frontmatterKeyRegex = regexp.MustCompile(`^([^\s#\-][^:]*):(?:\s|$)`)
+
+// splitFrontmatter returns the raw YAML frontmatter of the given note
+// content, and the rest of the content.
+func splitFrontmatter(content string) (frontmatter string, rest string) {
+	loc := frontmatterRegex.FindStringSubmatchIndex(content)
+	if loc == nil {
+		return "", content
+	}
+	if loc[2] >= 0 {
+		frontmatter = content[loc[2]:loc[3]]
+	}
+	return frontmatter, strings.TrimLeft(content[loc[1]:], "\r\n")
+}
+
+// frontmatterEntries splits the given raw YAML frontmatter into its
+// top-level entries, with their nested values and comments. The keys are
+// lowercased, as in the parsed metadata.
+func frontmatterEntries(frontmatter string) (keys []string, entries map[string]string) {
+	entries = map[string]string{}
+
+	key := ""
+	for _, line := range strings.SplitAfter(frontmatter, "\n") {
+		if line == "" {
+			continue
+		}
+		if matches := frontmatterKeyRegex.FindStringSubmatch(line); matches != nil {
+			key = strings.ToLower(strings.TrimSpace(matches[1]))
+			if _, ok := entries[key]; !ok {
+				keys = append(keys, key)
+			}
+			entries[key] = ""
+		}
+		entries[key] += line
+	}
+
+	// Leading comments don't belong to any entry.
+	delete(entries, "")
+	return keys, entries
+}
+
+// mergeFrontmatter adds the top-level entries of the given raw YAML
+// frontmatter which are missing from the frontmatter of the note content.
+// The note gets a new frontmatter if it doesn't have one.
+func mergeFrontmatter(content string, frontmatter string) string {
+	keys, entries := frontmatterEntries(frontmatter)
+	if len(keys) == 0 {
+		return content
+	}
+
+	loc := frontmatterRegex.FindStringSubmatchIndex(content)
+	if loc == nil {
+		merged := ""
+		for _, key := range keys {
+			merged += entries[key]
+		}
+		return "---\n" + merged + "---\n\n" + strings.TrimLeft(content, "\r\n")
+	}
+
+	existing := ""
+	if loc[2] >= 0 {
+		existing = content[loc[2]:loc[3]]
+	}
+	_, existingEntries := frontmatterEntries(existing)
+
+	merged := existing
+	for _, key := range keys {
+		if _, ok := existingEntries[key]; !ok {
+			merged += entries[key]
+		}
+	}
+	if merged == existing {
+		return content
+	}
+
+	return "---\n" + merged + "---\n" + content[loc[1]:]
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+)
+
+func TestSplitFrontmatter(t *testing.T) {
+	test := func(content string, expectedFrontmatter string, expectedRest string) {
+		frontmatter, rest := splitFrontmatter(content)
+		assert.Equal(t, frontmatter, expectedFrontmatter)
+		assert.Equal(t, rest, expectedRest)
+	}
+
+	test("", "", "")
+	test("# Title\nBody", "", "# Title\nBody")
+	test("---\ntitle: Title\ntags: [a, b]\n---\n\n# Title\nBody", "title: Title\ntags: [a, b]\n", "# Title\nBody")
+	test("\n---\nauthor: Alice\n---", "author: Alice\n", "")
+	test("---\n---\nBody", "", "Body")
+	test("Body\n---\nauthor: Alice\n---", "", "Body\n---\nauthor: Alice\n---")
+}
+
+func TestFrontmatterEntries(t *testing.T) {
+	keys, entries := frontmatterEntries(`# Comment
+title: Title
+Tags:
+  - a
+- b
+author: Alice # inline comment
+nested:
+  key: value
+`)
+	assert.Equal(t, keys, []string{"title", "tags", "author", "nested"})
+	assert.Equal(t, entries, map[string]string{
+		"title":  "title: Title\n",
+		"tags":   "Tags:\n  - a\n- b\n",
+		"author": "author: Alice # inline comment\n",
+		"nested": "nested:\n  key: value\n",
+	})
+}
+
+func TestMergeFrontmatter(t *testing.T) {
+	test := func(content string, frontmatter string, expected string) {
+		assert.Equal(t, mergeFrontmatter(content, frontmatter), expected)
+	}
+
+	// Nothing to merge
+	test("# Title\n", "", "# Title\n")
+	test("# Title\n", "# Comment\n", "# Title\n")
+
+	// New frontmatter
+	test("# Title\n\nBody\n", "author: Alice\ntags: [a]\n", "---\nauthor: Alice\ntags: [a]\n---\n\n# Title\n\nBody\n")
+
+	// Merged with the existing frontmatter, which takes precedence.
+	test(
+		"---\ntitle: Template title\ndate: 2009-11-17\n---\n\n# Title\n",
+		"Title: Piped title\nauthor: Alice\ntags:\n  - a\n",
+		"---\ntitle: Template title\ndate: 2009-11-17\nauthor: Alice\ntags:\n  - a\n---\n\n# Title\n",
+	)
+	test(
+		"---\ntitle: Template title\n---\n# Title\n",
+		"title: Piped title\n",
+		"---\ntitle: Template title\n---\n# Title\n",
+	)
+	test("---\n---\nBody", "author: Alice\n", "---\nauthor: Alice\n---\nBody")
+}
@@ -33,4 +33,8 @@ type FileStorage interface {
 	// Write creates or overwrite the content at the given file path, creating
 	// any intermediate directories if needed.
 	Write(path string, content []byte) error
+
+	// Move renames the file at the given source path to the destination path,
+	// creating any intermediate directories if needed.
+	Move(source string, dest string) error
 }
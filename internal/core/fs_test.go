@@ -3,6 +3,7 @@ package core
 import (
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // fileStorageMock implements an in-memory FileStorage for testing purposes.
@@ -67,7 +68,7 @@ func (fs *fileStorageMock) fileInfo(path string) (*os.FileInfo, error) {
 }
 
 func (fs *fileStorageMock) IsDescendantOf(dir string, path string) (bool, error) {
-	panic("not implemented")
+	return path == dir || strings.HasPrefix(path, dir+"/"), nil
 }
 
 func (fs *fileStorageMock) Read(path string) ([]byte, error) {
@@ -79,3 +80,13 @@ func (fs *fileStorageMock) Write(path string, content []byte) error {
 	fs.files[path] = string(content)
 	return nil
 }
+
+func (fs *fileStorageMock) Move(source string, dest string) error {
+	content, ok := fs.files[source]
+	if !ok {
+		return os.ErrNotExist
+	}
+	delete(fs.files, source)
+	fs.files[dest] = content
+	return nil
+}
@@ -1,10 +1,60 @@
 package core
 
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
 // IDOptions holds the options used to generate an ID.
 type IDOptions struct {
+	Kind    IDKind
 	Length  int
 	Charset Charset
 	Case    Case
+	// Go time layout used to format timestamp IDs.
+	Layout string
+}
+
+// IDKind is the scheme used to generate the IDs of new notes.
+type IDKind string
+
+const (
+	// IDKindRandom generates random IDs from a charset.
+	IDKindRandom IDKind = "random"
+	// IDKindCounter generates increasing numbers, persisted in the index.
+	IDKindCounter IDKind = "counter"
+	// IDKindTimestamp generates IDs from the creation date of the notes.
+	IDKindTimestamp IDKind = "timestamp"
+	// IDKindULID generates ULIDs, which are sortable by creation time.
+	// See https://github.com/ulid/spec
+	IDKindULID IDKind = "ulid"
+	// IDKindFolgezettel generates Luhmann-style IDs derived from the ID of a
+	// parent note, e.g. 1a2b.
+	IDKindFolgezettel IDKind = "folgezettel"
+)
+
+var IDKinds = []IDKind{
+	IDKindRandom, IDKindCounter, IDKindTimestamp, IDKindULID, IDKindFolgezettel,
+}
+
+// IDKindFromString returns the ID kind matching the given name.
+func IDKindFromString(str string) (IDKind, error) {
+	for _, kind := range IDKinds {
+		if string(kind) == str {
+			return kind, nil
+		}
+	}
+
+	strs := []string{}
+	for _, kind := range IDKinds {
+		strs = append(strs, string(kind))
+	}
+	return "", fmt.Errorf("%s: unknown ID kind\ntry %s", str, strings.Join(strs, ", "))
 }
 
 // Charset is a set of characters.
@@ -34,4 +84,138 @@ const (
 type IDGenerator func() string
 
 // IDGeneratorFactory creates a new IDGenerator function using the given IDOptions.
+// It is used for the random and ULID kinds of IDs.
 type IDGeneratorFactory func(opts IDOptions) func() string
+
+// maxTimestampShift is the maximum number of seconds a timestamp ID can be
+// moved forward to find a different ID.
+const maxTimestampShift = 24 * 60 * 60
+
+// newTimestampIDGenerator returns a function generating IDs from the given
+// date, formatted with a Go time layout. The date is moved forward until the
+// ID changes with each invocation, to find a free filename.
+func newTimestampIDGenerator(date time.Time, layout string) IDGenerator {
+	last := ""
+	return func() string {
+		id := date.Format(layout)
+		for i := 0; id == last && i < maxTimestampShift; i++ {
+			date = date.Add(time.Second)
+			id = date.Format(layout)
+		}
+		last = id
+		return id
+	}
+}
+
+// counterIDGenerator generates increasing numbers as IDs, from a counter
+// persisted in the index.
+type counterIDGenerator struct {
+	index NoteIndex
+	name  string
+	// Minimum number of digits of the IDs, padded with zeros.
+	length int
+	value  int
+}
+
+func newCounterIDGenerator(index NoteIndex, name string, length int) (*counterIDGenerator, error) {
+	value, err := index.Counter(name)
+	if err != nil {
+		return nil, err
+	}
+	return &counterIDGenerator{
+		index:  index,
+		name:   name,
+		length: length,
+		value:  value,
+	}, nil
+}
+
+func (g *counterIDGenerator) next() string {
+	g.value++
+	return fmt.Sprintf("%0*d", g.length, g.value)
+}
+
+// save persists the last generated value of the counter.
+func (g *counterIDGenerator) save() error {
+	return g.index.SetCounter(g.name, g.value)
+}
+
+// folgezettelRegex matches the Folgezettel ID at the beginning of a filename,
+// e.g. 1a2b in 1a2b-an-interesting-concept.md.
+var folgezettelRegex = regexp.MustCompile(`^([0-9]+(?:[a-z]+[0-9]+)*[a-z]*)(?:[^0-9a-z]|$)`)
+
+// FolgezettelID returns the Folgezettel ID at the beginning of the filename
+// of the given path, or an empty string if there is none.
+func FolgezettelID(path string) string {
+	matches := folgezettelRegex.FindStringSubmatch(filepath.Base(path))
+	if matches == nil {
+		return ""
+	}
+	return matches[1]
+}
+
+// newFolgezettelIDGenerator returns a function generating the IDs of the
+// next children of the given parent ID, after the existing ones. Numbers and
+// letters alternate, e.g. the children of 1a are 1a1, 1a2, etc. and those of
+// 1a2 are 1a2a, 1a2b, etc. Without parent, top-level IDs 1, 2, etc. are
+// generated.
+func newFolgezettelIDGenerator(parent string, existingIDs []string) IDGenerator {
+	letters := parent != "" && unicode.IsDigit(rune(parent[len(parent)-1]))
+
+	next := 1
+	for _, id := range existingIDs {
+		if !strings.HasPrefix(id, parent) {
+			continue
+		}
+		if index := folgezettelIndex(id[len(parent):], letters); index >= next {
+			next = index + 1
+		}
+	}
+
+	return func() string {
+		id := parent + folgezettelSegment(next, letters)
+		next++
+		return id
+	}
+}
+
+// folgezettelIndex returns the index of the leading segment of the given
+// Folgezettel ID suffix, or 0 if it doesn't start with a segment of the
+// expected type. Letter segments are counted like spreadsheet columns: a is
+// 1, z is 26 and aa is 27.
+func folgezettelIndex(suffix string, letters bool) int {
+	end := strings.IndexFunc(suffix, func(r rune) bool {
+		return unicode.IsDigit(r) == letters
+	})
+	if end == -1 {
+		end = len(suffix)
+	}
+	segment := suffix[:end]
+	if segment == "" {
+		return 0
+	}
+
+	if !letters {
+		index, _ := strconv.Atoi(segment)
+		return index
+	}
+	index := 0
+	for _, r := range segment {
+		index = index*26 + int(r-'a') + 1
+	}
+	return index
+}
+
+// folgezettelSegment is the inverse of folgezettelIndex.
+func folgezettelSegment(index int, letters bool) string {
+	if !letters {
+		return strconv.Itoa(index)
+	}
+	segment := ""
+	for index > 0 {
+		index--
+		segment = string(rune('a'+index%26)) + segment
+		index /= 26
+	}
+	return segment
+}
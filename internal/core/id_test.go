@@ -0,0 +1,68 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+)
+
+func TestTimestampIDGenerator(t *testing.T) {
+	date := time.Date(2009, 11, 17, 20, 34, 58, 0, time.UTC)
+
+	genID := newTimestampIDGenerator(date, "200601021504")
+	assert.Equal(t, genID(), "200911172034")
+	// The date is moved forward to get a different ID.
+	assert.Equal(t, genID(), "200911172035")
+	assert.Equal(t, genID(), "200911172036")
+
+	genID = newTimestampIDGenerator(date, "20060102150405")
+	assert.Equal(t, genID(), "20091117203458")
+	assert.Equal(t, genID(), "20091117203459")
+}
+
+func TestTimestampIDGeneratorWithoutTime(t *testing.T) {
+	date := time.Date(2009, 11, 17, 20, 34, 58, 0, time.UTC)
+
+	genID := newTimestampIDGenerator(date, "note")
+	assert.Equal(t, genID(), "note")
+	assert.Equal(t, genID(), "note")
+}
+
+func TestFolgezettelID(t *testing.T) {
+	test := func(path string, expected string) {
+		assert.Equal(t, FolgezettelID(path), expected)
+	}
+
+	test("1", "1")
+	test("1a2b", "1a2b")
+	test("1a2b.md", "1a2b")
+	test("dir/1a2b-an-interesting-concept.md", "1a2b")
+	test("12ab34 Title.md", "12ab34")
+	test("a1", "")
+	test("1a2b_Title.md", "1a2b")
+	test("an-interesting-concept.md", "")
+}
+
+func TestFolgezettelIDGenerator(t *testing.T) {
+	test := func(parent string, existingIDs []string, expected ...string) {
+		genID := newFolgezettelIDGenerator(parent, existingIDs)
+		for _, id := range expected {
+			assert.Equal(t, genID(), id)
+		}
+	}
+
+	// Top-level IDs
+	test("", []string{}, "1", "2", "3")
+	test("", []string{"1", "2", "2a", "10b1"}, "11", "12")
+
+	// Children of a number
+	test("1", []string{}, "1a", "1b")
+	test("1", []string{"1", "1a", "1a1", "1b", "12"}, "1c", "1d")
+	test("1", []string{"1y"}, "1z", "1aa", "1ab")
+	test("1", []string{"1az"}, "1ba")
+
+	// Children of a letter
+	test("1a", []string{}, "1a1", "1a2")
+	test("1a", []string{"1a1", "1a9b", "1b3"}, "1a10", "1a11")
+}
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mickael-menu/zk/internal/util/errors"
+	"github.com/mickael-menu/zk/internal/util/opt"
+)
+
+// JournalPeriod is the period of time covered by a journal note.
+type JournalPeriod string
+
+const (
+	JournalPeriodDaily   JournalPeriod = "daily"
+	JournalPeriodWeekly  JournalPeriod = "weekly"
+	JournalPeriodMonthly JournalPeriod = "monthly"
+)
+
+// JournalPeriods lists all the supported journal periods.
+var JournalPeriods = []JournalPeriod{
+	JournalPeriodDaily,
+	JournalPeriodWeekly,
+	JournalPeriodMonthly,
+}
+
+// JournalPeriodFromString returns a JournalPeriod from its string
+// representation.
+func JournalPeriodFromString(str string) (JournalPeriod, error) {
+	for _, period := range JournalPeriods {
+		if string(period) == str {
+			return period, nil
+		}
+	}
+
+	strs := []string{}
+	for _, period := range JournalPeriods {
+		strs = append(strs, string(period))
+	}
+	return "", fmt.Errorf("%s: unknown journal period\ntry %s", str, strings.Join(strs, ", "))
+}
+
+// Start returns the beginning of the period containing the given date.
+// Weeks start on Monday.
+func (p JournalPeriod) Start(date time.Time) time.Time {
+	year, month, day := date.Date()
+
+	switch p {
+	case JournalPeriodWeekly:
+		// Number of days since the last Monday.
+		days := (int(date.Weekday()) + 6) % 7
+		return time.Date(year, month, day-days, 0, 0, 0, 0, date.Location())
+	case JournalPeriodMonthly:
+		return time.Date(year, month, 1, 0, 0, 0, 0, date.Location())
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, date.Location())
+	}
+}
+
+// Add shifts the given date by a number of periods.
+func (p JournalPeriod) Add(date time.Time, count int) time.Time {
+	switch p {
+	case JournalPeriodWeekly:
+		return date.AddDate(0, 0, 7*count)
+	case JournalPeriodMonthly:
+		return date.AddDate(0, count, 0)
+	default:
+		return date.AddDate(0, 0, count)
+	}
+}
+
+// JournalOpts holds the options used to open a journal note.
+type JournalOpts struct {
+	// Period covered by the journal note.
+	Period JournalPeriod
+	// Date included in the period of the journal note, in the local time
+	// zone.
+	Date time.Time
+	// Number of periods to shift the date with, e.g. -1 to open the
+	// previous journal note.
+	Offset int
+	// Prompts the user for the missing template variables declared in the
+	// config, when creating the journal note. Their default value is used
+	// when nil.
+	PromptVariable TemplateVariablePrompter
+}
+
+// JournalNote returns the path to the journal note covering the given date,
+// after creating it if it doesn't exist yet.
+//
+// The journal note is created in the first path of the group declaring the
+// period. Its filename template must depend only on the date, to find the
+// existing notes.
+func (n *Notebook) JournalNote(opts JournalOpts) (string, error) {
+	wrap := errors.Wrapperf("%s journal note", opts.Period)
+
+	name, group, err := n.Config.JournalGroup(opts.Period)
+	if err != nil {
+		return "", wrap(err)
+	}
+
+	dir := n.Path
+	if len(group.Paths) > 0 {
+		dir = filepath.Join(n.Path, group.Paths[0])
+	}
+
+	// The period is found in the local time zone, as parsed dates might be
+	// in UTC.
+	date := opts.Period.Add(opts.Period.Start(opts.Date.Local()), opts.Offset)
+
+	// There is only one journal note per period.
+	path, err := n.NewNote(NewNoteOpts{
+		Directory:      opt.NewString(dir),
+		Group:          opt.NewString(name),
+		Date:           date,
+		OnConflict:     NoteConflictOpen,
+		PromptVariable: opts.PromptVariable,
+	})
+	return path, wrap(err)
+}
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mickael-menu/zk/internal/util/opt"
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+)
+
+func TestJournalPeriodFromString(t *testing.T) {
+	test := func(str string, expected JournalPeriod) {
+		actual, err := JournalPeriodFromString(str)
+		assert.Nil(t, err)
+		assert.Equal(t, actual, expected)
+	}
+
+	test("daily", JournalPeriodDaily)
+	test("weekly", JournalPeriodWeekly)
+	test("monthly", JournalPeriodMonthly)
+
+	_, err := JournalPeriodFromString("yearly")
+	assert.Err(t, err, "yearly: unknown journal period\ntry daily, weekly, monthly")
+}
+
+func TestJournalPeriodStart(t *testing.T) {
+	test := func(period JournalPeriod, date time.Time, expected time.Time) {
+		assert.Equal(t, period.Start(date), expected)
+	}
+
+	// Tuesday
+	date := time.Date(2009, 11, 17, 20, 34, 58, 0, time.UTC)
+	test(JournalPeriodDaily, date, time.Date(2009, 11, 17, 0, 0, 0, 0, time.UTC))
+	test(JournalPeriodWeekly, date, time.Date(2009, 11, 16, 0, 0, 0, 0, time.UTC))
+	test(JournalPeriodMonthly, date, time.Date(2009, 11, 1, 0, 0, 0, 0, time.UTC))
+
+	// Weeks start on Monday.
+	test(JournalPeriodWeekly, time.Date(2009, 11, 16, 8, 0, 0, 0, time.UTC), time.Date(2009, 11, 16, 0, 0, 0, 0, time.UTC))
+	test(JournalPeriodWeekly, time.Date(2009, 11, 22, 8, 0, 0, 0, time.UTC), time.Date(2009, 11, 16, 0, 0, 0, 0, time.UTC))
+	// Across months.
+	test(JournalPeriodWeekly, time.Date(2009, 12, 2, 8, 0, 0, 0, time.UTC), time.Date(2009, 11, 30, 0, 0, 0, 0, time.UTC))
+}
+
+func TestJournalPeriodAdd(t *testing.T) {
+	test := func(period JournalPeriod, count int, expected time.Time) {
+		date := time.Date(2009, 11, 30, 0, 0, 0, 0, time.UTC)
+		assert.Equal(t, period.Add(date, count), expected)
+	}
+
+	test(JournalPeriodDaily, 0, time.Date(2009, 11, 30, 0, 0, 0, 0, time.UTC))
+	test(JournalPeriodDaily, 1, time.Date(2009, 12, 1, 0, 0, 0, 0, time.UTC))
+	test(JournalPeriodDaily, -1, time.Date(2009, 11, 29, 0, 0, 0, 0, time.UTC))
+	test(JournalPeriodWeekly, 1, time.Date(2009, 12, 7, 0, 0, 0, 0, time.UTC))
+	test(JournalPeriodWeekly, -2, time.Date(2009, 11, 16, 0, 0, 0, 0, time.UTC))
+	test(JournalPeriodMonthly, 1, time.Date(2009, 12, 30, 0, 0, 0, 0, time.UTC))
+	test(JournalPeriodMonthly, -1, time.Date(2009, 10, 30, 0, 0, 0, 0, time.UTC))
+}
+
+func TestNotebookJournalNote(t *testing.T) {
+	setLocalTimezone(t, time.UTC)
+	test := newJournalTest(nil)
+
+	path, err := test.notebook().JournalNote(JournalOpts{
+		Period: JournalPeriodDaily,
+		Date:   now,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, path, "/notebook/journal/2009-11-17.ext")
+	assert.Equal(t, test.fs.files[path], "body")
+}
+
+func TestNotebookJournalNoteWithOffset(t *testing.T) {
+	setLocalTimezone(t, time.UTC)
+	test := newJournalTest(nil)
+
+	path, err := test.notebook().JournalNote(JournalOpts{
+		Period: JournalPeriodDaily,
+		Date:   now,
+		Offset: -1,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, path, "/notebook/journal/2009-11-16.ext")
+}
+
+func TestNotebookJournalNoteOpensExistingNote(t *testing.T) {
+	setLocalTimezone(t, time.UTC)
+	files := map[string]string{
+		"/notebook/journal/2009-11-17.ext": "existing",
+	}
+	test := newJournalTest(files)
+
+	path, err := test.notebook().JournalNote(JournalOpts{
+		Period: JournalPeriodDaily,
+		Date:   now,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, path, "/notebook/journal/2009-11-17.ext")
+	assert.Equal(t, test.fs.files[path], "existing")
+}
+
+// Dates parsed from natural language are in UTC, which might be another day
+// than the local one.
+func TestNotebookJournalNoteUsesLocalDate(t *testing.T) {
+	setLocalTimezone(t, time.FixedZone("UTC-10", -10*60*60))
+	test := newJournalTest(nil)
+
+	path, err := test.notebook().JournalNote(JournalOpts{
+		Period: JournalPeriodDaily,
+		Date:   time.Date(2009, 11, 17, 5, 0, 0, 0, time.UTC),
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, path, "/notebook/journal/2009-11-16.ext")
+}
+
+func TestNotebookJournalNotePromptsTemplateVariables(t *testing.T) {
+	setLocalTimezone(t, time.UTC)
+	test := newJournalTest(nil)
+	group := test.config.Groups["daily"]
+	group.Variables = map[string]TemplateVariable{
+		"mood": {Type: TemplateVariableString, Required: true},
+	}
+	test.config.Groups["daily"] = group
+
+	_, err := test.notebook().JournalNote(JournalOpts{
+		Period: JournalPeriodDaily,
+		Date:   now,
+		PromptVariable: func(name string, variable TemplateVariable) (string, error) {
+			return "happy", nil
+		},
+	})
+
+	assert.Nil(t, err)
+	context := test.bodyTemplate.Contexts[0].(newNoteTemplateContext)
+	assert.Equal(t, context.Extra["mood"], "happy")
+}
+
+func TestNotebookJournalNoteWithUnknownPeriod(t *testing.T) {
+	test := newJournalTest(nil)
+
+	_, err := test.notebook().JournalNote(JournalOpts{
+		Period: JournalPeriodMonthly,
+		Date:   now,
+	})
+
+	assert.Err(t, err, "monthly journal note: no group with the monthly period found in the config")
+}
+
+// setLocalTimezone replaces the local time zone for the duration of a test.
+func setLocalTimezone(t *testing.T, loc *time.Location) {
+	local := time.Local
+	time.Local = loc
+	t.Cleanup(func() {
+		time.Local = local
+	})
+}
+
+// newJournalTest creates a notebook with a daily journal group, whose notes
+// are named after their date.
+func newJournalTest(files map[string]string) *newNoteTest {
+	test := &newNoteTest{
+		rootDir: "/notebook",
+		dirs:    []string{"/notebook/journal"},
+		files:   files,
+		groups: map[string]GroupConfig{
+			"daily": {
+				Paths:  []string{"journal"},
+				Period: JournalPeriodDaily,
+				Note: NoteConfig{
+					FilenameTemplate: "filename",
+					Extension:        "ext",
+					BodyTemplatePath: opt.NewString("default"),
+				},
+			},
+		},
+		filenameTemplateRender: func(context newNoteTemplateContext) string {
+			return context.Now.Format("2006-01-02") + ".ext"
+		},
+	}
+	test.setup()
+	return test
+}
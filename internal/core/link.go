@@ -1,5 +1,7 @@
 package core
 
+import "strings"
+
 // Link represents a link in a note to another note or an external resource.
 type Link struct {
 	// Label of the link.
@@ -16,6 +18,24 @@ type Link struct {
 	SnippetStart int
 	// End byte offset of the snippet in the note content.
 	SnippetEnd int
+	// Start byte offset of the href in the note content, as written in the
+	// link. HrefStart and HrefEnd are 0 when the href is not written inline,
+	// for example with reference links.
+	HrefStart int
+	// End byte offset of the href in the note content, as written in the
+	// link.
+	HrefEnd int
+	// Indicates whether this is a [[wiki link]].
+	IsWikiLink bool
+}
+
+// SplitHref separates the path of a link href from its #anchor, which is
+// returned without the leading #.
+func SplitHref(href string) (path string, anchor string) {
+	if i := strings.Index(href, "#"); i >= 0 {
+		return href[:i], href[i+1:]
+	}
+	return href, ""
 }
 
 // LinkRelation defines the relationship between a link's source and target.
@@ -36,3 +56,12 @@ func LinkRels(rel ...string) []LinkRelation {
 	}
 	return rels
 }
+
+// ResolvedLink represents a link between two indexed notes.
+type ResolvedLink struct {
+	Link
+	SourceID   NoteID
+	SourcePath string
+	TargetID   NoteID
+	TargetPath string
+}
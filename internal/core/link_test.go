@@ -0,0 +1,22 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+)
+
+func TestSplitHref(t *testing.T) {
+	test := func(href string, expectedPath string, expectedAnchor string) {
+		path, anchor := SplitHref(href)
+		assert.Equal(t, path, expectedPath)
+		assert.Equal(t, anchor, expectedAnchor)
+	}
+
+	test("", "", "")
+	test("dir/note.md", "dir/note.md", "")
+	test("dir/note.md#section", "dir/note.md", "section")
+	test("#section", "", "section")
+	test("note#a#b", "note", "a#b")
+	test("note#", "note", "")
+}
@@ -32,6 +32,8 @@ type Note struct {
 	Links []Link
 	// List of tags found in the content.
 	Tags []string
+	// List of sections introduced by the headings of the content.
+	Sections []Section
 	// JSON dictionary of raw metadata extracted from the frontmatter.
 	Metadata map[string]interface{}
 	// Date of creation.
@@ -51,6 +53,8 @@ type ContextualNote struct {
 	Note
 	// List of context-sensitive excerpts from the note.
 	Snippets []string
+	// Section containing the first match of the search query, if any.
+	MatchedSection *Section
 }
 
 // MinimalNote holds a Note's title and path information, for display purposes.
@@ -61,4 +65,6 @@ type MinimalNote struct {
 	Path string
 	// Title of the note.
 	Title string
+	// JSON dictionary of raw metadata extracted from the frontmatter.
+	Metadata map[string]interface{}
 }
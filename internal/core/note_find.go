@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -15,6 +16,12 @@ type NoteFindOpts struct {
 	Match opt.String
 	// Search for exact occurrences of the Match string.
 	ExactMatch bool
+	// Filter notes whose title, or one of the `aliases` declared in their
+	// YAML frontmatter, contains the given string case-insensitively.
+	TitleMatch opt.String
+	// Filter notes having a heading containing the given string
+	// case-insensitively.
+	HeadingMatch opt.String
 	// Filter by note paths.
 	IncludePaths []string
 	// Filter excluding notes at the given paths.
@@ -23,6 +30,9 @@ type NoteFindOpts struct {
 	ExcludeIDs []NoteID
 	// Filter by tags found in the notes.
 	Tags []string
+	// Filter the notes having any of the given tags, matched by their exact
+	// name instead of a glob pattern.
+	ExactTags []string
 	// Filter the notes mentioning the given ones.
 	Mention []string
 	// Filter the notes mentioned by the given ones.
@@ -43,6 +53,16 @@ type NoteFindOpts struct {
 	ModifiedStart *time.Time
 	// Filter notes modified before the given date.
 	ModifiedEnd *time.Time
+	// Filter notes by the values of their YAML frontmatter.
+	Metadata []MetadataFilter
+	// Filter notes having at least the given number of links from other notes.
+	MinBacklinks *int
+	// Filter notes having at most the given number of links from other notes.
+	MaxBacklinks *int
+	// Filter notes having at least the given number of internal links.
+	MinLinks *int
+	// Filter notes having at most the given number of internal links.
+	MaxLinks *int
 	// Limits the number of results
 	Limit int
 	// Sorting criteria
@@ -68,6 +88,99 @@ type LinkFilter struct {
 	MaxDistance int
 }
 
+// MetadataFilter is a note filter matching a value of the YAML frontmatter.
+type MetadataFilter struct {
+	// Key of the frontmatter value. Nested keys are separated by dots, e.g.
+	// `project.status`.
+	Key string
+	// Operator used to compare the frontmatter value with Value.
+	Operator MetadataOperator
+	// Value compared with the frontmatter value. It can be a glob pattern
+	// when testing for equality.
+	Value string
+	// Negate selects the notes which don't match the filter.
+	Negate bool
+}
+
+// MetadataOperator represents a comparison operator of a MetadataFilter.
+type MetadataOperator int
+
+const (
+	// The key is present in the frontmatter.
+	MetadataExists MetadataOperator = iota + 1
+	// The value is equal to, or matches the glob pattern. When the
+	// frontmatter value is a list, any of its items can match.
+	MetadataEqual
+	MetadataLess
+	MetadataLessOrEqual
+	MetadataGreater
+	MetadataGreaterOrEqual
+)
+
+var metadataFilterRegex = regexp.MustCompile(`^([^=!<>]+?)\s*(!=|<=|>=|=|<|>)\s*(.*)$`)
+
+// MetadataFiltersFromStrings returns a list of MetadataFilter from their
+// string representation.
+func MetadataFiltersFromStrings(strs []string) ([]MetadataFilter, error) {
+	filters := make([]MetadataFilter, 0)
+	for _, str := range strs {
+		filter, err := MetadataFilterFromString(str)
+		if err != nil {
+			return filters, err
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// MetadataFilterFromString returns a MetadataFilter from its string
+// representation, e.g.:
+//   - `status=draft` and `status!=draft` for (in)equality
+//   - `status=dra*` to match a glob pattern
+//   - `archived` and `-archived` or `NOT archived` for the presence of a key
+//   - `priority>2` or `review<=2021-06-01` for numeric and date comparisons
+func MetadataFilterFromString(str string) (MetadataFilter, error) {
+	filter := MetadataFilter{}
+
+	expr := strings.TrimSpace(str)
+	if strings.HasPrefix(expr, "-") {
+		filter.Negate = true
+		expr = strings.TrimPrefix(expr, "-")
+	} else if strings.HasPrefix(expr, "NOT ") {
+		filter.Negate = true
+		expr = strings.TrimPrefix(expr, "NOT ")
+	}
+	expr = strings.TrimSpace(expr)
+
+	if match := metadataFilterRegex.FindStringSubmatch(expr); match != nil {
+		filter.Key = match[1]
+		filter.Value = match[3]
+		switch match[2] {
+		case "=":
+			filter.Operator = MetadataEqual
+		case "!=":
+			filter.Operator = MetadataEqual
+			filter.Negate = !filter.Negate
+		case "<":
+			filter.Operator = MetadataLess
+		case "<=":
+			filter.Operator = MetadataLessOrEqual
+		case ">":
+			filter.Operator = MetadataGreater
+		case ">=":
+			filter.Operator = MetadataGreaterOrEqual
+		}
+	} else {
+		filter.Key = expr
+		filter.Operator = MetadataExists
+	}
+
+	if filter.Key == "" || strings.ContainsAny(filter.Key, `"=<>`) {
+		return filter, fmt.Errorf("%s: invalid metadata filter\ntry key=value, key!=value, key>value or -key", str)
+	}
+	return filter, nil
+}
+
 // NoteSorter represents an order term used to sort a list of notes.
 type NoteSorter struct {
 	Field     NoteSortField
@@ -90,6 +203,12 @@ const (
 	NoteSortTitle
 	// Sort by the number of words in the note bodies.
 	NoteSortWordCount
+	// Sort by the number of links pointing to the notes from other notes.
+	NoteSortBacklinkCount
+	// Sort by the number of internal links found in the notes.
+	NoteSortLinkCount
+	// Sort by the number of external links (e.g. URLs) found in the notes.
+	NoteSortExternalLinkCount
 	// Sort by the length of the note path.
 	// This is not accessible to the user but used for technical reasons, to
 	// find the best match when searching a path prefix.
@@ -136,8 +255,14 @@ func NoteSorterFromString(str string) (NoteSorter, error) {
 		sorter = NoteSorter{Field: NoteSortRandom, Ascending: true}
 	case "word-count", "wc":
 		sorter = NoteSorter{Field: NoteSortWordCount, Ascending: true}
+	case "backlink-count", "blc":
+		sorter = NoteSorter{Field: NoteSortBacklinkCount, Ascending: false}
+	case "link-count", "lc":
+		sorter = NoteSorter{Field: NoteSortLinkCount, Ascending: false}
+	case "external-link-count", "elc":
+		sorter = NoteSorter{Field: NoteSortExternalLinkCount, Ascending: false}
 	default:
-		return sorter, fmt.Errorf("%s: unknown sorting term\ntry created, modified, path, title, random or word-count", str)
+		return sorter, fmt.Errorf("%s: unknown sorting term\ntry created, modified, path, title, random, word-count, backlink-count, link-count or external-link-count", str)
 	}
 
 	switch orderSymbol {
@@ -39,6 +39,18 @@ func TestNoteSorterFromString(t *testing.T) {
 	test("word-count", NoteSortWordCount, true)
 	test("word-count-", NoteSortWordCount, false)
 
+	test("blc", NoteSortBacklinkCount, false)
+	test("backlink-count", NoteSortBacklinkCount, false)
+	test("backlink-count+", NoteSortBacklinkCount, true)
+
+	test("lc", NoteSortLinkCount, false)
+	test("link-count", NoteSortLinkCount, false)
+	test("link-count+", NoteSortLinkCount, true)
+
+	test("elc", NoteSortExternalLinkCount, false)
+	test("external-link-count", NoteSortExternalLinkCount, false)
+	test("external-link-count+", NoteSortExternalLinkCount, true)
+
 	_, err := NoteSorterFromString("foobar")
 	assert.Err(t, err, "foobar: unknown sorting term")
 }
@@ -67,3 +79,39 @@ func TestSortersFromStrings(t *testing.T) {
 	_, err := NoteSortersFromStrings([]string{"c", "foobar"})
 	assert.Err(t, err, "foobar: unknown sorting term")
 }
+
+func TestMetadataFilterFromString(t *testing.T) {
+	test := func(str string, expected MetadataFilter) {
+		actual, err := MetadataFilterFromString(str)
+		assert.Nil(t, err)
+		assert.Equal(t, actual, expected)
+	}
+
+	test("status=draft", MetadataFilter{Key: "status", Operator: MetadataEqual, Value: "draft"})
+	test(" status = in progress ", MetadataFilter{Key: "status", Operator: MetadataEqual, Value: "in progress"})
+	test("status=dra*", MetadataFilter{Key: "status", Operator: MetadataEqual, Value: "dra*"})
+	test("status=", MetadataFilter{Key: "status", Operator: MetadataEqual, Value: ""})
+	test("status!=draft", MetadataFilter{Key: "status", Operator: MetadataEqual, Value: "draft", Negate: true})
+	test("-status!=draft", MetadataFilter{Key: "status", Operator: MetadataEqual, Value: "draft", Negate: false})
+	test("project.status=done", MetadataFilter{Key: "project.status", Operator: MetadataEqual, Value: "done"})
+	test("url=https://a.com/?q=1", MetadataFilter{Key: "url", Operator: MetadataEqual, Value: "https://a.com/?q=1"})
+	test("archived", MetadataFilter{Key: "archived", Operator: MetadataExists})
+	test("-archived", MetadataFilter{Key: "archived", Operator: MetadataExists, Negate: true})
+	test("NOT archived", MetadataFilter{Key: "archived", Operator: MetadataExists, Negate: true})
+	test("priority>2", MetadataFilter{Key: "priority", Operator: MetadataGreater, Value: "2"})
+	test("priority>=2", MetadataFilter{Key: "priority", Operator: MetadataGreaterOrEqual, Value: "2"})
+	test("priority<2", MetadataFilter{Key: "priority", Operator: MetadataLess, Value: "2"})
+	test("review<=2021-06-01", MetadataFilter{Key: "review", Operator: MetadataLessOrEqual, Value: "2021-06-01"})
+}
+
+func TestMetadataFilterFromStringInvalid(t *testing.T) {
+	test := func(str string) {
+		_, err := MetadataFilterFromString(str)
+		assert.Err(t, err, str+": invalid metadata filter\ntry key=value, key!=value, key>value or -key")
+	}
+
+	test("")
+	test("-")
+	test("=value")
+	test(`"key"=value`)
+}
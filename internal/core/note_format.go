@@ -45,6 +45,7 @@ func newNoteFormatter(basePath string, template Template, linkFormatter LinkForm
 			Body:       note.Body,
 			Snippets:   snippets,
 			Tags:       note.Tags,
+			Sections:   note.Sections,
 			RawContent: note.RawContent,
 			WordCount:  note.WordCount,
 			Metadata:   note.Metadata,
@@ -52,6 +53,8 @@ func newNoteFormatter(basePath string, template Template, linkFormatter LinkForm
 			Modified:   note.Modified,
 			Checksum:   note.Checksum,
 			Env:        env,
+
+			MatchedSection: note.MatchedSection,
 		})
 	}, nil
 }
@@ -71,11 +74,15 @@ type noteFormatRenderContext struct {
 	RawContent string                 `json:"rawContent" handlebars:"raw-content"`
 	WordCount  int                    `json:"wordCount" handlebars:"word-count"`
 	Tags       []string               `json:"tags"`
+	Sections   []Section              `json:"sections"`
 	Metadata   map[string]interface{} `json:"metadata"`
 	Created    time.Time              `json:"created"`
 	Modified   time.Time              `json:"modified"`
 	Checksum   string                 `json:"checksum"`
 	Env        map[string]string      `json:"-"`
+
+	// Section containing the first match of the --match query.
+	MatchedSection *Section `json:"matchedSection,omitempty" handlebars:"matched-section"`
 }
 
 func (c noteFormatRenderContext) Equal(other noteFormatRenderContext) bool {
@@ -35,6 +35,10 @@ func TestNewNoteFormatter(t *testing.T) {
 			RawContent: "Content 1",
 			WordCount:  1,
 			Tags:       []string{"tag1", "tag2"},
+			Sections: []Section{
+				{Level: 1, Title: "Note 1", Slug: "note-1", Start: 0, End: 20},
+				{Level: 2, Title: "Part 1", Slug: "part-1", Start: 10, End: 20},
+			},
 			Metadata: map[string]interface{}{
 				"metadata1": "val1",
 				"metadata2": "val2",
@@ -43,7 +47,8 @@ func TestNewNoteFormatter(t *testing.T) {
 			Modified: date2,
 			Checksum: "checksum1",
 		},
-		Snippets: []string{"snippet1", "snippet2"},
+		Snippets:       []string{"snippet1", "snippet2"},
+		MatchedSection: &Section{Level: 2, Title: "Part 1", Slug: "part-1", Start: 10, End: 20},
 	})
 	assert.Nil(t, err)
 	assert.Equal(t, res, "format")
@@ -81,13 +86,18 @@ func TestNewNoteFormatter(t *testing.T) {
 			RawContent: "Content 1",
 			WordCount:  1,
 			Tags:       []string{"tag1", "tag2"},
+			Sections: []Section{
+				{Level: 1, Title: "Note 1", Slug: "note-1", Start: 0, End: 20},
+				{Level: 2, Title: "Part 1", Slug: "part-1", Start: 10, End: 20},
+			},
 			Metadata: map[string]interface{}{
 				"metadata1": "val1",
 				"metadata2": "val2",
 			},
-			Created:  date1,
-			Modified: date2,
-			Checksum: "checksum1",
+			Created:        date1,
+			Modified:       date2,
+			Checksum:       "checksum1",
+			MatchedSection: &Section{Level: 2, Title: "Part 1", Slug: "part-1", Start: 10, End: 20},
 		},
 		noteFormatRenderContext{
 			Path:       "dir/note2",
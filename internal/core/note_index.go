@@ -4,7 +4,10 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -25,17 +28,34 @@ type NoteIndex interface {
 	// given filtering and sorting criteria.
 	FindMinimal(opts NoteFindOpts) ([]MinimalNote, error)
 
-	// FindCollections retrieves all the collections of the given kind.
-	FindCollections(kind CollectionKind) ([]Collection, error)
+	// FindLinksBetweenNotes retrieves the links whose source and target are
+	// both among the given notes.
+	FindLinksBetweenNotes(ids []NoteID) ([]ResolvedLink, error)
+	// FindDeadLinks retrieves the internal links whose target could not be
+	// found in the index.
+	FindDeadLinks() ([]ResolvedLink, error)
+
+	// FindSections retrieves the sections of the given notes, ordered by
+	// their position in the notes.
+	FindSections(ids []NoteID) (map[NoteID][]Section, error)
+
+	// FindCollections retrieves all the collections of the given kind,
+	// ordered with the given sorters.
+	FindCollections(kind CollectionKind, sorters []CollectionSorter) ([]Collection, error)
 
 	// Indexed returns the list of indexed note file metadata.
 	IndexedPaths() (<-chan paths.Metadata, error)
+	// IndexedChecksums returns the content checksums of the indexed notes,
+	// by path.
+	IndexedChecksums() (map[string]string, error)
 	// Add indexes a new note from its metadata.
 	Add(note Note) (NoteID, error)
 	// Update resets the metadata of an already indexed note.
 	Update(note Note) error
 	// Remove deletes a note from the index.
 	Remove(path string) error
+	// Move updates the path of an indexed note, preserving its ID.
+	Move(oldPath string, newPath string) error
 
 	// Commit performs a set of operations atomically.
 	Commit(transaction func(idx NoteIndex) error) error
@@ -44,6 +64,16 @@ type NoteIndex interface {
 	NeedsReindexing() (bool, error)
 	// SetNeedsReindexing indicates whether all notes should be reindexed.
 	SetNeedsReindexing(needsReindexing bool) error
+
+	// Counter returns the current value of the counter with the given name,
+	// or 0 if it was never set.
+	Counter(name string) (int, error)
+	// SetCounter persists the value of the counter with the given name.
+	SetCounter(name string, value int) error
+
+	// Close releases the resources held by the index, such as its database
+	// connection. The index can't be used afterwards.
+	Close() error
 }
 
 // NoteIndexingStats holds statistics about a notebook indexing process.
@@ -56,6 +86,8 @@ type NoteIndexingStats struct {
 	ModifiedCount int `json:"modifiedCount"`
 	// Number of notes removed since last indexing.
 	RemovedCount int `json:"removedCount"`
+	// Number of notes moved or renamed since last indexing.
+	MovedCount int `json:"movedCount"`
 	// Duration of the indexing process.
 	Duration time.Duration `json:"duration"`
 }
@@ -65,11 +97,12 @@ func (s NoteIndexingStats) String() string {
 	return fmt.Sprintf(`Indexed %d %v in %v
   + %d added
   ~ %d modified
-  - %d removed`,
+  - %d removed
+  > %d moved`,
 		s.SourceCount,
 		strutil.Pluralize("note", s.SourceCount),
 		s.Duration.Round(500*time.Millisecond),
-		s.AddedCount, s.ModifiedCount, s.RemovedCount,
+		s.AddedCount, s.ModifiedCount, s.RemovedCount, s.MovedCount,
 	)
 }
 
@@ -77,9 +110,14 @@ func (s NoteIndexingStats) String() string {
 type indexTask struct {
 	notebook *Notebook
 	force    bool
-	index    NoteIndex
-	parser   NoteParser
-	logger   util.Logger
+	// Paths of the notes or directories to index, relative to the notebook
+	// root. The whole notebook is indexed when nil.
+	paths  []string
+	index  NoteIndex
+	parser NoteParser
+	logger util.Logger
+	// Number of notes parsed concurrently, defaults to the number of CPUs.
+	workers int
 }
 
 func (t *indexTask) execute(callback func(change paths.DiffChange)) (NoteIndexingStats, error) {
@@ -95,83 +133,265 @@ func (t *indexTask) execute(callback func(change paths.DiffChange)) (NoteIndexin
 
 	force := t.force || needsReindexing
 
-	shouldIgnorePath := func(path string) (bool, error) {
-		group, err := t.notebook.Config.GroupConfigForPath(path)
-		if err != nil {
-			return true, err
-		}
-
-		if filepath.Ext(path) != "."+group.Note.Extension {
-			return true, nil
-		}
+	// A moved note is reported as a removal and an addition by paths.Diff.
+	// To preserve its ID, the removals and the added notes whose content
+	// matches an indexed note are applied after diffing, to pair them by
+	// checksum.
+	indexedChecksums, err := t.index.IndexedChecksums()
+	if err != nil {
+		return stats, wrap(err)
+	}
+	isIndexedChecksum := map[string]bool{}
+	for _, checksum := range indexedChecksums {
+		isIndexedChecksum[checksum] = true
+	}
 
-		for _, ignoreGlob := range group.IgnoreGlobs() {
-			matches, err := filepath.Match(ignoreGlob, path)
-			if err != nil {
-				return true, errors.Wrapf(err, "failed to match ignore glob %s to %s", ignoreGlob, path)
-			}
-			if matches {
-				return true, nil
-			}
+	var source, target <-chan paths.Metadata
+	if t.paths == nil || needsReindexing {
+		target, err = t.index.IndexedPaths()
+		if err == nil {
+			source = paths.Walk(t.notebook.Path, t.logger, t.notebook.shouldIgnorePath)
 		}
-
-		return false, nil
+	} else {
+		source, target, err = t.restrictedSourceAndTarget()
 	}
-
-	source := paths.Walk(t.notebook.Path, t.logger, shouldIgnorePath)
-
-	target, err := t.index.IndexedPaths()
 	if err != nil {
 		return stats, wrap(err)
 	}
 
-	// FIXME: Use the FS?
-	count, err := paths.Diff(source, target, force, func(change paths.DiffChange) error {
-		callback(change)
+	// The notes are parsed concurrently by a pool of workers, while the
+	// changes are applied to the index in the diffing order.
+	workers := t.workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	parsing := make(chan indexJob)
+	pending := make(chan indexJob, workers*4)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for job := range parsing {
+				note, err := t.noteAt(job.change.Path)
+				job.result <- indexJobResult{note: note, err: err}
+			}
+		}()
+	}
+
+	var count int
+	var diffErr error
+	go func() {
+		defer close(pending)
+		defer close(parsing)
+
+		// FIXME: Use the FS?
+		count, diffErr = paths.Diff(source, target, force, func(change paths.DiffChange) error {
+			job := indexJob{change: change}
+			if change.Kind != paths.DiffRemoved {
+				job.result = make(chan indexJobResult, 1)
+				parsing <- job
+			}
+			pending <- job
+			return nil
+		})
+	}()
 
-		switch change.Kind {
+	removedPaths := []string{}
+	movedCandidates := []Note{}
+
+	for job := range pending {
+		switch job.change.Kind {
 		case paths.DiffAdded:
+			res := <-job.result
+			if res.err == nil && isIndexedChecksum[res.note.Checksum] {
+				movedCandidates = append(movedCandidates, res.note)
+				continue
+			}
+			callback(job.change)
 			stats.AddedCount += 1
-			note, err := t.noteAt(change.Path)
+			err := res.err
 			if err == nil {
-				_, err = t.index.Add(note)
+				_, err = t.index.Add(res.note)
 			}
 			t.logger.Err(err)
 
 		case paths.DiffModified:
+			callback(job.change)
 			stats.ModifiedCount += 1
-			note, err := t.noteAt(change.Path)
+			res := <-job.result
+			err := res.err
 			if err == nil {
-				err = t.index.Update(note)
+				err = t.index.Update(res.note)
 			}
 			t.logger.Err(err)
 
 		case paths.DiffRemoved:
-			stats.RemovedCount += 1
-			err := t.index.Remove(change.Path)
-			t.logger.Err(err)
+			removedPaths = append(removedPaths, job.change.Path)
 		}
-		return nil
-	})
+	}
+
+	t.applyMoves(movedCandidates, removedPaths, indexedChecksums, &stats, callback)
 
 	stats.SourceCount = count
 	stats.Duration = time.Since(startTime)
 
-	if needsReindexing {
+	err = diffErr
+	if err == nil && needsReindexing {
 		err = t.index.SetNeedsReindexing(false)
 	}
 
 	return stats, wrap(err)
 }
 
+// applyMoves pairs the added notes with the removed ones having the same
+// checksum, to update their path in the index instead of indexing them as new
+// notes. The remaining notes are added or removed.
+//
+// A pair is made only when a single added note and a single removed note
+// share a checksum, as identical notes can't be told apart.
+//
+// The moves are reported to the callback as a single DiffMoved change.
+func (t *indexTask) applyMoves(added []Note, removedPaths []string, checksums map[string]string, stats *NoteIndexingStats, callback func(change paths.DiffChange)) {
+	removedByChecksum := map[string][]string{}
+	for _, path := range removedPaths {
+		checksum := checksums[path]
+		removedByChecksum[checksum] = append(removedByChecksum[checksum], path)
+	}
+
+	addedByChecksum := map[string]int{}
+	for _, note := range added {
+		addedByChecksum[note.Checksum] += 1
+	}
+
+	moved := map[string]bool{}
+	for _, note := range added {
+		var err error
+		if oldPaths := removedByChecksum[note.Checksum]; note.Checksum != "" && len(oldPaths) == 1 && addedByChecksum[note.Checksum] == 1 {
+			moved[oldPaths[0]] = true
+			callback(paths.DiffChange{Path: note.Path, Kind: paths.DiffMoved})
+			stats.MovedCount += 1
+			err = t.index.Move(oldPaths[0], note.Path)
+			if err == nil {
+				// The links need to be updated, as they are relative to the
+				// note directory.
+				err = t.index.Update(note)
+			}
+		} else {
+			callback(paths.DiffChange{Path: note.Path, Kind: paths.DiffAdded})
+			stats.AddedCount += 1
+			_, err = t.index.Add(note)
+		}
+		t.logger.Err(err)
+	}
+
+	for _, path := range removedPaths {
+		if moved[path] {
+			continue
+		}
+		callback(paths.DiffChange{Path: path, Kind: paths.DiffRemoved})
+		stats.RemovedCount += 1
+		t.logger.Err(t.index.Remove(path))
+	}
+}
+
+// indexJob is a file change to apply to the index.
+type indexJob struct {
+	change paths.DiffChange
+	// Receives the parsed note, for added and modified files.
+	result chan indexJobResult
+}
+
+type indexJobResult struct {
+	note Note
+	err  error
+}
+
+// restrictedSourceAndTarget returns the metadata of the notes found on the
+// file system and in the index, restricted to the paths of the task and their
+// descendants.
+func (t *indexTask) restrictedSourceAndTarget() (<-chan paths.Metadata, <-chan paths.Metadata, error) {
+	isRestricted := func(path string) bool {
+		for _, root := range t.paths {
+			if root == "" || path == root || strings.HasPrefix(path, root+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	sourceFiles := map[string]paths.Metadata{}
+	for _, root := range t.paths {
+		info, err := os.Stat(filepath.Join(t.notebook.Path, root))
+		switch {
+		case os.IsNotExist(err):
+			continue
+		case err != nil:
+			return nil, nil, err
+
+		case info.IsDir():
+			shouldIgnorePath := func(path string) (bool, error) {
+				return t.notebook.shouldIgnorePath(filepath.Join(root, path))
+			}
+			for metadata := range paths.Walk(filepath.Join(t.notebook.Path, root), t.logger, shouldIgnorePath) {
+				metadata.Path = filepath.Join(root, metadata.Path)
+				sourceFiles[metadata.Path] = metadata
+			}
+
+		default:
+			shouldIgnore, err := t.notebook.shouldIgnorePath(root)
+			if err != nil {
+				t.logger.Err(err)
+			}
+			if !shouldIgnore {
+				sourceFiles[root] = paths.Metadata{
+					Path:     root,
+					Modified: info.ModTime().UTC(),
+				}
+			}
+		}
+	}
+
+	indexed, err := t.index.IndexedPaths()
+	if err != nil {
+		return nil, nil, err
+	}
+	targetFiles := map[string]paths.Metadata{}
+	for metadata := range indexed {
+		if isRestricted(metadata.Path) {
+			targetFiles[metadata.Path] = metadata
+		}
+	}
+
+	return sortedMetadata(sourceFiles), sortedMetadata(targetFiles), nil
+}
+
+// sortedMetadata returns a channel emitting the given file metadata, sorted
+// by path as expected by paths.Diff.
+func sortedMetadata(files map[string]paths.Metadata) <-chan paths.Metadata {
+	sortedFiles := []paths.Metadata{}
+	for _, metadata := range files {
+		sortedFiles = append(sortedFiles, metadata)
+	}
+	sort.Slice(sortedFiles, func(i, j int) bool {
+		return sortedFiles[i].Path < sortedFiles[j].Path
+	})
+
+	c := make(chan paths.Metadata, len(sortedFiles))
+	for _, metadata := range sortedFiles {
+		c <- metadata
+	}
+	close(c)
+	return c
+}
+
 // noteAt parses a Note at the given path.
 func (t *indexTask) noteAt(path string) (Note, error) {
 	wrap := errors.Wrapper(path)
 
 	note := Note{
-		Path:  path,
-		Links: []Link{},
-		Tags:  []string{},
+		Path:     path,
+		Links:    []Link{},
+		Tags:     []string{},
+		Sections: []Section{},
 	}
 
 	absPath := filepath.Join(t.notebook.Path, path)
@@ -191,6 +411,7 @@ func (t *indexTask) noteAt(path string) (Note, error) {
 	note.WordCount = len(strings.Fields(contentStr))
 	note.Links = make([]Link, 0)
 	note.Tags = contentParts.Tags
+	note.Sections = slugifySections(contentParts.Sections)
 	note.Metadata = contentParts.Metadata
 	note.Checksum = fmt.Sprintf("%x", sha256.Sum256(content))
 
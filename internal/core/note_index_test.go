@@ -0,0 +1,330 @@
+package core
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mickael-menu/zk/internal/util"
+	"github.com/mickael-menu/zk/internal/util/paths"
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+)
+
+func TestIndexTaskMatchesSequentialIndexing(t *testing.T) {
+	date := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	test := newIndexTest(t)
+	defer test.close()
+
+	expectedCalls := []string{}
+	expectedRemovals := []string{}
+	for i := 0; i < 60; i++ {
+		path := fmt.Sprintf("note%02d.md", i)
+		content := fmt.Sprintf("Note %d", i)
+		failing := i%7 == 0
+		if failing {
+			content = "fail"
+		}
+
+		switch i % 4 {
+		case 0: // Unchanged
+			test.write(path, content, date)
+			test.index(path, date, "")
+		case 1: // Modified
+			test.write(path, content, date)
+			test.index(path, date.Add(-time.Hour), "")
+			if !failing {
+				expectedCalls = append(expectedCalls, "update "+path)
+			}
+		case 2: // Added
+			test.write(path, content, date)
+			if !failing {
+				expectedCalls = append(expectedCalls, "add "+path)
+			}
+		case 3: // Removed
+			test.index(path, date, "")
+			expectedRemovals = append(expectedRemovals, "remove "+path)
+		}
+	}
+	test.parser.errors["fail"] = fmt.Errorf("parsing failed")
+	expectedCalls = append(expectedCalls, expectedRemovals...)
+
+	seqCalls, seqStats := test.run(1)
+	assert.Equal(t, seqCalls, expectedCalls)
+	assert.Equal(t, seqStats, NoteIndexingStats{
+		SourceCount:   45,
+		AddedCount:    15,
+		ModifiedCount: 15,
+		RemovedCount:  15,
+	})
+
+	for _, workers := range []int{2, 8, 32} {
+		calls, stats := test.run(workers)
+		assert.Equal(t, calls, seqCalls)
+		assert.Equal(t, stats, seqStats)
+	}
+}
+
+func TestIndexTaskKeepsIDOfMovedNote(t *testing.T) {
+	date := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	test := newIndexTest(t)
+	defer test.close()
+	test.write("dir/renamed.md", "Moved content", date)
+	test.index("old.md", date, fmt.Sprintf("%x", sha256.Sum256([]byte("Moved content"))))
+	test.write("other.md", "Other content", date)
+	test.index("other.md", date, "")
+
+	index := newNoteIndexMock(test.indexed)
+	changes := []paths.DiffChange{}
+	stats, err := test.task(index, 0).execute(func(change paths.DiffChange) {
+		changes = append(changes, change)
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, stats.MovedCount, 1)
+	assert.Equal(t, stats.AddedCount, 0)
+	assert.Equal(t, stats.RemovedCount, 0)
+	assert.Equal(t, index.calls, []string{"move old.md dir/renamed.md", "update dir/renamed.md"})
+	assert.Equal(t, index.notes["dir/renamed.md"].id, test.indexed["old.md"].id)
+	assert.Equal(t, changes, []paths.DiffChange{
+		{Path: "dir/renamed.md", Kind: paths.DiffMoved},
+	})
+}
+
+func TestIndexTaskDoesntPairAmbiguousMoves(t *testing.T) {
+	date := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	checksum := fmt.Sprintf("%x", sha256.Sum256([]byte("Same content")))
+
+	// Several removed notes have the checksum of the added note.
+	test := newIndexTest(t)
+	defer test.close()
+	test.write("new.md", "Same content", date)
+	test.index("old1.md", date, checksum)
+	test.index("old2.md", date, checksum)
+
+	calls, stats := test.run(1)
+	assert.Equal(t, stats.MovedCount, 0)
+	assert.Equal(t, stats.AddedCount, 1)
+	assert.Equal(t, stats.RemovedCount, 2)
+	assert.Equal(t, calls, []string{"add new.md", "remove old1.md", "remove old2.md"})
+
+	// Several added notes have the checksum of the removed note.
+	test = newIndexTest(t)
+	defer test.close()
+	test.write("new1.md", "Same content", date)
+	test.write("new2.md", "Same content", date)
+	test.index("old.md", date, checksum)
+
+	calls, stats = test.run(1)
+	assert.Equal(t, stats.MovedCount, 0)
+	assert.Equal(t, stats.AddedCount, 2)
+	assert.Equal(t, stats.RemovedCount, 1)
+	assert.Equal(t, calls, []string{"add new1.md", "add new2.md", "remove old.md"})
+}
+
+// indexTest builds a notebook on the file system, along with a fake index
+// state, to run indexing tasks against it.
+type indexTest struct {
+	t       *testing.T
+	dir     string
+	parser  *noteParserMock
+	indexed map[string]indexedNoteMock
+}
+
+func newIndexTest(t *testing.T) *indexTest {
+	dir, err := ioutil.TempDir("", "zk-index")
+	assert.Nil(t, err)
+
+	return &indexTest{
+		t:       t,
+		dir:     dir,
+		parser:  newNoteParserMock(),
+		indexed: map[string]indexedNoteMock{},
+	}
+}
+
+func (t *indexTest) close() {
+	os.RemoveAll(t.dir)
+}
+
+// write creates a note file with the given modification date.
+func (t *indexTest) write(path string, content string, modified time.Time) {
+	absPath := filepath.Join(t.dir, path)
+	assert.Nil(t.t, os.MkdirAll(filepath.Dir(absPath), 0755))
+	assert.Nil(t.t, ioutil.WriteFile(absPath, []byte(content), 0644))
+	assert.Nil(t.t, os.Chtimes(absPath, modified, modified))
+}
+
+// index adds a note to the initial state of the index.
+func (t *indexTest) index(path string, modified time.Time, checksum string) {
+	t.indexed[path] = indexedNoteMock{
+		id:       NoteID(len(t.indexed) + 1),
+		modified: modified,
+		checksum: checksum,
+	}
+}
+
+// run indexes the notebook from the initial state of the index, and returns
+// the operations applied to the index.
+func (t *indexTest) run(workers int) ([]string, NoteIndexingStats) {
+	index := newNoteIndexMock(t.indexed)
+	stats, err := t.task(index, workers).execute(func(change paths.DiffChange) {})
+	assert.Nil(t.t, err)
+	stats.Duration = 0
+	return index.calls, stats
+}
+
+func (t *indexTest) task(index NoteIndex, workers int) *indexTask {
+	return &indexTask{
+		notebook: &Notebook{
+			Path:   t.dir,
+			Config: NewDefaultConfig(),
+			fs:     newFileStorageMock(t.dir, []string{}),
+			logger: &util.NullLogger,
+		},
+		index:   index,
+		parser:  t.parser,
+		logger:  &util.NullLogger,
+		workers: workers,
+	}
+}
+
+// noteIndexMock implements an in-memory NoteIndex recording the indexing
+// operations, for testing purposes.
+type noteIndexMock struct {
+	notes    map[string]indexedNoteMock
+	nextID   NoteID
+	counters map[string]int
+	calls    []string
+	closed   bool
+}
+
+type indexedNoteMock struct {
+	id       NoteID
+	modified time.Time
+	checksum string
+}
+
+func newNoteIndexMock(notes map[string]indexedNoteMock) *noteIndexMock {
+	index := &noteIndexMock{
+		notes:    map[string]indexedNoteMock{},
+		nextID:   1,
+		counters: map[string]int{},
+		calls:    []string{},
+	}
+	for path, note := range notes {
+		index.notes[path] = note
+		if note.id >= index.nextID {
+			index.nextID = note.id + 1
+		}
+	}
+	return index
+}
+
+func (i *noteIndexMock) Find(opts NoteFindOpts) ([]ContextualNote, error) {
+	panic("not implemented")
+}
+
+func (i *noteIndexMock) FindMinimal(opts NoteFindOpts) ([]MinimalNote, error) {
+	panic("not implemented")
+}
+
+func (i *noteIndexMock) FindLinksBetweenNotes(ids []NoteID) ([]ResolvedLink, error) {
+	panic("not implemented")
+}
+
+func (i *noteIndexMock) FindDeadLinks() ([]ResolvedLink, error) {
+	panic("not implemented")
+}
+
+func (i *noteIndexMock) FindSections(ids []NoteID) (map[NoteID][]Section, error) {
+	panic("not implemented")
+}
+
+func (i *noteIndexMock) FindCollections(kind CollectionKind, sorters []CollectionSorter) ([]Collection, error) {
+	panic("not implemented")
+}
+
+func (i *noteIndexMock) IndexedPaths() (<-chan paths.Metadata, error) {
+	files := map[string]paths.Metadata{}
+	for path, note := range i.notes {
+		files[path] = paths.Metadata{Path: path, Modified: note.modified}
+	}
+	return sortedMetadata(files), nil
+}
+
+func (i *noteIndexMock) IndexedChecksums() (map[string]string, error) {
+	checksums := map[string]string{}
+	for path, note := range i.notes {
+		checksums[path] = note.checksum
+	}
+	return checksums, nil
+}
+
+func (i *noteIndexMock) Add(note Note) (NoteID, error) {
+	id := i.nextID
+	i.nextID++
+	i.notes[note.Path] = indexedNoteMock{id: id, modified: note.Modified, checksum: note.Checksum}
+	i.calls = append(i.calls, "add "+note.Path)
+	return id, nil
+}
+
+func (i *noteIndexMock) Update(note Note) error {
+	indexed, ok := i.notes[note.Path]
+	if !ok {
+		return fmt.Errorf("%s: note not found", note.Path)
+	}
+	indexed.modified = note.Modified
+	indexed.checksum = note.Checksum
+	i.notes[note.Path] = indexed
+	i.calls = append(i.calls, "update "+note.Path)
+	return nil
+}
+
+func (i *noteIndexMock) Remove(path string) error {
+	delete(i.notes, path)
+	i.calls = append(i.calls, "remove "+path)
+	return nil
+}
+
+func (i *noteIndexMock) Move(oldPath string, newPath string) error {
+	note, ok := i.notes[oldPath]
+	if !ok {
+		return fmt.Errorf("%s: note not found", oldPath)
+	}
+	delete(i.notes, oldPath)
+	i.notes[newPath] = note
+	i.calls = append(i.calls, "move "+oldPath+" "+newPath)
+	return nil
+}
+
+func (i *noteIndexMock) Commit(transaction func(idx NoteIndex) error) error {
+	return transaction(i)
+}
+
+func (i *noteIndexMock) NeedsReindexing() (bool, error) {
+	return false, nil
+}
+
+func (i *noteIndexMock) SetNeedsReindexing(needsReindexing bool) error {
+	return nil
+}
+
+func (i *noteIndexMock) Counter(name string) (int, error) {
+	return i.counters[name], nil
+}
+
+func (i *noteIndexMock) SetCounter(name string, value int) error {
+	i.counters[name] = value
+	return nil
+}
+
+func (i *noteIndexMock) Close() error {
+	i.closed = true
+	return nil
+}
@@ -0,0 +1,387 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mickael-menu/zk/internal/util/errors"
+	"github.com/mickael-menu/zk/internal/util/paths"
+	strutil "github.com/mickael-menu/zk/internal/util/strings"
+)
+
+// NoteMove describes the relocation of a note in the notebook, with the edits
+// required to keep the links pointing to it up to date.
+type NoteMove struct {
+	// Current path of the note, relative to the notebook root.
+	From string
+	// Destination path of the note, relative to the notebook root.
+	To string
+	// Edits to apply to the notes linking to the moved note, and to the moved
+	// note itself.
+	Edits []TextEdit
+}
+
+// TextEdit represents the replacement of a range of text in a note.
+type TextEdit struct {
+	// Path of the edited note, relative to the notebook root. When editing the
+	// moved note itself, this is the path before the move.
+	Path string
+	// Start byte offset of the replaced text in the note content.
+	Start int
+	// End byte offset of the replaced text in the note content.
+	End int
+	// Text being replaced.
+	OldText string
+	// Replacement text.
+	NewText string
+}
+
+// PlanNoteMove computes the edits needed to move the note at path `from` to
+// `to`, without modifying the notebook.
+//
+// If `to` is an existing directory, the note keeps its filename.
+func (n *Notebook) PlanNoteMove(from string, to string) (NoteMove, error) {
+	return n.PlanNoteMoveWithContents(from, to, nil)
+}
+
+// PlanNoteMoveWithContents is like PlanNoteMove, but the edits of the notes
+// found in contents, indexed by their absolute path, are computed against
+// this content instead of the files on the disk. This is useful for editors
+// with unsaved buffers.
+func (n *Notebook) PlanNoteMoveWithContents(from string, to string, contents map[string]string) (NoteMove, error) {
+	wrap := errors.Wrapperf("%s: cannot move note", from)
+	move := NoteMove{Edits: []TextEdit{}}
+
+	from, err := n.RelPath(from)
+	if err != nil {
+		return move, wrap(err)
+	}
+	to, err = n.RelPath(to)
+	if err != nil {
+		return move, wrap(err)
+	}
+
+	isDir, err := n.fs.DirExists(filepath.Join(n.Path, to))
+	if err != nil {
+		return move, wrap(err)
+	}
+	if isDir {
+		to = filepath.Join(to, filepath.Base(from))
+	}
+	if from == to {
+		return move, wrap(fmt.Errorf("source and destination are the same"))
+	}
+
+	exists, err := n.fs.FileExists(filepath.Join(n.Path, to))
+	if err != nil {
+		return move, wrap(err)
+	}
+	if exists {
+		return move, wrap(fmt.Errorf("%s: file already exists", to))
+	}
+
+	note, err := n.FindByHref(from)
+	if err != nil {
+		return move, wrap(err)
+	}
+	if note == nil || note.Path != from {
+		return move, wrap(fmt.Errorf("note not found in the index"))
+	}
+
+	move.From = from
+	move.To = to
+
+	relinker := noteRelinker{notebook: n, from: from, to: to, contents: contents}
+
+	// Rewrite the inbound links.
+	sources, err := n.FindMinimalNotes(NoteFindOpts{
+		LinkTo: &LinkFilter{Paths: []string{from}},
+	})
+	if err != nil {
+		return move, wrap(err)
+	}
+	for _, source := range sources {
+		if source.Path == from {
+			continue
+		}
+		edits, err := relinker.editsFor(source.Path, filepath.Dir(source.Path))
+		if err != nil {
+			return move, wrap(err)
+		}
+		move.Edits = append(move.Edits, edits...)
+	}
+
+	// Rewrite the outbound links of the moved note, which are relative to its
+	// parent directory.
+	edits, err := relinker.editsFor(from, filepath.Dir(to))
+	if err != nil {
+		return move, wrap(err)
+	}
+	move.Edits = append(move.Edits, edits...)
+
+	return move, nil
+}
+
+// MoveNote applies the given NoteMove to the notebook: the note file is
+// moved, the linking notes are edited and its path is updated in the index,
+// so that it keeps the same ID.
+//
+// The linking notes are edited only once the note file was moved, and they
+// are restored if one of the edits fails.
+//
+// The edited notes are not reindexed, which is up to the caller.
+func (n *Notebook) MoveNote(move NoteMove) error {
+	wrap := errors.Wrapperf("%s: cannot move note", move.From)
+
+	from := filepath.Join(n.Path, move.From)
+	to := filepath.Join(n.Path, move.To)
+
+	exists, err := n.fs.FileExists(to)
+	if err != nil {
+		return wrap(err)
+	}
+	if exists {
+		return wrap(fmt.Errorf("%s: file already exists", move.To))
+	}
+	err = n.fs.Move(from, to)
+	if err != nil {
+		return wrap(err)
+	}
+
+	edits := map[string][]TextEdit{}
+	for _, edit := range move.Edits {
+		// The edits of the moved note refer to its path before the move.
+		path := edit.Path
+		if path == move.From {
+			path = move.To
+		}
+		edits[path] = append(edits[path], edit)
+	}
+
+	originals := map[string][]byte{}
+	rollback := func(err error) error {
+		for path, content := range originals {
+			n.logger.Err(n.fs.Write(path, content))
+		}
+		n.logger.Err(n.fs.Move(to, from))
+		return wrap(err)
+	}
+
+	for path, pathEdits := range edits {
+		absPath := filepath.Join(n.Path, path)
+		content, err := n.fs.Read(absPath)
+		if err != nil {
+			return rollback(err)
+		}
+		originals[absPath] = content
+		err = n.fs.Write(absPath, []byte(ApplyTextEdits(string(content), pathEdits)))
+		if err != nil {
+			return rollback(err)
+		}
+	}
+
+	return n.NoteMoved(from, to)
+}
+
+// NoteMoved updates the index after the note at path `from` was moved to
+// `to`, for example by an editor, so that it keeps the same ID.
+func (n *Notebook) NoteMoved(from string, to string) error {
+	wrap := errors.Wrapperf("%s: cannot move note", from)
+
+	from, err := n.RelPath(from)
+	if err != nil {
+		return wrap(err)
+	}
+	to, err = n.RelPath(to)
+	if err != nil {
+		return wrap(err)
+	}
+
+	err = n.index.Commit(func(index NoteIndex) error {
+		return index.Move(from, to)
+	})
+	return wrap(err)
+}
+
+// ApplyTextEdits returns the given content after applying the edits.
+// The edits must not overlap.
+func ApplyTextEdits(content string, edits []TextEdit) string {
+	edits = append([]TextEdit{}, edits...)
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].Start > edits[j].Start
+	})
+
+	for _, edit := range edits {
+		content = content[:edit.Start] + edit.NewText + content[edit.End:]
+	}
+	return content
+}
+
+// noteRelinker rewrites the hrefs of the links pointing to a moved note.
+type noteRelinker struct {
+	notebook *Notebook
+	from     string
+	to       string
+	// Content of the notes overriding the files on the disk, by absolute
+	// path.
+	contents map[string]string
+}
+
+// editsFor returns the edits to apply to the links found in the note at
+// `path`, once the note lives in the directory `newDir`.
+func (r *noteRelinker) editsFor(path string, newDir string) ([]TextEdit, error) {
+	edits := []TextEdit{}
+
+	content, err := r.read(path)
+	if err != nil {
+		return edits, err
+	}
+
+	parsed, err := r.notebook.parser.Parse(content)
+	if err != nil {
+		return edits, err
+	}
+
+	dir := filepath.Dir(path)
+
+	for _, link := range findLinkOccurrences(content, parsed.Links) {
+		href, _ := SplitHref(link.Href)
+		if href == "" || strutil.IsURL(href) {
+			continue
+		}
+
+		target := filepath.Join(dir, href)
+		retargeted := false
+		if strings.HasPrefix(r.from, target) {
+			// Prefix matching might resolve the href to a different note.
+			note, err := r.notebook.FindByHref(target)
+			if err != nil {
+				return edits, err
+			}
+			if note != nil && note.Path == r.from {
+				target = r.reshape(target)
+				retargeted = true
+			}
+		}
+		if !retargeted && newDir == dir {
+			continue
+		}
+
+		newHref, err := filepath.Rel(newDir, target)
+		if err != nil {
+			return edits, err
+		}
+		if newHref == filepath.Clean(href) {
+			continue
+		}
+
+		newText := r.format(newHref, link)
+		// Keep the anchor as it was written.
+		if _, anchor := SplitHref(link.Raw); anchor != "" {
+			newText += "#" + anchor
+		}
+		edits = append(edits, TextEdit{
+			Path:    path,
+			Start:   link.Start,
+			End:     link.End,
+			OldText: link.Raw,
+			NewText: newText,
+		})
+	}
+
+	return edits, nil
+}
+
+// read returns the content of the note at the given path, relative to the
+// notebook root.
+func (r *noteRelinker) read(path string) (string, error) {
+	absPath := filepath.Join(r.notebook.Path, path)
+	if content, ok := r.contents[absPath]; ok {
+		return content, nil
+	}
+	content, err := r.notebook.fs.Read(absPath)
+	return string(content), err
+}
+
+// reshape converts a href resolving to the old path of the note into one
+// resolving to its new path, keeping the same shape. For example a href
+// without extension stays without extension, and a href made of the ID
+// prefix of a filename stays an ID prefix.
+func (r *noteRelinker) reshape(target string) string {
+	suffix := strings.TrimPrefix(r.from, target)
+	if strings.HasSuffix(r.to, suffix) {
+		return strings.TrimSuffix(r.to, suffix)
+	}
+	if r.notebook.Config.Format.Markdown.LinkDropExtension {
+		return paths.DropExt(r.to)
+	}
+	return r.to
+}
+
+// format encodes or escapes the new href according to the style of the
+// original link and the notebook configuration.
+func (r *noteRelinker) format(href string, link linkOccurrence) string {
+	if r.notebook.Config.Format.Markdown.LinkEncodePath || link.IsEncoded {
+		return strings.ReplaceAll(url.PathEscape(href), "%2F", "/")
+	}
+
+	href = strings.ReplaceAll(href, `\`, `\\`)
+	if link.IsWikiLink {
+		href = strings.ReplaceAll(href, `]]`, `\]]`)
+	} else {
+		href = strings.ReplaceAll(href, `)`, `\)`)
+	}
+	return href
+}
+
+// linkOccurrence is a link found in the raw content of a note.
+type linkOccurrence struct {
+	// Decoded href of the link.
+	Href string
+	// Raw href, as written in the note.
+	Raw string
+	// Start byte offset of the raw href in the note content.
+	Start int
+	// End byte offset of the raw href in the note content.
+	End int
+	// Indicates whether this is a [[wiki link]].
+	IsWikiLink bool
+	// Indicates whether the raw href is percent-encoded.
+	IsEncoded bool
+}
+
+// findLinkOccurrences returns the occurrences of the given parsed links in
+// the note content, ignoring the links without a known href range.
+func findLinkOccurrences(content string, links []Link) []linkOccurrence {
+	occurrences := []linkOccurrence{}
+
+	for _, link := range links {
+		if link.HrefEnd <= link.HrefStart || link.HrefEnd > len(content) {
+			continue
+		}
+
+		occurrence := linkOccurrence{
+			Href:       link.Href,
+			Raw:        content[link.HrefStart:link.HrefEnd],
+			Start:      link.HrefStart,
+			End:        link.HrefEnd,
+			IsWikiLink: link.IsWikiLink,
+		}
+		// Valid Markdown links are percent-encoded.
+		if !link.IsWikiLink {
+			if href, err := url.PathUnescape(link.Href); err == nil {
+				occurrence.Href = href
+				occurrence.IsEncoded = href != link.Href
+			}
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].Start < occurrences[j].Start
+	})
+	return occurrences
+}
@@ -0,0 +1,175 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/mickael-menu/zk/internal/util"
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+)
+
+func TestFindLinkOccurrences(t *testing.T) {
+	content := `# Title
+
+A [[wiki link]] and [a markdown link](dir/note.md).
+[[[parent|With a label]]] and [[b#section]]
+[encoded](my%20note.md#anchor) and [external](https://example.com)
+[reference][ref]
+`
+	links := []Link{
+		{Href: "wiki link", HrefStart: 13, HrefEnd: 22, IsWikiLink: true},
+		{Href: "dir/note.md", HrefStart: 47, HrefEnd: 58},
+		{Href: "parent", HrefStart: 64, HrefEnd: 70, IsWikiLink: true},
+		{Href: "b#section", HrefStart: 93, HrefEnd: 102, IsWikiLink: true},
+		{Href: "my%20note.md#anchor", HrefStart: 115, HrefEnd: 134},
+		{Href: "https://example.com", HrefStart: 151, HrefEnd: 170},
+		// Reference links don't have an href range.
+		{Href: "ref.md"},
+	}
+
+	assert.Equal(t, findLinkOccurrences(content, links), []linkOccurrence{
+		{Href: "wiki link", Raw: "wiki link", Start: 13, End: 22, IsWikiLink: true},
+		{Href: "dir/note.md", Raw: "dir/note.md", Start: 47, End: 58},
+		{Href: "parent", Raw: "parent", Start: 64, End: 70, IsWikiLink: true},
+		{Href: "b#section", Raw: "b#section", Start: 93, End: 102, IsWikiLink: true},
+		{Href: "my note.md#anchor", Raw: "my%20note.md#anchor", Start: 115, End: 134, IsEncoded: true},
+		{Href: "https://example.com", Raw: "https://example.com", Start: 151, End: 170},
+	})
+}
+
+func TestApplyTextEdits(t *testing.T) {
+	content := "Link to [[old]] and [old](old.md)."
+	edits := []TextEdit{
+		{Start: 10, End: 13, NewText: "dir/new"},
+		{Start: 26, End: 32, NewText: "dir/new.md"},
+	}
+	assert.Equal(t, ApplyTextEdits(content, edits), "Link to [[dir/new]] and [old](dir/new.md).")
+	// The original edits are not reordered.
+	assert.Equal(t, edits[0].Start, 10)
+}
+
+func TestNoteRelinkerReshapesHref(t *testing.T) {
+	test := func(from, to, target string, dropExtension bool, expected string) {
+		relinker := noteRelinker{
+			notebook: &Notebook{Config: Config{Format: FormatConfig{Markdown: MarkdownConfig{
+				LinkDropExtension: dropExtension,
+			}}}},
+			from: from,
+			to:   to,
+		}
+		assert.Equal(t, relinker.reshape(target), expected)
+	}
+
+	// Full path
+	test("old.md", "dir/new.md", "old.md", false, "dir/new.md")
+	// Without extension
+	test("old.md", "dir/new.md", "old", false, "dir/new")
+	// ID prefix
+	test("a3f9 Old title.md", "archive/a3f9 Old title.md", "a3f9", false, "archive/a3f9")
+	// The shape cannot be preserved, fallback on the config.
+	test("a3f9 Old title.md", "new title.md", "a3f9", false, "new title.md")
+	test("a3f9 Old title.md", "new title.md", "a3f9", true, "new title")
+}
+
+func TestNoteRelinkerFormatsHref(t *testing.T) {
+	test := func(href string, link linkOccurrence, encodePath bool, expected string) {
+		relinker := noteRelinker{
+			notebook: &Notebook{Config: Config{Format: FormatConfig{Markdown: MarkdownConfig{
+				LinkEncodePath: encodePath,
+			}}}},
+		}
+		assert.Equal(t, relinker.format(href, link), expected)
+	}
+
+	test("dir/a note).md", linkOccurrence{}, false, `dir/a note\).md`)
+	test("dir/a note]].md", linkOccurrence{IsWikiLink: true}, false, `dir/a note\]].md`)
+	test("dir/a note.md", linkOccurrence{}, true, "dir/a%20note.md")
+	test("dir/a note.md", linkOccurrence{IsEncoded: true}, false, "dir/a%20note.md")
+}
+
+func TestMoveNoteFailsWhenTargetExists(t *testing.T) {
+	fs := newFileStorageMock("/notebook", []string{})
+	fs.files["/notebook/old.md"] = "Old"
+	fs.files["/notebook/new.md"] = "New"
+	fs.files["/notebook/a.md"] = "Link to [[old]]"
+	notebook := &Notebook{Path: "/notebook", fs: fs, logger: &util.NullLogger}
+
+	err := notebook.MoveNote(NoteMove{
+		From:  "old.md",
+		To:    "new.md",
+		Edits: []TextEdit{{Path: "a.md", Start: 10, End: 13, OldText: "old", NewText: "new"}},
+	})
+	assert.Err(t, err, "old.md: cannot move note: new.md: file already exists")
+
+	// The linking notes are left untouched.
+	assert.Equal(t, fs.files, map[string]string{
+		"/notebook/old.md": "Old",
+		"/notebook/new.md": "New",
+		"/notebook/a.md":   "Link to [[old]]",
+	})
+}
+
+func TestMoveNoteFailsWhenMoveFails(t *testing.T) {
+	fs := newFileStorageMock("/notebook", []string{})
+	fs.files["/notebook/a.md"] = "Link to [[old]]"
+	notebook := &Notebook{Path: "/notebook", fs: fs, logger: &util.NullLogger}
+
+	// The note file doesn't exist.
+	err := notebook.MoveNote(NoteMove{
+		From:  "old.md",
+		To:    "new.md",
+		Edits: []TextEdit{{Path: "a.md", Start: 10, End: 13, OldText: "old", NewText: "new"}},
+	})
+	assert.NotNil(t, err)
+
+	// The linking notes are left untouched.
+	assert.Equal(t, fs.files, map[string]string{
+		"/notebook/a.md": "Link to [[old]]",
+	})
+}
+
+func TestNoteRelinkerUsesGivenContents(t *testing.T) {
+	fs := newFileStorageMock("/notebook", []string{})
+	fs.files["/notebook/dir/old.md"] = "[[b]]"
+	parser := newNoteParserMock()
+	parser.results["Unsaved [[b]]"] = &ParsedNote{Links: []Link{
+		{Href: "b", HrefStart: 10, HrefEnd: 11, IsWikiLink: true},
+	}}
+	relinker := noteRelinker{
+		notebook: &Notebook{Path: "/notebook", fs: fs, parser: parser},
+		from:     "dir/old.md",
+		to:       "other/old.md",
+		contents: map[string]string{
+			"/notebook/dir/old.md": "Unsaved [[b]]",
+		},
+	}
+
+	edits, err := relinker.editsFor("dir/old.md", "other")
+	assert.Nil(t, err)
+	assert.Equal(t, edits, []TextEdit{
+		{Path: "dir/old.md", Start: 10, End: 11, OldText: "b", NewText: "../dir/b"},
+	})
+}
+
+func TestNoteRelinkerEditsParsedLinks(t *testing.T) {
+	content := "[x](old.md) [](old.md)\n\n```\n[code](old.md)\n```\n"
+	fs := newFileStorageMock("/notebook", []string{})
+	fs.files["/notebook/a.md"] = content
+	parser := newNoteParserMock()
+	// The parser doesn't report the links found in code blocks.
+	parser.results[content] = &ParsedNote{Links: []Link{
+		{Title: "x", Href: "old.md", HrefStart: 4, HrefEnd: 10},
+		{Title: "", Href: "old.md", HrefStart: 15, HrefEnd: 21},
+	}}
+	relinker := noteRelinker{
+		notebook: &Notebook{Path: "/notebook", fs: fs, parser: parser},
+		from:     "dir/old.md",
+		to:       "other/old.md",
+	}
+
+	edits, err := relinker.editsFor("a.md", "dir")
+	assert.Nil(t, err)
+	assert.Equal(t, edits, []TextEdit{
+		{Path: "a.md", Start: 4, End: 10, OldText: "old.md", NewText: "../old.md"},
+		{Path: "a.md", Start: 15, End: 21, OldText: "old.md", NewText: "../old.md"},
+	})
+}
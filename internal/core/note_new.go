@@ -1,66 +1,108 @@
 package core
 
 import (
+	"fmt"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/mickael-menu/zk/internal/util/errors"
 	"github.com/mickael-menu/zk/internal/util/opt"
 	"github.com/mickael-menu/zk/internal/util/paths"
 )
 
 type newNoteTask struct {
-	dir              Dir
-	title            string
-	content          string
-	date             time.Time
-	extra            map[string]string
-	env              map[string]string
-	fs               FileStorage
-	filenameTemplate string
-	bodyTemplatePath opt.String
-	templates        TemplateLoader
-	genID            IDGenerator
+	dir                Dir
+	filename           string
+	title              string
+	content            string
+	contentBody        string
+	contentMetadata    map[string]interface{}
+	contentFrontmatter string
+	date               time.Time
+	extra              map[string]string
+	env                map[string]string
+	fs                 FileStorage
+	filenameTemplate   string
+	bodyTemplatePath   opt.String
+	templates          TemplateLoader
+	genID              IDGenerator
+	onConflict         NoteConflictPolicy
 }
 
-func (t *newNoteTask) execute() (string, error) {
+// execute writes the note file and returns its path, and whether a new file
+// was created. An existing note might be opened or appended to instead,
+// according to the conflict policy.
+func (t *newNoteTask) execute() (string, bool, error) {
 	filenameTemplate, err := t.templates.LoadTemplate(t.filenameTemplate)
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
 
 	var contentTemplate Template = NullTemplate
 	if templatePath := t.bodyTemplatePath.Unwrap(); templatePath != "" {
 		contentTemplate, err = t.templates.LoadTemplateAt(templatePath)
 		if err != nil {
-			return "", err
+			return "", false, err
 		}
 	}
 
 	context := newNoteTemplateContext{
-		Title:   t.title,
-		Content: t.content,
-		Dir:     t.dir.Name,
-		Extra:   t.extra,
-		Now:     t.date,
-		Env:     t.env,
+		Title:           t.title,
+		Content:         t.content,
+		ContentBody:     t.contentBody,
+		ContentMetadata: t.contentMetadata,
+		Dir:             t.dir.Name,
+		Extra:           t.extra,
+		Now:             t.date,
+		Env:             t.env,
 	}
 
+	appending := false
 	path, context, err := t.generatePath(context, filenameTemplate)
 	if err != nil {
-		return "", err
+		var noteExists ErrNoteExists
+		if !errors.As(err, &noteExists) {
+			return "", false, err
+		}
+
+		switch t.onConflict {
+		case NoteConflictOpen:
+			return noteExists.Path, false, nil
+		case NoteConflictSuffix:
+			path, err = t.suffixedPath(noteExists.Path)
+			if err != nil {
+				return "", false, err
+			}
+		case NoteConflictAppend:
+			path = noteExists.Path
+			appending = true
+		default:
+			return "", false, err
+		}
+		context.Filename = filepath.Base(path)
+		context.FilenameStem = paths.FilenameStem(path)
 	}
 
 	content, err := contentTemplate.Render(context)
 	if err != nil {
-		return "", err
+		return "", false, err
+	}
+	content = mergeFrontmatter(content, t.contentFrontmatter)
+
+	if appending {
+		content, err = t.appendedContent(path, content)
+		if err != nil {
+			return "", false, err
+		}
 	}
 
 	err = t.fs.Write(path, []byte(content))
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
 
-	return path, nil
+	return path, !appending, nil
 }
 
 func (c *newNoteTask) generatePath(context newNoteTemplateContext, filenameTemplate Template) (string, newNoteTemplateContext, error) {
@@ -68,12 +110,21 @@ func (c *newNoteTask) generatePath(context newNoteTemplateContext, filenameTempl
 	var filename string
 	var path string
 
-	for i := 0; i < 50; i++ {
+	// Only one attempt with a fixed filename.
+	attempts := 50
+	if c.filename != "" {
+		attempts = 1
+	}
+
+	for i := 0; i < attempts; i++ {
 		context.ID = c.genID()
 
-		filename, err = filenameTemplate.Render(context)
-		if err != nil {
-			return "", context, err
+		filename = c.filename
+		if filename == "" {
+			filename, err = filenameTemplate.Render(context)
+			if err != nil {
+				return "", context, err
+			}
 		}
 
 		path = filepath.Join(c.dir.Path, filename)
@@ -93,15 +144,69 @@ func (c *newNoteTask) generatePath(context newNoteTemplateContext, filenameTempl
 	}
 }
 
+// suffixedPath returns the first free path made of the given one with a -2,
+// -3, etc. suffix before its extension.
+func (t *newNoteTask) suffixedPath(path string) (string, error) {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+
+	for i := 2; ; i++ {
+		path := fmt.Sprintf("%s-%d%s", base, i, ext)
+		exists, err := t.fs.FileExists(path)
+		if err != nil || !exists {
+			return path, err
+		}
+	}
+}
+
+// appendedContent returns the content of the existing note at the given path,
+// followed by the given content after a blank line.
+func (t *newNoteTask) appendedContent(path string, content string) (string, error) {
+	existing, err := t.fs.Read(path)
+	if err != nil {
+		return "", err
+	}
+
+	existingContent := strings.TrimRight(string(existing), "\n")
+	switch {
+	case existingContent == "":
+		return content, nil
+	case content == "":
+		return string(existing), nil
+	default:
+		return existingContent + "\n\n" + content, nil
+	}
+}
+
 // newNoteTemplateContext holds the placeholder values which will be expanded in the templates.
 type newNoteTemplateContext struct {
-	ID           string `handlebars:"id"`
-	Title        string
-	Content      string
-	Dir          string
-	Filename     string
-	FilenameStem string `handlebars:"filename-stem"`
-	Extra        map[string]string
-	Now          time.Time
-	Env          map[string]string
+	ID              string `handlebars:"id"`
+	Title           string
+	Content         string
+	ContentBody     string                 `handlebars:"content-body"`
+	ContentMetadata map[string]interface{} `handlebars:"content-metadata"`
+	Dir             string
+	Filename        string
+	FilenameStem    string `handlebars:"filename-stem"`
+	Extra           map[string]string
+	Now             time.Time
+	Env             map[string]string
+}
+
+// trimTitleHeading removes the heading opening the given content when it is
+// the title of the parsed note, as the templates render the title from
+// {{title}}. A heading found after other paragraphs is kept.
+func trimTitleHeading(content string, parsed *ParsedNote) string {
+	title := parsed.Title.Unwrap()
+	body := parsed.Body.Unwrap()
+	trimmed := strings.TrimSpace(content)
+	if title == "" || !strings.HasSuffix(trimmed, body) {
+		return content
+	}
+
+	heading := strings.TrimSpace(strings.TrimSuffix(trimmed, body))
+	if heading == "" || strings.Contains(heading, "\n\n") || !strings.Contains(heading, title) {
+		return content
+	}
+	return body
 }
@@ -37,28 +37,32 @@ func TestNotebookNewNote(t *testing.T) {
 	// Check that the templates received the proper render contexts.
 	assert.Equal(t, test.filenameTemplate.Contexts, []interface{}{
 		newNoteTemplateContext{
-			ID:           "id",
-			Title:        "Note title",
-			Content:      "Note content",
-			Dir:          "",
-			Filename:     "",
-			FilenameStem: "",
-			Extra:        map[string]string{"add-extra": "ec83da", "conf-extra": "38srnw"},
-			Now:          now,
-			Env:          map[string]string{"KEY1": "foo", "KEY2": "bar"},
+			ID:              "id",
+			Title:           "Note title",
+			Content:         "Note content",
+			ContentBody:     "Note content",
+			ContentMetadata: map[string]interface{}{},
+			Dir:             "",
+			Filename:        "",
+			FilenameStem:    "",
+			Extra:           map[string]string{"add-extra": "ec83da", "conf-extra": "38srnw"},
+			Now:             now,
+			Env:             map[string]string{"KEY1": "foo", "KEY2": "bar"},
 		},
 	})
 	assert.Equal(t, test.bodyTemplate.Contexts, []interface{}{
 		newNoteTemplateContext{
-			ID:           "id",
-			Title:        "Note title",
-			Content:      "Note content",
-			Dir:          "",
-			Filename:     "filename.ext",
-			FilenameStem: "filename",
-			Extra:        map[string]string{"add-extra": "ec83da", "conf-extra": "38srnw"},
-			Now:          now,
-			Env:          map[string]string{"KEY1": "foo", "KEY2": "bar"},
+			ID:              "id",
+			Title:           "Note title",
+			Content:         "Note content",
+			ContentBody:     "Note content",
+			ContentMetadata: map[string]interface{}{},
+			Dir:             "",
+			Filename:        "filename.ext",
+			FilenameStem:    "filename",
+			Extra:           map[string]string{"add-extra": "ec83da", "conf-extra": "38srnw"},
+			Now:             now,
+			Env:             map[string]string{"KEY1": "foo", "KEY2": "bar"},
 		},
 	})
 }
@@ -378,6 +382,372 @@ func TestNotebookNewNoteErrorWhenNoFreePath(t *testing.T) {
 	assert.Equal(t, test.fs.files, files)
 }
 
+func TestNotebookNewNoteWithFilename(t *testing.T) {
+	test := newNoteTest{
+		rootDir: "/notebook",
+	}
+	test.setup()
+
+	path, err := test.run(NewNoteOpts{
+		Title:    opt.NewString("Note title"),
+		Filename: opt.NewString("custom-name"),
+		Date:     now,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, path, "/notebook/custom-name.ext")
+	assert.Equal(t, test.fs.files[path], "body")
+	assert.Equal(t, test.filenameTemplate.Contexts, []interface{}{})
+}
+
+func TestNotebookNewNoteWithFilenameAndExtension(t *testing.T) {
+	test := newNoteTest{
+		rootDir: "/notebook",
+	}
+	test.setup()
+
+	path, err := test.run(NewNoteOpts{
+		Filename: opt.NewString("custom-name.txt"),
+		Date:     now,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, path, "/notebook/custom-name.txt")
+}
+
+func TestNotebookNewNoteErrorWhenFilenameExists(t *testing.T) {
+	files := map[string]string{
+		"/notebook/custom-name.ext": "file",
+	}
+	test := newNoteTest{
+		rootDir: "/notebook",
+		files:   files,
+	}
+	test.setup()
+
+	_, err := test.run(NewNoteOpts{
+		Filename: opt.NewString("custom-name"),
+		Date:     now,
+	})
+
+	assert.Err(t, err, "/notebook/custom-name.ext: note already exists")
+	assert.Equal(t, test.fs.files, files)
+}
+
+func TestNotebookNewNoteWithCounterID(t *testing.T) {
+	test := newNoteTest{
+		rootDir: "/notebook",
+		filenameTemplateRender: func(context newNoteTemplateContext) string {
+			return context.ID + ".ext"
+		},
+	}
+	test.setup()
+	test.config.Note.IDOptions = IDOptions{Kind: IDKindCounter, Length: 3}
+	test.index.counters["id"] = 4
+
+	path, err := test.run(NewNoteOpts{Date: now})
+	assert.Nil(t, err)
+	assert.Equal(t, path, "/notebook/005.ext")
+	assert.Equal(t, test.index.counters, map[string]int{"id": 5})
+
+	path, err = test.run(NewNoteOpts{Date: now})
+	assert.Nil(t, err)
+	assert.Equal(t, path, "/notebook/006.ext")
+	assert.Equal(t, test.index.counters, map[string]int{"id": 6})
+}
+
+// The IDs used by the attempts to find a free filename are lost.
+func TestNotebookNewNoteWithCounterIDSkipsExistingFiles(t *testing.T) {
+	test := newNoteTest{
+		rootDir: "/notebook",
+		files: map[string]string{
+			"/notebook/1.ext": "file",
+			"/notebook/2.ext": "file",
+		},
+		filenameTemplateRender: func(context newNoteTemplateContext) string {
+			return context.ID + ".ext"
+		},
+	}
+	test.setup()
+	test.config.Note.IDOptions = IDOptions{Kind: IDKindCounter}
+
+	path, err := test.run(NewNoteOpts{Date: now})
+	assert.Nil(t, err)
+	assert.Equal(t, path, "/notebook/3.ext")
+	assert.Equal(t, test.index.counters, map[string]int{"id": 3})
+}
+
+func TestNotebookNewNoteWithCounterIDNotSavedOnConflict(t *testing.T) {
+	files := map[string]string{
+		"/notebook/filename.ext": "file",
+	}
+	test := newNoteTest{
+		rootDir: "/notebook",
+		files:   files,
+	}
+	test.setup()
+	test.config.Note.IDOptions = IDOptions{Kind: IDKindCounter}
+	test.config.Note.OnConflict = NoteConflictAsk
+	test.index.counters["id"] = 4
+
+	_, err := test.run(NewNoteOpts{Date: now})
+	assert.Err(t, err, "filename.ext: note already exists")
+	assert.Equal(t, test.index.counters, map[string]int{"id": 4})
+	assert.Equal(t, test.fs.files, files)
+}
+
+// The counter is saved only when a new note file is written.
+func TestNotebookNewNoteWithCounterIDNotSavedForExistingNote(t *testing.T) {
+	test := func(policy NoteConflictPolicy) {
+		test := newNoteTest{
+			rootDir: "/notebook",
+			files: map[string]string{
+				"/notebook/filename.ext": "file\n",
+			},
+		}
+		test.setup()
+		test.config.Note.IDOptions = IDOptions{Kind: IDKindCounter}
+		test.config.Note.OnConflict = policy
+		test.index.counters["id"] = 4
+
+		_, err := test.run(NewNoteOpts{Date: now})
+		assert.Nil(t, err)
+		assert.Equal(t, test.index.counters, map[string]int{"id": 4})
+	}
+
+	test(NoteConflictOpen)
+	test(NoteConflictAppend)
+}
+
+func TestNotebookNewNoteOnConflictOpen(t *testing.T) {
+	files := map[string]string{
+		"/notebook/filename.ext": "file",
+	}
+	test := newNoteTest{
+		rootDir: "/notebook",
+		files:   files,
+	}
+	test.setup()
+	test.config.Note.OnConflict = NoteConflictOpen
+
+	path, err := test.run(NewNoteOpts{
+		Date: now,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, path, "/notebook/filename.ext")
+	assert.Equal(t, test.fs.files, files)
+}
+
+func TestNotebookNewNoteOnConflictSuffix(t *testing.T) {
+	test := newNoteTest{
+		rootDir: "/notebook",
+		files: map[string]string{
+			"/notebook/filename.ext":   "file",
+			"/notebook/filename-2.ext": "file2",
+		},
+	}
+	test.setup()
+	test.config.Note.OnConflict = NoteConflictSuffix
+
+	path, err := test.run(NewNoteOpts{
+		Date: now,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, path, "/notebook/filename-3.ext")
+	assert.Equal(t, test.fs.files[path], "body")
+	assert.Equal(t, test.fs.files["/notebook/filename.ext"], "file")
+
+	// The body template receives the suffixed filename.
+	context := test.bodyTemplate.Contexts[0].(newNoteTemplateContext)
+	assert.Equal(t, context.Filename, "filename-3.ext")
+	assert.Equal(t, context.FilenameStem, "filename-3")
+}
+
+func TestNotebookNewNoteOnConflictAppend(t *testing.T) {
+	test := newNoteTest{
+		rootDir: "/notebook",
+		files: map[string]string{
+			"/notebook/filename.ext": "file\n",
+		},
+	}
+	test.setup()
+	test.config.Note.OnConflict = NoteConflictAppend
+
+	path, err := test.run(NewNoteOpts{
+		Date: now,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, path, "/notebook/filename.ext")
+	assert.Equal(t, test.fs.files[path], "file\n\nbody")
+}
+
+func TestNotebookNewNoteOnConflictWithGroup(t *testing.T) {
+	test := newNoteTest{
+		rootDir: "/notebook",
+		files: map[string]string{
+			"/notebook/filename.ext": "file",
+		},
+		groups: map[string]GroupConfig{
+			"log": {
+				Note: NoteConfig{
+					FilenameTemplate: "filename",
+					Extension:        "ext",
+					OnConflict:       NoteConflictSuffix,
+				},
+			},
+		},
+	}
+	test.setup()
+
+	path, err := test.run(NewNoteOpts{
+		Group: opt.NewString("log"),
+		Date:  now,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, path, "/notebook/filename-2.ext")
+}
+
+func TestNotebookNewNoteOnConflictOverriddenByOpts(t *testing.T) {
+	files := map[string]string{
+		"/notebook/filename.ext": "file",
+	}
+	test := newNoteTest{
+		rootDir: "/notebook",
+		files:   files,
+	}
+	test.setup()
+	test.config.Note.OnConflict = NoteConflictAppend
+
+	path, err := test.run(NewNoteOpts{
+		Date:       now,
+		OnConflict: NoteConflictOpen,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, path, "/notebook/filename.ext")
+	assert.Equal(t, test.fs.files, files)
+}
+
+func TestNotebookNewNoteWithTemplateVariables(t *testing.T) {
+	test := newNoteTest{
+		rootDir: "/notebook",
+	}
+	test.setup()
+	test.config.Variables = map[string]TemplateVariable{
+		"severity": {Type: TemplateVariableString, Required: true},
+		"owner":    {Type: TemplateVariableString, Default: opt.NewString("ops")},
+	}
+
+	_, err := test.run(NewNoteOpts{
+		Extra: map[string]string{"owner": "alice"},
+		PromptVariable: func(name string, variable TemplateVariable) (string, error) {
+			return "high", nil
+		},
+		Date: now,
+	})
+
+	assert.Nil(t, err)
+	context := test.bodyTemplate.Contexts[0].(newNoteTemplateContext)
+	assert.Equal(t, context.Extra, map[string]string{
+		"conf-extra": "38srnw",
+		"owner":      "alice",
+		"severity":   "high",
+	})
+}
+
+func TestNotebookNewNoteErrorWhenMissingTemplateVariable(t *testing.T) {
+	test := newNoteTest{
+		rootDir: "/notebook",
+	}
+	test.setup()
+	test.config.Variables = map[string]TemplateVariable{
+		"severity": {Type: TemplateVariableString, Required: true},
+	}
+
+	_, err := test.run(NewNoteOpts{
+		Date: now,
+	})
+
+	assert.Err(t, err, "new note: missing value for the required template variables: severity")
+	assert.Equal(t, test.fs.files, map[string]string{})
+}
+
+func TestNotebookNewNoteWithParsedContent(t *testing.T) {
+	test := newNoteTest{
+		rootDir: "/notebook",
+	}
+	test.setup()
+	content := "---\nauthor: Alice\n---\n\n# Piped title\nBody"
+	test.parser.results[content] = &ParsedNote{
+		Title:    opt.NewString("Piped title"),
+		Body:     opt.NewString("Body"),
+		Metadata: map[string]interface{}{"author": "Alice"},
+	}
+
+	path, err := test.run(NewNoteOpts{
+		Content: content,
+		Date:    now,
+	})
+
+	assert.Nil(t, err)
+	context := test.bodyTemplate.Contexts[0].(newNoteTemplateContext)
+	assert.Equal(t, context.Title, "Piped title")
+	// The title heading is rendered by the template.
+	assert.Equal(t, context.Content, "Body")
+	assert.Equal(t, context.ContentBody, "Body")
+	assert.Equal(t, context.ContentMetadata, map[string]interface{}{"author": "Alice"})
+
+	// The frontmatter of the content is merged into the note.
+	assert.Equal(t, test.fs.files[path], "---\nauthor: Alice\n---\n\nbody")
+}
+
+func TestNotebookNewNoteTitleTakesPrecedenceOverParsedContent(t *testing.T) {
+	test := newNoteTest{
+		rootDir: "/notebook",
+	}
+	test.setup()
+	test.parser.results["# Piped title"] = &ParsedNote{
+		Title: opt.NewString("Piped title"),
+	}
+
+	_, err := test.run(NewNoteOpts{
+		Title:   opt.NewString("Note title"),
+		Content: "# Piped title",
+		Date:    now,
+	})
+
+	assert.Nil(t, err)
+	context := test.bodyTemplate.Contexts[0].(newNoteTemplateContext)
+	assert.Equal(t, context.Title, "Note title")
+	assert.Equal(t, context.Content, "# Piped title")
+}
+
+func TestNotebookNewNoteKeepsTitleHeadingAfterParagraphs(t *testing.T) {
+	test := newNoteTest{
+		rootDir: "/notebook",
+	}
+	test.setup()
+	content := "Intro\n\n# Piped title\nBody"
+	test.parser.results[content] = &ParsedNote{
+		Title: opt.NewString("Piped title"),
+		Body:  opt.NewString("Body"),
+	}
+
+	_, err := test.run(NewNoteOpts{
+		Content: content,
+		Date:    now,
+	})
+
+	assert.Nil(t, err)
+	context := test.bodyTemplate.Contexts[0].(newNoteTemplateContext)
+	assert.Equal(t, context.Title, "Piped title")
+	assert.Equal(t, context.Content, content)
+}
+
 var now = time.Date(2009, 11, 17, 20, 34, 58, 651387237, time.UTC)
 
 // newNoteTest builds and runs the SUT for new note test cases.
@@ -386,6 +756,8 @@ type newNoteTest struct {
 	files                  map[string]string
 	dirs                   []string
 	fs                     *fileStorageMock
+	index                  *noteIndexMock
+	parser                 *noteParserMock
 	config                 Config
 	groups                 map[string]GroupConfig
 	templateLoader         *templateLoaderMock
@@ -412,6 +784,10 @@ func (t *newNoteTest) setup() {
 		t.fs.files = t.files
 	}
 
+	if t.index == nil {
+		t.index = newNoteIndexMock(nil)
+	}
+	t.parser = newNoteParserMock()
 	t.templateLoader = newTemplateLoaderMock()
 	if t.filenameTemplateRender != nil {
 		t.filenameTemplate = t.templateLoader.Spy("filename.ext", func(context interface{}) string {
@@ -460,7 +836,11 @@ func (t *newNoteTest) setup() {
 }
 
 func (t *newNoteTest) run(opts NewNoteOpts) (string, error) {
-	notebook := NewNotebook(t.rootDir, t.config, NotebookPorts{
+	return t.notebook().NewNote(opts)
+}
+
+func (t *newNoteTest) notebook() *Notebook {
+	return NewNotebook(t.rootDir, t.config, NotebookPorts{
 		TemplateLoaderFactory: func(language string) (TemplateLoader, error) {
 			t.receivedLang = language
 			return t.templateLoader, nil
@@ -469,12 +849,12 @@ func (t *newNoteTest) run(opts NewNoteOpts) (string, error) {
 			t.receivedIDOpts = opts
 			return t.idGeneratorFactory(opts)
 		},
-		FS:     t.fs,
-		Logger: &util.NullLogger,
-		OSEnv:  func() map[string]string { return t.osEnv },
+		NoteIndex:  t.index,
+		NoteParser: t.parser,
+		FS:         t.fs,
+		Logger:     &util.NullLogger,
+		OSEnv:      func() map[string]string { return t.osEnv },
 	})
-
-	return notebook.NewNote(opts)
 }
 
 // incrementingID returns a generator of incrementing string ID.
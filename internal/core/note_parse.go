@@ -21,6 +21,9 @@ type ParsedNote struct {
 	Tags []string
 	// Links is the list of outbound links found in the note.
 	Links []Link
+	// Sections is the list of sections introduced by the headings of the
+	// note, in order of appearance. Their Slug is set when indexing the note.
+	Sections []Section
 	// Additional metadata. For example, extracted from a YAML frontmatter.
 	Metadata map[string]interface{}
 }
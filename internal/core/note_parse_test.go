@@ -0,0 +1,30 @@
+package core
+
+import "github.com/mickael-menu/zk/internal/util/opt"
+
+// noteParserMock implements a NoteParser returning predefined results or
+// errors, or the whole content as the body of the note.
+type noteParserMock struct {
+	results map[string]*ParsedNote
+	errors  map[string]error
+}
+
+func newNoteParserMock() *noteParserMock {
+	return &noteParserMock{
+		results: map[string]*ParsedNote{},
+		errors:  map[string]error{},
+	}
+}
+
+func (p *noteParserMock) Parse(content string) (*ParsedNote, error) {
+	if err, ok := p.errors[content]; ok {
+		return nil, err
+	}
+	if note, ok := p.results[content]; ok {
+		return note, nil
+	}
+	return &ParsedNote{
+		Body:     opt.NewNotEmptyString(content),
+		Metadata: map[string]interface{}{},
+	}, nil
+}
@@ -21,6 +21,7 @@ type Notebook struct {
 
 	index                 NoteIndex
 	parser                NoteParser
+	tagRewriter           TagRewriter
 	templateLoaderFactory TemplateLoaderFactory
 	idGeneratorFactory    IDGeneratorFactory
 	fs                    FileStorage
@@ -39,6 +40,7 @@ func NewNotebook(
 		Config:                config,
 		index:                 ports.NoteIndex,
 		parser:                ports.NoteParser,
+		tagRewriter:           ports.TagRewriter,
 		templateLoaderFactory: ports.TemplateLoaderFactory,
 		idGeneratorFactory:    ports.IDGeneratorFactory,
 		fs:                    ports.FS,
@@ -50,6 +52,7 @@ func NewNotebook(
 type NotebookPorts struct {
 	NoteIndex             NoteIndex
 	NoteParser            NoteParser
+	TagRewriter           TagRewriter
 	TemplateLoaderFactory TemplateLoaderFactory
 	IDGeneratorFactory    IDGeneratorFactory
 	FS                    FileStorage
@@ -90,22 +93,130 @@ func (n *Notebook) Index(force bool) (stats NoteIndexingStats, err error) {
 	return
 }
 
+// IndexPaths indexes only the notes at the given paths, relative to the
+// notebook root. Directories are indexed recursively and the notes which do
+// not exist anymore are removed from the index.
+func (n *Notebook) IndexPaths(notePaths []string) (stats NoteIndexingStats, err error) {
+	if len(notePaths) == 0 {
+		return
+	}
+
+	err = n.index.Commit(func(index NoteIndex) error {
+		task := indexTask{
+			notebook: n,
+			paths:    notePaths,
+			index:    index,
+			parser:   n.parser,
+			logger:   n.logger,
+		}
+		stats, err = task.execute(func(change paths.DiffChange) {})
+		return err
+	})
+
+	err = errors.Wrap(err, "indexing")
+	return
+}
+
+// watchDebounceDelay is the delay without any file change after which the
+// changed notes are indexed.
+const watchDebounceDelay = 300 * time.Millisecond
+
+// Watch keeps the index up to date with the changes made to the notebook
+// files, until the stop channel is closed. The callback is called after
+// indexing each batch of changes.
+func (n *Notebook) Watch(stop <-chan struct{}, callback func(stats NoteIndexingStats, err error)) error {
+	watcher, err := paths.Watch(n.Path, n.logger)
+	if err != nil {
+		return errors.Wrapf(err, "%s: failed to watch the notebook", n.Path)
+	}
+	defer watcher.Close()
+
+	changedPaths := []string{}
+	var debounce <-chan time.Time
+
+	for {
+		select {
+		case <-stop:
+			return nil
+
+		case path, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			changedPaths = append(changedPaths, path)
+			debounce = time.After(watchDebounceDelay)
+
+		case <-debounce:
+			stats, err := n.IndexPaths(changedPaths)
+			changedPaths = []string{}
+			debounce = nil
+			callback(stats, err)
+		}
+	}
+}
+
+// Close releases the resources held by the notebook, such as the
+// connection to its index.
+func (n *Notebook) Close() error {
+	return n.index.Close()
+}
+
+// shouldIgnorePath returns whether the file at the given path, relative to
+// the notebook root, is not a note and must not be indexed.
+func (n *Notebook) shouldIgnorePath(path string) (bool, error) {
+	group, err := n.Config.GroupConfigForPath(path)
+	if err != nil {
+		return true, err
+	}
+
+	if filepath.Ext(path) != "."+group.Note.Extension {
+		return true, nil
+	}
+
+	for _, ignoreGlob := range group.IgnoreGlobs() {
+		matches, err := filepath.Match(ignoreGlob, path)
+		if err != nil {
+			return true, errors.Wrapf(err, "failed to match ignore glob %s to %s", ignoreGlob, path)
+		}
+		if matches {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 // NewNoteOpts holds the options used to create a new note in a Notebook.
 type NewNoteOpts struct {
 	// Title of the new note.
 	Title opt.String
-	// Initial content of the note.
+	// Initial content of the note. Its title heading and frontmatter are
+	// parsed to fill the title and frontmatter of the new note, and removed
+	// from the content.
 	Content string
 	// Directory in which to create the note, relative to the root of the notebook.
 	Directory opt.String
+	// Filename of the note in its directory, instead of the one generated
+	// from the filename template. The default extension is appended when
+	// missing.
+	Filename opt.String
 	// Group this note belongs to.
 	Group opt.String
+	// Path or Folgezettel ID of the parent note, used to generate
+	// Folgezettel IDs.
+	Parent opt.String
 	// Path to a custom template used to render the note.
 	Template opt.String
 	// Extra variables passed to the templates.
 	Extra map[string]string
+	// Prompts the user for the missing template variables declared in the
+	// config. Their default value is used when nil.
+	PromptVariable TemplateVariablePrompter
 	// Creation date provided to the templates.
 	Date time.Time
+	// Policy applied when the filename is already taken, instead of the one
+	// of the group.
+	OnConflict NoteConflictPolicy
 }
 
 // ErrNoteExists is an error returned when a note already exists with the
@@ -121,7 +232,10 @@ func (e ErrNoteExists) Error() string {
 
 // NewNote generates a new note in the notebook and returns its path.
 //
-// Returns ErrNoteExists if no free filename can be generated for this note.
+// When no free filename can be generated for this note, the conflict policy
+// of its group decides whether to use the existing note, to suffix the
+// filename or to append the content to the existing note. Returns
+// ErrNoteExists with the `ask` policy.
 func (n *Notebook) NewNote(opts NewNoteOpts) (string, error) {
 	wrap := errors.Wrapper("new note")
 
@@ -130,38 +244,139 @@ func (n *Notebook) NewNote(opts NewNoteOpts) (string, error) {
 		return "", wrap(err)
 	}
 
-	config, err := n.Config.GroupConfigNamed(opts.Group.OrString(dir.Group).Unwrap())
+	group := opts.Group.OrString(dir.Group).Unwrap()
+	config, err := n.Config.GroupConfigNamed(group)
 	if err != nil {
 		return "", wrap(err)
 	}
 
-	extra := config.Extra
+	genID, saveID, err := n.newIDGenerator(group, config.Note.IDOptions, opts)
+	if err != nil {
+		return "", wrap(err)
+	}
+
+	extra := map[string]string{}
+	for k, v := range config.Extra {
+		extra[k] = v
+	}
 	for k, v := range opts.Extra {
 		extra[k] = v
 	}
+	err = resolveTemplateVariables(config.Variables, extra, opts.PromptVariable)
+	if err != nil {
+		return "", wrap(err)
+	}
 
 	templates, err := n.templateLoaderFactory(config.Note.Lang)
 	if err != nil {
 		return "", wrap(err)
 	}
 
+	filename := opts.Filename.Unwrap()
+	if filename != "" && filepath.Ext(filename) == "" {
+		filename += "." + config.Note.Extension
+	}
+
+	title := opts.Title
+	content := opts.Content
+	var contentBody string
+	var contentMetadata map[string]interface{}
+	var contentFrontmatter string
+	if content != "" {
+		parsed, err := n.ParseNote(content)
+		if err != nil {
+			return "", wrap(err)
+		}
+		title = title.Or(parsed.Title)
+		contentBody = parsed.Body.Unwrap()
+		contentMetadata = parsed.Metadata
+		contentFrontmatter, content = splitFrontmatter(content)
+		if opts.Title.IsNull() {
+			content = trimTitleHeading(content, parsed)
+		}
+	}
+
 	task := newNoteTask{
-		dir:              dir,
-		title:            opts.Title.OrString(config.Note.DefaultTitle).Unwrap(),
-		content:          opts.Content,
-		date:             opts.Date,
-		extra:            extra,
-		env:              n.osEnv(),
-		fs:               n.fs,
-		filenameTemplate: config.Note.FilenameTemplate + "." + config.Note.Extension,
-		bodyTemplatePath: opts.Template.Or(config.Note.BodyTemplatePath),
-		templates:        templates,
-		genID:            n.idGeneratorFactory(config.Note.IDOptions),
-	}
-	path, err := task.execute()
+		dir:                dir,
+		filename:           filename,
+		title:              title.OrString(config.Note.DefaultTitle).Unwrap(),
+		content:            content,
+		contentBody:        contentBody,
+		contentMetadata:    contentMetadata,
+		contentFrontmatter: contentFrontmatter,
+		date:               opts.Date,
+		extra:              extra,
+		env:                n.osEnv(),
+		fs:                 n.fs,
+		filenameTemplate:   config.Note.FilenameTemplate + "." + config.Note.Extension,
+		bodyTemplatePath:   opts.Template.Or(config.Note.BodyTemplatePath),
+		templates:          templates,
+		genID:              genID,
+		onConflict:         config.Note.OnConflict,
+	}
+	if opts.OnConflict != "" {
+		task.onConflict = opts.OnConflict
+	}
+	path, created, err := task.execute()
+	if err != nil {
+		return "", wrap(err)
+	}
+
+	// The ID is only consumed by a new note file.
+	if created {
+		err = saveID()
+	}
 	return path, wrap(err)
 }
 
+// newIDGenerator creates the generator of the IDs of a new note in the given
+// group. The returned save function persists the state of the generator once
+// the note is created.
+func (n *Notebook) newIDGenerator(group string, idOpts IDOptions, opts NewNoteOpts) (IDGenerator, func() error, error) {
+	save := func() error { return nil }
+
+	switch idOpts.Kind {
+	case IDKindCounter:
+		// Each group has its own counter.
+		name := "id"
+		if group != "" {
+			name += "." + group
+		}
+		counter, err := newCounterIDGenerator(n.index, name, idOpts.Length)
+		if err != nil {
+			return nil, nil, err
+		}
+		return counter.next, counter.save, nil
+
+	case IDKindTimestamp:
+		return newTimestampIDGenerator(opts.Date, idOpts.Layout), save, nil
+
+	case IDKindFolgezettel:
+		parent := ""
+		if parentOpt := opts.Parent.Unwrap(); parentOpt != "" {
+			parent = FolgezettelID(parentOpt)
+			if parent == "" {
+				return nil, nil, fmt.Errorf("%s: not a Folgezettel ID", parentOpt)
+			}
+		}
+
+		notes, err := n.FindMinimalNotes(NoteFindOpts{})
+		if err != nil {
+			return nil, nil, err
+		}
+		ids := []string{}
+		for _, note := range notes {
+			if id := FolgezettelID(note.Path); id != "" {
+				ids = append(ids, id)
+			}
+		}
+		return newFolgezettelIDGenerator(parent, ids), save, nil
+
+	default:
+		return n.idGeneratorFactory(idOpts), save, nil
+	}
+}
+
 // FindNotes retrieves the notes matching the given filtering options.
 func (n *Notebook) FindNotes(opts NoteFindOpts) ([]ContextualNote, error) {
 	return n.index.Find(opts)
@@ -197,9 +412,46 @@ func (n *Notebook) FindByHref(href string) (*MinimalNote, error) {
 	}
 }
 
-// FindCollections retrieves all the collections of the given kind.
-func (n *Notebook) FindCollections(kind CollectionKind) ([]Collection, error) {
-	return n.index.FindCollections(kind)
+// FindLinksBetweenNotes retrieves the links between the given notes.
+func (n *Notebook) FindLinksBetweenNotes(ids []NoteID) ([]ResolvedLink, error) {
+	return n.index.FindLinksBetweenNotes(ids)
+}
+
+// FindSections retrieves the sections of the given notes.
+func (n *Notebook) FindSections(ids []NoteID) (map[NoteID][]Section, error) {
+	return n.index.FindSections(ids)
+}
+
+// ParseNote parses the given note content, for example from an unsaved
+// editor buffer.
+func (n *Notebook) ParseNote(content string) (*ParsedNote, error) {
+	parsed, err := n.parser.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	parsed.Sections = slugifySections(parsed.Sections)
+	return parsed, nil
+}
+
+// ParseSections parses the sections introduced by the headings of the given
+// note content, for example from an unsaved editor buffer.
+func (n *Notebook) ParseSections(content string) ([]Section, error) {
+	parsed, err := n.ParseNote(content)
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Sections, nil
+}
+
+// FindDeadLinks retrieves the internal links whose target could not be found.
+func (n *Notebook) FindDeadLinks() ([]ResolvedLink, error) {
+	return n.index.FindDeadLinks()
+}
+
+// FindCollections retrieves all the collections of the given kind, ordered
+// with the given sorters.
+func (n *Notebook) FindCollections(kind CollectionKind, sorters []CollectionSorter) ([]Collection, error) {
+	return n.index.FindCollections(kind, sorters)
 }
 
 // RelPath returns the path relative to the notebook root to the given path.
@@ -303,6 +555,21 @@ func (n *Notebook) NewNoteFormatter(templateString string) (NoteFormatter, error
 	return newNoteFormatter(n.Path, template, linkFormatter, n.osEnv(), n.fs)
 }
 
+// NewCollectionFormatter returns a CollectionFormatter used to format
+// collections with the given template.
+func (n *Notebook) NewCollectionFormatter(templateString string) (CollectionFormatter, error) {
+	templates, err := n.templateLoaderFactory(n.Config.Note.Lang)
+	if err != nil {
+		return nil, err
+	}
+	template, err := templates.LoadTemplate(templateString)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCollectionFormatter(template)
+}
+
 // NewLinkFormatter returns a LinkFormatter used to generate internal links between notes.
 func (n *Notebook) NewLinkFormatter() (LinkFormatter, error) {
 	templates, err := n.templateLoaderFactory(n.Config.Note.Lang)
@@ -0,0 +1,246 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mickael-menu/zk/internal/util/errors"
+	"github.com/mickael-menu/zk/internal/util/paths"
+)
+
+// NoteIssue is a problem found in a note when checking the health of a
+// notebook.
+type NoteIssue struct {
+	// Kind of issue.
+	Kind NoteIssueKind `json:"kind"`
+	// Path of the faulty note, relative to the notebook root.
+	Path string `json:"path"`
+	// Human readable description of the issue.
+	Message string `json:"message"`
+	// Href of the faulty link, for link-related issues.
+	Href string `json:"href,omitempty"`
+}
+
+// NoteIssueKind defines a kind of issue found in a note.
+type NoteIssueKind string
+
+const (
+	// The note links to a missing note.
+	NoteIssueDeadLink NoteIssueKind = "dead-link"
+	// The note links to a missing section of an existing note.
+	NoteIssueBrokenAnchor NoteIssueKind = "broken-anchor"
+	// The note content, such as its YAML frontmatter, could not be parsed.
+	NoteIssueParseError NoteIssueKind = "parse-error"
+	// Another note has the same title.
+	NoteIssueDuplicateTitle NoteIssueKind = "duplicate-title"
+	// No other note links to the note.
+	NoteIssueOrphan NoteIssueKind = "orphan"
+)
+
+// NoteIssueKinds lists all the kinds of issues reported by Notebook.Check.
+var NoteIssueKinds = []NoteIssueKind{
+	NoteIssueDeadLink,
+	NoteIssueBrokenAnchor,
+	NoteIssueParseError,
+	NoteIssueDuplicateTitle,
+	NoteIssueOrphan,
+}
+
+// NoteIssueKindFromString returns a NoteIssueKind from its string
+// representation.
+func NoteIssueKindFromString(str string) (NoteIssueKind, error) {
+	for _, kind := range NoteIssueKinds {
+		if string(kind) == str {
+			return kind, nil
+		}
+	}
+
+	strs := []string{}
+	for _, kind := range NoteIssueKinds {
+		strs = append(strs, string(kind))
+	}
+	return "", fmt.Errorf("%s: unknown kind of issue\ntry %s", str, strings.Join(strs, ", "))
+}
+
+// Check reports the issues of the given kinds found in the notebook, ordered
+// by note path. All the kinds of issues are checked if none is given.
+func (n *Notebook) Check(kinds []NoteIssueKind) ([]NoteIssue, error) {
+	wrap := errors.Wrapper("check failed")
+
+	task := checkTask{notebook: n, issues: []NoteIssue{}}
+	if len(kinds) == 0 {
+		kinds = NoteIssueKinds
+	}
+
+	for _, kind := range kinds {
+		var err error
+		switch kind {
+		case NoteIssueDeadLink:
+			err = task.checkDeadLinks()
+		case NoteIssueBrokenAnchor:
+			err = task.checkAnchors()
+		case NoteIssueParseError:
+			err = task.checkParsing()
+		case NoteIssueDuplicateTitle:
+			err = task.checkDuplicateTitles()
+		case NoteIssueOrphan:
+			err = task.checkOrphans()
+		}
+		if err != nil {
+			return nil, wrap(err)
+		}
+	}
+
+	sort.SliceStable(task.issues, func(i, j int) bool {
+		return task.issues[i].Path < task.issues[j].Path
+	})
+	return task.issues, nil
+}
+
+// checkTask collects the issues found in a notebook.
+type checkTask struct {
+	notebook *Notebook
+	issues   []NoteIssue
+	// Cache of all the indexed notes, ordered by path.
+	notes []ContextualNote
+}
+
+func (t *checkTask) report(kind NoteIssueKind, path string, href string, format string, args ...interface{}) {
+	t.issues = append(t.issues, NoteIssue{
+		Kind:    kind,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+		Href:    href,
+	})
+}
+
+func (t *checkTask) allNotes() ([]ContextualNote, error) {
+	if t.notes != nil {
+		return t.notes, nil
+	}
+
+	notes, err := t.notebook.FindNotes(NoteFindOpts{
+		Sorters: []NoteSorter{{Field: NoteSortPath, Ascending: true}},
+	})
+	t.notes = notes
+	return notes, err
+}
+
+func (t *checkTask) checkDeadLinks() error {
+	links, err := t.notebook.FindDeadLinks()
+	if err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		t.report(NoteIssueDeadLink, link.SourcePath, link.Href, "dead link to %s", link.Href)
+	}
+	return nil
+}
+
+func (t *checkTask) checkAnchors() error {
+	notes, err := t.allNotes()
+	if err != nil {
+		return err
+	}
+
+	notesByID := map[NoteID]ContextualNote{}
+	ids := []NoteID{}
+	for _, note := range notes {
+		notesByID[note.ID] = note
+		ids = append(ids, note.ID)
+	}
+
+	links, err := t.notebook.FindLinksBetweenNotes(ids)
+	if err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		path, anchor := SplitHref(link.Href)
+		if anchor == "" {
+			continue
+		}
+
+		targetID := link.TargetID
+		// Anchors without a path, e.g. [](#section), point to a section
+		// of the source note.
+		if path == "" || strings.HasSuffix(path, "/") {
+			targetID = link.SourceID
+		}
+
+		target, ok := notesByID[targetID]
+		if !ok {
+			continue
+		}
+
+		if FindSectionByAnchor(target.Sections, anchor) == nil {
+			t.report(NoteIssueBrokenAnchor, link.SourcePath, link.Href,
+				"section #%s not found in %s", anchor, target.Path,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (t *checkTask) checkParsing() error {
+	n := t.notebook
+	for metadata := range paths.Walk(n.Path, n.logger, n.shouldIgnorePath) {
+		content, err := n.fs.Read(filepath.Join(n.Path, metadata.Path))
+		if err != nil {
+			return err
+		}
+		_, err = n.parser.Parse(string(content))
+		if err != nil {
+			t.report(NoteIssueParseError, metadata.Path, "", "%v", err)
+		}
+	}
+	return nil
+}
+
+func (t *checkTask) checkDuplicateTitles() error {
+	notes, err := t.allNotes()
+	if err != nil {
+		return err
+	}
+
+	pathsByTitle := map[string][]string{}
+	for _, note := range notes {
+		if note.Title != "" {
+			pathsByTitle[note.Title] = append(pathsByTitle[note.Title], note.Path)
+		}
+	}
+
+	for _, note := range notes {
+		others := []string{}
+		for _, path := range pathsByTitle[note.Title] {
+			if path != note.Path {
+				others = append(others, path)
+			}
+		}
+		if len(others) > 0 {
+			t.report(NoteIssueDuplicateTitle, note.Path, "",
+				"title %q is also used by %s", note.Title, strings.Join(others, ", "),
+			)
+		}
+	}
+	return nil
+}
+
+func (t *checkTask) checkOrphans() error {
+	notes, err := t.notebook.FindMinimalNotes(NoteFindOpts{
+		Orphan:  true,
+		Sorters: []NoteSorter{{Field: NoteSortPath, Ascending: true}},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, note := range notes {
+		t.report(NoteIssueOrphan, note.Path, "", "no other note links to it")
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mickael-menu/zk/internal/util"
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+)
+
+func TestNoteIssueKindFromString(t *testing.T) {
+	kind, err := NoteIssueKindFromString("dead-link")
+	assert.Nil(t, err)
+	assert.Equal(t, kind, NoteIssueDeadLink)
+
+	kind, err = NoteIssueKindFromString("orphan")
+	assert.Nil(t, err)
+	assert.Equal(t, kind, NoteIssueOrphan)
+
+	_, err = NoteIssueKindFromString("foobar")
+	assert.Err(t, err, "foobar: unknown kind of issue")
+}
+
+func TestNotebookCheckDeadLinks(t *testing.T) {
+	test := newCheckTest(t)
+	defer test.close()
+	test.index.deadLinks = []ResolvedLink{
+		{Link: Link{Href: "missing"}, SourcePath: "b.md"},
+		{Link: Link{Href: "dir/other.md"}, SourcePath: "a.md"},
+	}
+
+	test.check([]NoteIssueKind{NoteIssueDeadLink}, []NoteIssue{
+		{Kind: NoteIssueDeadLink, Path: "a.md", Message: "dead link to dir/other.md", Href: "dir/other.md"},
+		{Kind: NoteIssueDeadLink, Path: "b.md", Message: "dead link to missing", Href: "missing"},
+	})
+}
+
+func TestNotebookCheckAnchors(t *testing.T) {
+	test := newCheckTest(t)
+	defer test.close()
+	test.index.notes = []ContextualNote{
+		{Note: Note{ID: 1, Path: "a.md", Sections: []Section{{Level: 1, Title: "Intro", Slug: "intro"}}}},
+		{Note: Note{ID: 2, Path: "dir/b.md", Sections: []Section{{Level: 2, Title: "Usage", Slug: "usage"}}}},
+	}
+	link := func(href string, sourceID NoteID, sourcePath string, targetID NoteID) ResolvedLink {
+		return ResolvedLink{Link: Link{Href: href}, SourceID: sourceID, SourcePath: sourcePath, TargetID: targetID}
+	}
+	test.index.links = []ResolvedLink{
+		// Without anchor.
+		link("dir/b", 1, "a.md", 2),
+		// Valid anchors, by slug or title.
+		link("dir/b#usage", 1, "a.md", 2),
+		link("a#Intro", 2, "dir/b.md", 1),
+		// Anchors to a section of the source note.
+		link("#intro", 1, "a.md", 0),
+		link("#missing", 2, "dir/b.md", 0),
+		// Broken anchor.
+		link("dir/b#install", 1, "a.md", 2),
+	}
+
+	test.check([]NoteIssueKind{NoteIssueBrokenAnchor}, []NoteIssue{
+		{Kind: NoteIssueBrokenAnchor, Path: "a.md", Message: "section #install not found in dir/b.md", Href: "dir/b#install"},
+		{Kind: NoteIssueBrokenAnchor, Path: "dir/b.md", Message: "section #missing not found in dir/b.md", Href: "#missing"},
+	})
+}
+
+func TestNotebookCheckParsing(t *testing.T) {
+	test := newCheckTest(t)
+	defer test.close()
+	test.write("a.md", "Valid")
+	test.write("dir/b.md", "Invalid")
+	test.write(".hidden.md", "Invalid")
+	test.parser.errors["Invalid"] = fmt.Errorf("invalid frontmatter")
+
+	test.check([]NoteIssueKind{NoteIssueParseError}, []NoteIssue{
+		{Kind: NoteIssueParseError, Path: "dir/b.md", Message: "invalid frontmatter"},
+	})
+}
+
+func TestNotebookCheckDuplicateTitles(t *testing.T) {
+	test := newCheckTest(t)
+	defer test.close()
+	test.index.notes = []ContextualNote{
+		{Note: Note{ID: 1, Path: "a.md", Title: "Same"}},
+		{Note: Note{ID: 2, Path: "b.md", Title: "Unique"}},
+		{Note: Note{ID: 3, Path: "c.md", Title: ""}},
+		{Note: Note{ID: 4, Path: "d.md", Title: ""}},
+		{Note: Note{ID: 5, Path: "dir/e.md", Title: "Same"}},
+	}
+
+	test.check([]NoteIssueKind{NoteIssueDuplicateTitle}, []NoteIssue{
+		{Kind: NoteIssueDuplicateTitle, Path: "a.md", Message: `title "Same" is also used by dir/e.md`},
+		{Kind: NoteIssueDuplicateTitle, Path: "dir/e.md", Message: `title "Same" is also used by a.md`},
+	})
+}
+
+func TestNotebookCheckOrphans(t *testing.T) {
+	test := newCheckTest(t)
+	defer test.close()
+	test.index.orphans = []MinimalNote{
+		{ID: 1, Path: "a.md"},
+		{ID: 3, Path: "c.md"},
+	}
+
+	test.check([]NoteIssueKind{NoteIssueOrphan}, []NoteIssue{
+		{Kind: NoteIssueOrphan, Path: "a.md", Message: "no other note links to it"},
+		{Kind: NoteIssueOrphan, Path: "c.md", Message: "no other note links to it"},
+	})
+}
+
+func TestNotebookCheckAllKinds(t *testing.T) {
+	test := newCheckTest(t)
+	defer test.close()
+	test.write("a.md", "Invalid")
+	test.parser.errors["Invalid"] = fmt.Errorf("invalid frontmatter")
+	test.index.notes = []ContextualNote{
+		{Note: Note{ID: 1, Path: "a.md", Title: "Same"}},
+		{Note: Note{ID: 2, Path: "b.md", Title: "Same"}},
+	}
+	test.index.links = []ResolvedLink{
+		{Link: Link{Href: "#missing"}, SourceID: 2, SourcePath: "b.md"},
+	}
+	test.index.deadLinks = []ResolvedLink{
+		{Link: Link{Href: "missing"}, SourcePath: "b.md"},
+	}
+	test.index.orphans = []MinimalNote{{ID: 1, Path: "a.md"}}
+
+	// The issues are ordered by path, then by kind.
+	test.check([]NoteIssueKind{}, []NoteIssue{
+		{Kind: NoteIssueParseError, Path: "a.md", Message: "invalid frontmatter"},
+		{Kind: NoteIssueDuplicateTitle, Path: "a.md", Message: `title "Same" is also used by b.md`},
+		{Kind: NoteIssueOrphan, Path: "a.md", Message: "no other note links to it"},
+		{Kind: NoteIssueDeadLink, Path: "b.md", Message: "dead link to missing", Href: "missing"},
+		{Kind: NoteIssueBrokenAnchor, Path: "b.md", Message: "section #missing not found in b.md", Href: "#missing"},
+		{Kind: NoteIssueDuplicateTitle, Path: "b.md", Message: `title "Same" is also used by a.md`},
+	})
+
+	// Only the requested kinds are checked.
+	test.check([]NoteIssueKind{NoteIssueOrphan, NoteIssueDeadLink}, []NoteIssue{
+		{Kind: NoteIssueOrphan, Path: "a.md", Message: "no other note links to it"},
+		{Kind: NoteIssueDeadLink, Path: "b.md", Message: "dead link to missing", Href: "missing"},
+	})
+}
+
+func TestNotebookCheckFailsWithIndexError(t *testing.T) {
+	test := newCheckTest(t)
+	defer test.close()
+	test.index.err = fmt.Errorf("database is locked")
+
+	_, err := test.notebook.Check([]NoteIssueKind{NoteIssueDeadLink})
+	assert.Err(t, err, "check failed: database is locked")
+}
+
+// checkTest runs Notebook.Check against a notebook on the file system, with
+// a fake index.
+type checkTest struct {
+	t        *testing.T
+	dir      string
+	fs       *fileStorageMock
+	parser   *noteParserMock
+	index    *checkIndexMock
+	notebook *Notebook
+}
+
+func newCheckTest(t *testing.T) *checkTest {
+	dir, err := ioutil.TempDir("", "zk-check")
+	assert.Nil(t, err)
+
+	fs := newFileStorageMock(dir, []string{})
+	parser := newNoteParserMock()
+	index := &checkIndexMock{
+		noteIndexMock: newNoteIndexMock(map[string]indexedNoteMock{}),
+		notes:         []ContextualNote{},
+		links:         []ResolvedLink{},
+		deadLinks:     []ResolvedLink{},
+		orphans:       []MinimalNote{},
+	}
+
+	return &checkTest{
+		t:      t,
+		dir:    dir,
+		fs:     fs,
+		parser: parser,
+		index:  index,
+		notebook: NewNotebook(dir, NewDefaultConfig(), NotebookPorts{
+			NoteIndex:  index,
+			NoteParser: parser,
+			FS:         fs,
+			Logger:     &util.NullLogger,
+		}),
+	}
+}
+
+func (t *checkTest) close() {
+	os.RemoveAll(t.dir)
+}
+
+// write creates a note file, on the disk and in the file storage.
+func (t *checkTest) write(path string, content string) {
+	absPath := filepath.Join(t.dir, path)
+	assert.Nil(t.t, os.MkdirAll(filepath.Dir(absPath), 0755))
+	assert.Nil(t.t, ioutil.WriteFile(absPath, []byte(content), 0644))
+	t.fs.files[absPath] = content
+}
+
+func (t *checkTest) check(kinds []NoteIssueKind, expected []NoteIssue) {
+	issues, err := t.notebook.Check(kinds)
+	assert.Nil(t.t, err)
+	assert.Equal(t.t, issues, expected)
+}
+
+// checkIndexMock is a NoteIndex returning the given notes and links.
+type checkIndexMock struct {
+	*noteIndexMock
+	notes     []ContextualNote
+	links     []ResolvedLink
+	deadLinks []ResolvedLink
+	orphans   []MinimalNote
+	err       error
+}
+
+func (i *checkIndexMock) Find(opts NoteFindOpts) ([]ContextualNote, error) {
+	return i.notes, i.err
+}
+
+func (i *checkIndexMock) FindMinimal(opts NoteFindOpts) ([]MinimalNote, error) {
+	if !opts.Orphan {
+		panic("not implemented")
+	}
+	return i.orphans, i.err
+}
+
+func (i *checkIndexMock) FindLinksBetweenNotes(ids []NoteID) ([]ResolvedLink, error) {
+	return i.links, i.err
+}
+
+func (i *checkIndexMock) FindDeadLinks() ([]ResolvedLink, error) {
+	return i.deadLinks, i.err
+}
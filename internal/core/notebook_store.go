@@ -3,6 +3,7 @@ package core
 import (
 	"fmt"
 	"path/filepath"
+	"sync"
 
 	"github.com/mickael-menu/zk/internal/util/errors"
 )
@@ -16,6 +17,8 @@ type NotebookStore struct {
 
 	// Cached opened notebooks.
 	notebooks map[string]*Notebook
+	// Guards the cache of notebooks, which can be opened concurrently.
+	mutex sync.Mutex
 }
 
 type NotebookStorePorts struct {
@@ -46,7 +49,8 @@ func (e ErrNotebookNotFound) Error() string {
 // Open returns a new Notebook instance for the notebook containing the
 // given file path.
 func (ns *NotebookStore) Open(path string) (*Notebook, error) {
-	wrap := errors.Wrapper("open failed")
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
 
 	path = ns.fs.Canonical(path)
 	nb := ns.cachedNotebookAt(path)
@@ -54,6 +58,40 @@ func (ns *NotebookStore) Open(path string) (*Notebook, error) {
 		return nb, nil
 	}
 
+	return ns.load(path)
+}
+
+// Reload returns a new Notebook instance for the notebook containing the
+// given file path, after reading its configuration file again.
+//
+// The new instance shares the index of the previous one, which can still be
+// used safely, e.g. by pending requests. The previous instance is kept in the
+// cache if the reload fails.
+func (ns *NotebookStore) Reload(path string) (*Notebook, error) {
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+
+	path = ns.fs.Canonical(path)
+	previous := ns.cachedNotebookAt(path)
+
+	nb, err := ns.load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if previous != nil && previous != nb && previous.Path == nb.Path {
+		// Closing the index of the previous instance would break its
+		// current users, so the new connection is dropped instead.
+		err = nb.Close()
+		nb.index = previous.index
+	}
+	return nb, err
+}
+
+// load opens the notebook containing the given path and caches it.
+func (ns *NotebookStore) load(path string) (*Notebook, error) {
+	wrap := errors.Wrapper("open failed")
+
 	path, err := ns.fs.Abs(path)
 	if err != nil {
 		return nil, wrap(err)
@@ -69,7 +107,7 @@ func (ns *NotebookStore) Open(path string) (*Notebook, error) {
 		return nil, wrap(err)
 	}
 
-	nb, err = ns.notebookFactory(path, config)
+	nb, err := ns.notebookFactory(path, config)
 	if err != nil {
 		return nil, wrap(err)
 	}
@@ -199,7 +237,18 @@ template = "default.md"
 #	"log.md"
 #]
 
-# Configure random ID generation.
+# Configure the ID generation.
+
+# The kind of IDs to generate, among:
+#   * random: random IDs made with the charset, length and case below
+#   * counter: increasing numbers, padded with zeros to the length below
+#   * timestamp: creation date of the note, formatted with id-layout
+#   * ulid: unique IDs sortable by creation time
+#   * folgezettel: Luhmann-style IDs derived from zk new --parent, e.g. 1a2b
+#id-kind = "random"
+
+# Go time layout used for the timestamp IDs.
+#id-layout = "200601021504"
 
 # The charset used for random IDs. You can use:
 #   * letters: only letters from a to z.
@@ -215,6 +264,13 @@ template = "default.md"
 # Letter case for the random IDs, among lower, upper or mixed.
 #id-case = "lower"
 
+# What to do when the filename of a new note is already taken, among:
+#   * ask: ask whether to edit the existing note
+#   * open: edit the existing note
+#   * suffix: append -2, -3, etc. to the filename
+#   * append: add the new content at the end of the existing note
+#on-conflict = "ask"
+
 
 # EXTRA VARIABLES
 #
@@ -224,6 +280,13 @@ template = "default.md"
 
 #key = "value"
 
+# Extra variables prompted when creating a new note, if they are missing.
+#[variables.author]
+#description = "Author of the note"
+#type = "string"
+#choices = ["Alice", "Bob"]
+#required = true
+
 
 # GROUP OVERRIDES
 #
@@ -316,6 +379,24 @@ multiword-tags = false
 #wiki-title = "hint"
 # Warn for dead links between notes.
 dead-link = "error"
+# Warn for links to missing #sections of existing notes.
+#broken-anchor = "error"
+# Report notes which are not linked by any other note.
+#orphan = "hint"
+# Report notes without any title.
+#missing-title = "warning"
+# Report tags which are not used by any other note, most likely typos.
+#unknown-tag = "warning"
+# Report links pointing to their own note.
+#self-link = "warning"
+
+[lsp.code-lens]
+# Show the number of backlinks at the top of each note.
+#backlinks = true
+
+[lsp.inlay-hints]
+# Show the title of the notes linked with a bare ID, e.g. [[a3f9]].
+#link-title = true
 
 
 # NAMED FILTERS
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+)
+
+func TestNotebookStoreReloadSharesIndex(t *testing.T) {
+	fs := newFileStorageMock("/notebook", []string{"/notebook/.zk"})
+	fs.files["/notebook/.zk/config.toml"] = `[note]
+language = "fr"
+`
+	indexes := []*noteIndexMock{}
+	store := NewNotebookStore(NewDefaultConfig(), NotebookStorePorts{
+		NotebookFactory: func(path string, config Config) (*Notebook, error) {
+			index := newNoteIndexMock(map[string]indexedNoteMock{})
+			indexes = append(indexes, index)
+			return NewNotebook(path, config, NotebookPorts{NoteIndex: index, FS: fs}), nil
+		},
+		FS: fs,
+	})
+
+	previous, err := store.Open("/notebook/a.md")
+	assert.Nil(t, err)
+	assert.Equal(t, previous.Config.Note.Lang, "fr")
+
+	fs.files["/notebook/.zk/config.toml"] = `[note]
+language = "de"
+`
+	notebook, err := store.Reload("/notebook/.zk/config.toml")
+	assert.Nil(t, err)
+	assert.Equal(t, notebook.Config.Note.Lang, "de")
+
+	// The reloaded notebook is cached.
+	cached, err := store.Open("/notebook/b.md")
+	assert.Nil(t, err)
+	assert.True(t, cached == notebook)
+
+	// The previous instance keeps working with the same index, while the
+	// connection opened by the reload is closed.
+	assert.Equal(t, len(indexes), 2)
+	assert.True(t, previous.index == indexes[0])
+	assert.True(t, notebook.index == indexes[0])
+	assert.False(t, indexes[0].closed)
+	assert.True(t, indexes[1].closed)
+}
+
+func TestNotebookStoreReloadKeepsPreviousOnError(t *testing.T) {
+	fs := newFileStorageMock("/notebook", []string{"/notebook/.zk"})
+	store := NewNotebookStore(NewDefaultConfig(), NotebookStorePorts{
+		NotebookFactory: func(path string, config Config) (*Notebook, error) {
+			return NewNotebook(path, config, NotebookPorts{NoteIndex: newNoteIndexMock(map[string]indexedNoteMock{}), FS: fs}), nil
+		},
+		FS: fs,
+	})
+
+	previous, err := store.Open("/notebook")
+	assert.Nil(t, err)
+
+	fs.files["/notebook/.zk/config.toml"] = "invalid = ["
+	notebook, err := store.Reload("/notebook/.zk/config.toml")
+	assert.NotNil(t, err)
+	assert.Nil(t, notebook)
+
+	cached, err := store.Open("/notebook")
+	assert.Nil(t, err)
+	assert.True(t, cached == previous)
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// Section is a part of a note introduced by a heading.
+type Section struct {
+	// Level of the heading, from 1 to 6.
+	Level int `json:"level"`
+	// Text of the heading.
+	Title string `json:"title"`
+	// Anchor used to link to the section, e.g. `my-heading` in
+	// [](note.md#my-heading).
+	Slug string `json:"slug"`
+	// Byte offset of the heading line in the note content.
+	Start int `json:"start"`
+	// Byte offset of the end of the section, which is the start of the next
+	// heading of the same or a higher level.
+	End int `json:"end"`
+}
+
+// MatchesAnchor returns whether the given #anchor links to this section.
+// Both the heading title and its GitHub-style slug are accepted, after
+// normalization.
+func (s Section) MatchesAnchor(anchor string) bool {
+	anchor = normalizeAnchor(strings.TrimPrefix(anchor, "#"))
+	return anchor != "" && (anchor == normalizeAnchor(s.Title) || anchor == s.Slug)
+}
+
+// FindSectionByAnchor returns the first section matching the given #anchor.
+func FindSectionByAnchor(sections []Section, anchor string) *Section {
+	for _, section := range sections {
+		if section.MatchesAnchor(anchor) {
+			return &section
+		}
+	}
+	return nil
+}
+
+// SectionAt returns the innermost section containing the given byte offset.
+func SectionAt(sections []Section, offset int) *Section {
+	var found *Section
+	for i, section := range sections {
+		if offset >= section.Start && offset < section.End &&
+			(found == nil || section.Level > found.Level) {
+			found = &sections[i]
+		}
+	}
+	return found
+}
+
+// normalizeAnchor decodes the given anchor for case-insensitive comparisons.
+func normalizeAnchor(anchor string) string {
+	if decoded, err := url.PathUnescape(anchor); err == nil {
+		anchor = decoded
+	}
+	return strings.ToLower(strings.TrimSpace(anchor))
+}
+
+// slugifyHeading generates the anchor of a heading with the same rules as
+// GitHub: lowercase, punctuation removed and spaces replaced by hyphens.
+func slugifyHeading(heading string) string {
+	var slug strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(heading)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsNumber(r) || r == '-' || r == '_':
+			slug.WriteRune(r)
+		case r == ' ':
+			slug.WriteRune('-')
+		}
+	}
+	return slug.String()
+}
+
+// slugifySections sets the slug of the given sections from their title.
+func slugifySections(sections []Section) []Section {
+	for i, section := range sections {
+		sections[i].Slug = slugifyHeading(section.Title)
+	}
+	return sections
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+)
+
+func TestSectionMatchesAnchor(t *testing.T) {
+	section := Section{Level: 2, Title: "Section 2.1: Ça marche!", Slug: "section-21-ça-marche"}
+
+	assert.True(t, section.MatchesAnchor("section-21-ça-marche"))
+	assert.True(t, section.MatchesAnchor("#section-21-ça-marche"))
+	assert.True(t, section.MatchesAnchor("Section 2.1: Ça marche!"))
+	assert.True(t, section.MatchesAnchor("section%202.1:%20%C3%A7a%20marche!"))
+	assert.False(t, section.MatchesAnchor(""))
+	assert.False(t, section.MatchesAnchor("section-2"))
+}
+
+func TestFindSectionByAnchor(t *testing.T) {
+	sections := []Section{
+		{Level: 1, Title: "Title", Slug: "title"},
+		{Level: 2, Title: "Usage", Slug: "usage"},
+		{Level: 3, Title: "Usage", Slug: "usage"},
+	}
+
+	assert.Equal(t, FindSectionByAnchor(sections, "usage"), &sections[1])
+	assert.Nil(t, FindSectionByAnchor(sections, "missing"))
+	assert.Nil(t, FindSectionByAnchor(nil, "usage"))
+}
+
+func TestSectionAt(t *testing.T) {
+	sections := []Section{
+		{Level: 1, Title: "Title", Start: 0, End: 100},
+		{Level: 2, Title: "Part 1", Start: 20, End: 60},
+		{Level: 3, Title: "Part 1.1", Start: 40, End: 60},
+		{Level: 2, Title: "Part 2", Start: 60, End: 100},
+	}
+
+	assert.Equal(t, SectionAt(sections, 0), &sections[0])
+	assert.Equal(t, SectionAt(sections, 19), &sections[0])
+	assert.Equal(t, SectionAt(sections, 20), &sections[1])
+	assert.Equal(t, SectionAt(sections, 45), &sections[2])
+	assert.Equal(t, SectionAt(sections, 60), &sections[3])
+	assert.Nil(t, SectionAt(sections, 100))
+	assert.Nil(t, SectionAt(nil, 0))
+}
+
+func TestNormalizeAnchor(t *testing.T) {
+	assert.Equal(t, normalizeAnchor("Section"), "section")
+	assert.Equal(t, normalizeAnchor("My%20Section"), "my section")
+	assert.Equal(t, normalizeAnchor(" a-b "), "a-b")
+}
+
+func TestSlugifyHeading(t *testing.T) {
+	test := func(heading string, expected string) {
+		assert.Equal(t, slugifyHeading(heading), expected)
+	}
+
+	test("", "")
+	test("A title", "a-title")
+	test("  Trimmed  ", "trimmed")
+	test("What's new? (v1.2)", "whats-new-v12")
+	test("snake_case and kebab-case", "snake_case-and-kebab-case")
+	test("Élan vital", "élan-vital")
+}
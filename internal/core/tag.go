@@ -0,0 +1,70 @@
+package core
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/mickael-menu/zk/internal/util/errors"
+)
+
+// TagRewriter renames or removes tags in the raw content of a note.
+type TagRewriter interface {
+	// RewriteTags returns the given note content after renaming its tags
+	// according to the mapping between the old and new tag names. A tag
+	// mapped to an empty string is removed.
+	RewriteTags(content string, mapping map[string]string) (string, error)
+}
+
+// RewriteTags renames or removes tags across the notes of the notebook. The
+// mapping associates the current tag names with their new names, or with an
+// empty string to remove a tag.
+//
+// Returns the paths of the modified notes, relative to the notebook root.
+// The notes are not written when dryRun is true. The modified notes are not
+// reindexed, which is up to the caller.
+func (n *Notebook) RewriteTags(mapping map[string]string, dryRun bool) ([]string, error) {
+	wrap := errors.Wrapper("failed to rewrite tags")
+	modified := []string{}
+
+	// The tags are matched by their exact name, as tag names may contain
+	// special glob characters or the separators of the Tags filter.
+	tags := []string{}
+	for tag := range mapping {
+		tags = append(tags, tag)
+	}
+	notes, err := n.FindMinimalNotes(NoteFindOpts{ExactTags: tags})
+	if err != nil {
+		return modified, wrap(err)
+	}
+
+	sortedPaths := []string{}
+	for _, note := range notes {
+		sortedPaths = append(sortedPaths, note.Path)
+	}
+	sort.Strings(sortedPaths)
+
+	for _, path := range sortedPaths {
+		absPath := filepath.Join(n.Path, path)
+		content, err := n.fs.Read(absPath)
+		if err != nil {
+			return modified, wrap(err)
+		}
+		newContent, err := n.tagRewriter.RewriteTags(string(content), mapping)
+		if err != nil {
+			return modified, errors.Wrapf(err, "%s: failed to rewrite tags", path)
+		}
+		if newContent == string(content) {
+			continue
+		}
+
+		modified = append(modified, path)
+		if !dryRun {
+			err = n.fs.Write(absPath, []byte(newContent))
+			if err != nil {
+				return modified, wrap(err)
+			}
+		}
+	}
+
+	return modified, nil
+}
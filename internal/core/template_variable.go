@@ -0,0 +1,127 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mickael-menu/zk/internal/util/opt"
+)
+
+// TemplateVariable declares an extra variable expected by the templates of
+// a group, which is prompted to the user when missing.
+type TemplateVariable struct {
+	// Type of the value.
+	Type TemplateVariableType
+	// Message displayed when prompting the user for the value.
+	Description string
+	// Value used when none is provided.
+	Default opt.String
+	// Allowed values, if restricted.
+	Choices []string
+	// Indicates that a new note can't be created without a value.
+	Required bool
+}
+
+// TemplateVariableType is the type of the value of a template variable.
+type TemplateVariableType string
+
+const (
+	TemplateVariableString  TemplateVariableType = "string"
+	TemplateVariableNumber  TemplateVariableType = "number"
+	TemplateVariableBoolean TemplateVariableType = "boolean"
+)
+
+var TemplateVariableTypes = []TemplateVariableType{
+	TemplateVariableString, TemplateVariableNumber, TemplateVariableBoolean,
+}
+
+// TemplateVariableTypeFromString returns the variable type matching the given
+// name.
+func TemplateVariableTypeFromString(str string) (TemplateVariableType, error) {
+	for _, t := range TemplateVariableTypes {
+		if string(t) == str {
+			return t, nil
+		}
+	}
+
+	strs := []string{}
+	for _, t := range TemplateVariableTypes {
+		strs = append(strs, string(t))
+	}
+	return "", fmt.Errorf("%s: unknown variable type\ntry %s", str, strings.Join(strs, ", "))
+}
+
+// Validate checks that the given value matches the type and choices of the
+// variable.
+func (v TemplateVariable) Validate(value string) error {
+	switch v.Type {
+	case TemplateVariableNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%s: expected a number", value)
+		}
+	case TemplateVariableBoolean:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%s: expected a boolean", value)
+		}
+	}
+
+	if len(v.Choices) > 0 {
+		for _, choice := range v.Choices {
+			if value == choice {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: invalid choice\ntry %s", value, strings.Join(v.Choices, ", "))
+	}
+	return nil
+}
+
+// TemplateVariablePrompter asks the user for the value of a template
+// variable. An empty value means that the user skipped it.
+type TemplateVariablePrompter func(name string, variable TemplateVariable) (string, error)
+
+// resolveTemplateVariables fills the extra variables with the value of the
+// declared variables which are missing, either by prompting the user or from
+// their default value.
+func resolveTemplateVariables(variables map[string]TemplateVariable, extra map[string]string, prompt TemplateVariablePrompter) error {
+	names := []string{}
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	missing := []string{}
+	for _, name := range names {
+		variable := variables[name]
+
+		value, ok := extra[name]
+		if !ok && prompt != nil {
+			var err error
+			value, err = prompt(name, variable)
+			if err != nil {
+				return err
+			}
+		}
+		if value == "" {
+			value = variable.Default.Unwrap()
+		}
+		if value == "" {
+			if variable.Required {
+				missing = append(missing, name)
+			}
+			continue
+		}
+
+		if err := variable.Validate(value); err != nil {
+			return fmt.Errorf("invalid value for the template variable %s: %w", name, err)
+		}
+		extra[name] = value
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing value for the required template variables: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/mickael-menu/zk/internal/util/opt"
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+)
+
+func TestTemplateVariableValidate(t *testing.T) {
+	test := func(variable TemplateVariable, value string, expectedErr string) {
+		err := variable.Validate(value)
+		if expectedErr == "" {
+			assert.Nil(t, err)
+		} else {
+			assert.Err(t, err, expectedErr)
+		}
+	}
+
+	str := TemplateVariable{Type: TemplateVariableString}
+	test(str, "anything", "")
+
+	number := TemplateVariable{Type: TemplateVariableNumber}
+	test(number, "42", "")
+	test(number, "-4.2", "")
+	test(number, "four", "four: expected a number")
+
+	boolean := TemplateVariable{Type: TemplateVariableBoolean}
+	test(boolean, "true", "")
+	test(boolean, "false", "")
+	test(boolean, "maybe", "maybe: expected a boolean")
+
+	choice := TemplateVariable{Type: TemplateVariableString, Choices: []string{"low", "high"}}
+	test(choice, "low", "")
+	test(choice, "medium", "medium: invalid choice\ntry low, high")
+}
+
+func TestResolveTemplateVariablesFromExtra(t *testing.T) {
+	variables := map[string]TemplateVariable{
+		"severity": {Type: TemplateVariableNumber, Required: true},
+	}
+	extra := map[string]string{"severity": "2"}
+
+	err := resolveTemplateVariables(variables, extra, func(name string, variable TemplateVariable) (string, error) {
+		t.Errorf("unexpected prompt for %s", name)
+		return "", nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, extra, map[string]string{"severity": "2"})
+}
+
+func TestResolveTemplateVariablesInvalidExtra(t *testing.T) {
+	variables := map[string]TemplateVariable{
+		"severity": {Type: TemplateVariableNumber},
+	}
+
+	err := resolveTemplateVariables(variables, map[string]string{"severity": "high"}, nil)
+	assert.Err(t, err, "invalid value for the template variable severity: high: expected a number")
+}
+
+func TestResolveTemplateVariablesPrompts(t *testing.T) {
+	variables := map[string]TemplateVariable{
+		"service":  {Type: TemplateVariableString, Required: true},
+		"owner":    {Type: TemplateVariableString, Default: opt.NewString("ops")},
+		"severity": {Type: TemplateVariableString},
+	}
+	extra := map[string]string{"service": "api"}
+
+	prompted := []string{}
+	err := resolveTemplateVariables(variables, extra, func(name string, variable TemplateVariable) (string, error) {
+		prompted = append(prompted, name)
+		if name == "severity" {
+			return "high", nil
+		}
+		// Skipped by the user.
+		return "", nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, prompted, []string{"owner", "severity"})
+	assert.Equal(t, extra, map[string]string{
+		"service":  "api",
+		"owner":    "ops",
+		"severity": "high",
+	})
+}
+
+func TestResolveTemplateVariablesWithoutPrompt(t *testing.T) {
+	variables := map[string]TemplateVariable{
+		"owner":    {Type: TemplateVariableString, Default: opt.NewString("ops")},
+		"severity": {Type: TemplateVariableString},
+	}
+	extra := map[string]string{}
+
+	err := resolveTemplateVariables(variables, extra, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, extra, map[string]string{"owner": "ops"})
+}
+
+func TestResolveTemplateVariablesMissingRequired(t *testing.T) {
+	variables := map[string]TemplateVariable{
+		"service":  {Type: TemplateVariableString, Required: true},
+		"owner":    {Type: TemplateVariableString, Required: true},
+		"severity": {Type: TemplateVariableString, Required: true, Default: opt.NewString("low")},
+	}
+
+	err := resolveTemplateVariables(variables, map[string]string{}, nil)
+	assert.Err(t, err, "missing value for the required template variables: owner, service")
+}
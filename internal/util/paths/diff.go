@@ -20,6 +20,9 @@ const (
 	DiffAdded DiffKind = iota + 1
 	DiffModified
 	DiffRemoved
+	// DiffMoved is never reported by Diff, which sees a moved file as a
+	// removal and an addition. It is used by callers pairing them.
+	DiffMoved
 )
 
 // String implements Stringer.
@@ -31,6 +34,8 @@ func (k DiffKind) String() string {
 		return "~"
 	case DiffRemoved:
 		return "-"
+	case DiffMoved:
+		return ">"
 	default:
 		panic(fmt.Sprintf("%d: unknown DiffKind", int(k)))
 	}
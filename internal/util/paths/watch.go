@@ -0,0 +1,34 @@
+package paths
+
+import (
+	"strings"
+	"sync"
+)
+
+// Watcher reports the changes made to the files of a directory tree, until
+// closed. Hidden files and directories are ignored.
+type Watcher struct {
+	// Events receives the paths of the added, modified or removed files and
+	// directories, relative to the watched directory. An empty path means
+	// that the whole tree might have changed.
+	//
+	// The channel is closed when the Watcher is closed.
+	Events <-chan string
+
+	close     func() error
+	closeOnce sync.Once
+}
+
+// Close stops watching the directory tree. Closing an already closed Watcher
+// does nothing.
+func (w *Watcher) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		err = w.close()
+	})
+	return err
+}
+
+func isHidden(filename string) bool {
+	return strings.HasPrefix(filename, ".")
+}
@@ -0,0 +1,188 @@
+//go:build linux
+// +build linux
+
+package paths
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/mickael-menu/zk/internal/util"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_CLOSE_WRITE | syscall.IN_ATTRIB |
+	syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+
+// Watch starts watching recursively the directory at basePath, using inotify.
+func Watch(basePath string, logger util.Logger) (*Watcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+
+	w := &inotifyWatcher{
+		fd: fd,
+		// The file descriptor is non-blocking, so that closing the file
+		// interrupts any pending read.
+		file:     os.NewFile(uintptr(fd), "inotify"),
+		basePath: basePath,
+		dirs:     map[int]string{},
+		logger:   logger,
+		done:     make(chan struct{}),
+	}
+
+	err = w.addDir("")
+	if err != nil {
+		w.file.Close()
+		return nil, err
+	}
+
+	events := make(chan string, 50)
+	go w.run(events)
+
+	return &Watcher{
+		Events: events,
+		close: func() error {
+			close(w.done)
+			return w.file.Close()
+		},
+	}, nil
+}
+
+type inotifyWatcher struct {
+	fd       int
+	file     *os.File
+	basePath string
+	// Watched directories, relative to basePath, indexed by their inotify
+	// watch descriptor.
+	dirs   map[int]string
+	logger util.Logger
+	// Closed when the watcher is closed.
+	done chan struct{}
+}
+
+// addDir watches recursively the directory at the given path, relative to
+// basePath.
+func (w *inotifyWatcher) addDir(path string) error {
+	return filepath.Walk(filepath.Join(w.basePath, path), func(abs string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(w.basePath, abs)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			rel = ""
+		} else if isHidden(info.Name()) {
+			return filepath.SkipDir
+		}
+
+		// Watching a directory again returns its existing watch
+		// descriptor, which updates its path if it was moved.
+		wd, err := syscall.InotifyAddWatch(w.fd, abs, inotifyMask)
+		if err != nil {
+			return os.NewSyscallError("inotify_add_watch", err)
+		}
+		w.dirs[wd] = rel
+		return nil
+	})
+}
+
+// removeDir stops watching the directory at the given path, relative to
+// basePath, and its descendants. If the directory was moved inside basePath,
+// it is watched again when receiving IN_MOVED_TO.
+func (w *inotifyWatcher) removeDir(path string) {
+	for wd, dir := range w.dirs {
+		if dir == path || strings.HasPrefix(dir, path+string(filepath.Separator)) {
+			delete(w.dirs, wd)
+			_, err := syscall.InotifyRmWatch(w.fd, uint32(wd))
+			if err != nil && err != syscall.EINVAL {
+				w.logger.Err(os.NewSyscallError("inotify_rm_watch", err))
+			}
+		}
+	}
+}
+
+func (w *inotifyWatcher) run(events chan<- string) {
+	defer close(events)
+
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				w.logger.Err(err)
+			}
+			return
+		}
+
+		offset := 0
+		for offset+syscall.SizeofInotifyEvent <= n {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			nameEnd := nameStart + int(event.Len)
+			if nameEnd > n {
+				break
+			}
+			name := strings.TrimRight(string(buf[nameStart:nameEnd]), "\x00")
+			offset = nameEnd
+
+			if !w.handle(int(event.Wd), event.Mask, name, events) {
+				return
+			}
+		}
+	}
+}
+
+// handle processes an inotify event. Returns false if the watcher was closed.
+func (w *inotifyWatcher) handle(wd int, mask uint32, name string, events chan<- string) bool {
+	if mask&syscall.IN_Q_OVERFLOW != 0 {
+		// Some events were lost, the whole tree needs to be checked.
+		return w.send(events, "")
+	}
+
+	dir, ok := w.dirs[wd]
+	if !ok {
+		return true
+	}
+	if mask&syscall.IN_IGNORED != 0 {
+		// The directory was removed.
+		delete(w.dirs, wd)
+		return true
+	}
+	if name == "" || isHidden(name) {
+		return true
+	}
+
+	path := filepath.Join(dir, name)
+	if mask&syscall.IN_ISDIR != 0 {
+		if mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+			err := w.addDir(path)
+			if err != nil {
+				w.logger.Err(err)
+			}
+		} else if mask&syscall.IN_MOVED_FROM != 0 {
+			w.removeDir(path)
+		}
+	}
+
+	return w.send(events, path)
+}
+
+func (w *inotifyWatcher) send(events chan<- string, path string) bool {
+	select {
+	case events <- path:
+		return true
+	case <-w.done:
+		return false
+	}
+}
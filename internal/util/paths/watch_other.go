@@ -0,0 +1,74 @@
+//go:build !linux
+// +build !linux
+
+package paths
+
+import (
+	"time"
+
+	"github.com/mickael-menu/zk/internal/util"
+)
+
+// pollInterval is the delay between two scans of the watched directory.
+const pollInterval = 2 * time.Second
+
+// Watch starts watching recursively the directory at basePath, by scanning
+// it periodically.
+func Watch(basePath string, logger util.Logger) (*Watcher, error) {
+	events := make(chan string, 50)
+	stop := make(chan struct{})
+
+	scan := func() map[string]time.Time {
+		files := map[string]time.Time{}
+		for metadata := range Walk(basePath, logger, func(string) (bool, error) { return false, nil }) {
+			files[metadata.Path] = metadata.Modified
+		}
+		return files
+	}
+
+	previous := scan()
+
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				current := scan()
+				changed := []string{}
+				for path, modified := range current {
+					if previousModified, ok := previous[path]; !ok || !previousModified.Equal(modified) {
+						changed = append(changed, path)
+					}
+				}
+				for path := range previous {
+					if _, ok := current[path]; !ok {
+						changed = append(changed, path)
+					}
+				}
+				previous = current
+
+				for _, path := range changed {
+					select {
+					case events <- path:
+					case <-stop:
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return &Watcher{
+		Events: events,
+		close: func() error {
+			close(stop)
+			return nil
+		},
+	}, nil
+}
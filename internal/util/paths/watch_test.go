@@ -0,0 +1,77 @@
+package paths
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mickael-menu/zk/internal/util"
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+)
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zk-watch")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "existing"), 0755))
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, ".hidden"), 0755))
+
+	watcher, err := Watch(dir, &util.NullLogger)
+	assert.Nil(t, err)
+
+	write := func(path string) {
+		err := ioutil.WriteFile(filepath.Join(dir, path), []byte("content"), 0644)
+		assert.Nil(t, err)
+	}
+
+	// Waits for an event reporting one of the given paths.
+	expect := func(paths ...string) {
+		timeout := time.After(10 * time.Second)
+		for {
+			select {
+			case event := <-watcher.Events:
+				assert.NotEqual(t, event, ".hidden/a.md")
+				for _, path := range paths {
+					if event == path {
+						return
+					}
+				}
+			case <-timeout:
+				t.Fatalf("no event received for %v", paths)
+			}
+		}
+	}
+
+	write(".hidden/a.md")
+	write("a.md")
+	expect("a.md")
+
+	write("existing/b.md")
+	expect("existing/b.md")
+
+	// New directories are watched as well.
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "new"), 0755))
+	write("new/c.md")
+	expect("new", "new/c.md")
+	write("new/d.md")
+	expect("new/d.md")
+
+	// Moved directories are watched at their new location.
+	assert.Nil(t, os.Rename(filepath.Join(dir, "new"), filepath.Join(dir, "moved")))
+	expect("moved")
+	write("moved/e.md")
+	expect("moved/e.md")
+
+	assert.Nil(t, os.Remove(filepath.Join(dir, "a.md")))
+	expect("a.md")
+
+	assert.Nil(t, watcher.Close())
+	// Closing the watcher twice doesn't panic.
+	assert.Nil(t, watcher.Close())
+	// The events channel is closed eventually.
+	for range watcher.Events {
+	}
+}
@@ -1,6 +1,7 @@
 package rand
 
 import (
+	"encoding/binary"
 	"math/rand"
 	"time"
 	"unicode"
@@ -11,6 +12,10 @@ import (
 // NewIDGenerator returns a function generating string IDs using the given options.
 // Inspired by https://www.calhoun.io/creating-random-strings-in-go/
 func NewIDGenerator(options core.IDOptions) func() string {
+	if options.Kind == core.IDKindULID {
+		return newULIDGenerator()
+	}
+
 	if options.Length < 1 {
 		panic("IDOptions.Length must be at least 1")
 	}
@@ -41,3 +46,38 @@ func NewIDGenerator(options core.IDOptions) func() string {
 		return string(buf)
 	}
 }
+
+// crockfordBase32 is the alphabet used to encode ULIDs.
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newULIDGenerator returns a function generating ULIDs, made of a millisecond
+// timestamp followed by random bits to be sortable by creation time.
+// See https://github.com/ulid/spec
+func newULIDGenerator() func() string {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	return func() string {
+		entropy := make([]byte, 10)
+		rand.Read(entropy)
+		return encodeULID(time.Now(), entropy)
+	}
+}
+
+// encodeULID encodes the millisecond timestamp of the given time followed by
+// 80 bits of entropy as 26 characters of 5 bits.
+func encodeULID(t time.Time, entropy []byte) string {
+	data := make([]byte, 16)
+	ms := uint64(t.UnixNano() / int64(time.Millisecond))
+	binary.BigEndian.PutUint64(data[:8], ms<<16)
+	copy(data[6:], entropy)
+
+	hi := binary.BigEndian.Uint64(data[:8])
+	lo := binary.BigEndian.Uint64(data[8:])
+	buf := make([]byte, 26)
+	for i := len(buf) - 1; i >= 0; i-- {
+		buf[i] = crockfordBase32[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(buf)
+}
@@ -0,0 +1,43 @@
+package rand
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mickael-menu/zk/internal/core"
+	"github.com/mickael-menu/zk/internal/util/test/assert"
+)
+
+func TestEncodeULID(t *testing.T) {
+	test := func(ms int64, entropy []byte, expected string) {
+		date := time.Unix(0, ms*int64(time.Millisecond))
+		assert.Equal(t, encodeULID(date, entropy), expected)
+	}
+
+	zero := make([]byte, 10)
+	full := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+	test(0, zero, "00000000000000000000000000")
+	test(0, full, "0000000000ZZZZZZZZZZZZZZZZ")
+	// Timestamp of the example from the ULID spec.
+	test(1469918176385, zero, "01ARYZ6S410000000000000000")
+	test(1, []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 1}, "00000000010000000000000001")
+}
+
+func TestULIDGenerator(t *testing.T) {
+	generate := NewIDGenerator(core.IDOptions{Kind: core.IDKindULID})
+
+	ids := []string{}
+	for i := 0; i < 5; i++ {
+		id := generate()
+		assert.Equal(t, len(id), 26)
+		assert.Equal(t, strings.Trim(id, crockfordBase32), "")
+		ids = append(ids, id)
+		// IDs generated in the same millisecond are not ordered.
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	assert.True(t, sort.StringsAreSorted(ids))
+}
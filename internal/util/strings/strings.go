@@ -109,3 +109,39 @@ func ExpandWhitespaceLiterals(s string) string {
 	s = strings.ReplaceAll(s, `\t`, "\t")
 	return s
 }
+
+// EditDistance returns the Levenshtein distance between two strings, which is
+// the minimum number of single-character edits needed to change one into the
+// other.
+func EditDistance(a string, b string) int {
+	s, t := []rune(a), []rune(b)
+	prev := make([]int, len(t)+1)
+	curr := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		curr[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(t)]
+}
+
+func min(ints ...int) int {
+	res := ints[0]
+	for _, i := range ints[1:] {
+		if i < res {
+			res = i
+		}
+	}
+	return res
+}
@@ -115,3 +115,18 @@ func TestExpandWhitespaceLiterals(t *testing.T) {
 	test(`nothing`, "nothing")
 	test(`newline\ntab\t`, "newline\ntab\t")
 }
+
+func TestEditDistance(t *testing.T) {
+	test := func(a string, b string, expected int) {
+		assert.Equal(t, EditDistance(a, b), expected)
+		assert.Equal(t, EditDistance(b, a), expected)
+	}
+
+	test("", "", 0)
+	test("", "abc", 3)
+	test("abc", "abc", 0)
+	test("abc", "abd", 1)
+	test("kitten", "sitting", 3)
+	test("flaw", "lawn", 2)
+	test("été", "ete", 2)
+}
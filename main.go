@@ -21,10 +21,15 @@ var Build = "dev"
 var root struct {
 	Init  cmd.Init  `cmd group:"zk" help:"Create a new notebook in the given directory."`
 	Index cmd.Index `cmd group:"zk" help:"Index the notes to be searchable."`
-
-	New  cmd.New  `cmd group:"notes" help:"Create a new note in the given notebook directory."`
-	List cmd.List `cmd group:"notes" help:"List notes matching the given criteria."`
-	Edit cmd.Edit `cmd group:"notes" help:"Edit notes matching the given criteria."`
+	Check cmd.Check `cmd group:"zk" help:"Report issues found in the notebook, such as dead links."`
+
+	New     cmd.New     `cmd group:"notes" help:"Create a new note in the given notebook directory."`
+	Journal cmd.Journal `cmd group:"notes" help:"Open the daily, weekly or monthly journal note of a date, creating it if needed."`
+	List    cmd.List    `cmd group:"notes" help:"List notes matching the given criteria."`
+	Edit    cmd.Edit    `cmd group:"notes" help:"Edit notes matching the given criteria."`
+	Move    cmd.Move    `cmd group:"notes" name:"mv" help:"Move or rename a note, updating the links pointing to it."`
+	Graph   cmd.Graph   `cmd group:"notes" help:"Export the links between notes matching the given criteria as a graph."`
+	Tag     cmd.Tag     `cmd group:"notes" help:"List, rename, merge or delete the tags of the notebook."`
 
 	NotebookDir string  `type:path placeholder:PATH help:"Turn off notebook auto-discovery and set manually the notebook where commands are run."`
 	WorkingDir  string  `short:W type:path placeholder:PATH help:"Run as if zk was started in <PATH> instead of the current working directory."`